@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+
+	"github.com/scirelli/auction-ebidlocal-search/internal/app/migrate"
+	serverstorefs "github.com/scirelli/auction-ebidlocal-search/internal/app/server/store/fs"
+	serverstoresqlite "github.com/scirelli/auction-ebidlocal-search/internal/app/server/store/sqlite"
+	storefs "github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/store/fs"
+	storesqlite "github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/store/sqlite"
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/log"
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/sqldb"
+)
+
+const (
+	//StoreFS the directories of JSON files in the content path the apps use by default.
+	StoreFS = "fs"
+	//StoreSQLite the SQLite database, see sqldb.Config.
+	StoreSQLite = "sqlite"
+)
+
+//Copies users and watch lists from one store to another, see package migrate. Run it again until it has nothing left to copy, then switch the apps' store. Prints the report as JSON, exits with 1 if the target does not match the source.
+func main() {
+	var logger = log.New("Migrate.Main", log.DEFAULT_LOG_LEVEL)
+	var from *string = flag.String("from", StoreFS, fmt.Sprintf("store to copy from, '%s' or '%s'.", StoreFS, StoreSQLite))
+	var to *string = flag.String("to", StoreSQLite, fmt.Sprintf("store to copy to, '%s' or '%s'.", StoreFS, StoreSQLite))
+	var contentPath *string = flag.String("content-path", ".", "Base path of the user and watchlist data of the fs store.")
+	var dbPath *string = flag.String("db-path", "", "path of the SQLite database. Default 'ebidlocal.db' in the content path.")
+	var stateFile *string = flag.String("state-file", "", "file the migrated records are recorded in to resume from. Default 'migrate-<from>-<to>.json' in the content path.")
+	var dryRun *bool = flag.Bool("dry-run", false, "report what would be copied without copying anything.")
+	var verifyOnly *bool = flag.Bool("verify", false, "only compare the target with the source.")
+	var logLevel *string = flag.String("log-level", "", "log level.")
+	flag.Parse()
+
+	if *logLevel != "" {
+		logger.LogLevel = log.GetLevel(*logLevel)
+	}
+	if *from == *to {
+		logger.Fatalf("Nothing to migrate, from and to are both '%s'", *from)
+	}
+	if *stateFile == "" {
+		*stateFile = filepath.Join(*contentPath, fmt.Sprintf("migrate-%s-%s.json", *from, *to))
+	}
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	var dbConfig = sqldb.Config{Path: *dbPath}
+	sqldb.Defaults(&dbConfig, *contentPath)
+	var stores = make(map[string]migrate.Stores)
+	for _, name := range []string{*from, *to} {
+		switch name {
+		case StoreFS:
+			stores[name] = migrate.Stores{
+				Name:  name,
+				Users: serverstorefs.NewUserStore(filepath.Join(*contentPath, "web", "user"), "data.json", logger),
+				Watchlists: storefs.FSStore{
+					WatchlistStorer: storefs.NewWatchlistStore(storefs.WatchlistStoreConfig{
+						WatchlistDir: filepath.Join(*contentPath, "web", "watchlists"),
+					}, logger),
+					WatchlistContentStorer: storefs.NewWatchlistContentStore(storefs.WatchlistContentStoreConfig{
+						ContentPath: *contentPath,
+					}),
+				},
+			}
+		case StoreSQLite:
+			db, err := sqldb.Open(dbConfig)
+			if err != nil {
+				logger.Fatal(err)
+			}
+			defer db.Close()
+			//The history is copied as it is, the target's retention policy is applied by the apps.
+			watchlistStore := storesqlite.New(db, storesqlite.Config{}, logger)
+			userStore := serverstoresqlite.NewUserStore(db, logger)
+			//A dry run does not read the target, so it is left alone.
+			for _, migrateSchema := range []func(context.Context) (int, error){watchlistStore.Migrate, userStore.Migrate} {
+				if *dryRun && name == *to {
+					break
+				}
+				if _, err := migrateSchema(ctx); err != nil {
+					logger.Fatal(err)
+				}
+			}
+			stores[name] = migrate.Stores{Name: name, Users: userStore, Watchlists: watchlistStore}
+		default:
+			logger.Fatalf("Unknown store '%s', must be '%s' or '%s'", name, StoreFS, StoreSQLite)
+		}
+	}
+
+	migrator := migrate.New(migrate.Config{StateFile: *stateFile, LogLevel: logger.LogLevel}, stores[*from], stores[*to])
+	var report *migrate.Report
+	var err error
+	if *verifyOnly {
+		report, err = migrator.Verify(ctx)
+	} else {
+		report, err = migrator.Migrate(ctx, *dryRun)
+	}
+	if report != nil {
+		b, _ := json.MarshalIndent(report, "", "  ")
+		fmt.Println(string(b))
+	}
+	if err != nil {
+		logger.Fatal(err)
+	}
+	if !report.DryRun && !report.OK() {
+		os.Exit(1)
+	}
+}
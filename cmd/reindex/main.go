@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+
+	serverstore "github.com/scirelli/auction-ebidlocal-search/internal/app/server/store"
+	serverstorefs "github.com/scirelli/auction-ebidlocal-search/internal/app/server/store/fs"
+	serverstoresqlite "github.com/scirelli/auction-ebidlocal-search/internal/app/server/store/sqlite"
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/log"
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/sqldb"
+)
+
+const (
+	//StoreFS the directories of JSON files in the content path the apps use by default.
+	StoreFS = "fs"
+	//StoreSQLite the SQLite database, see sqldb.Config.
+	StoreSQLite = "sqlite"
+)
+
+//Rebuilds the user store's index of the users of each watch list, and of the owner of each share, from the users, see store.WatchlistUserIndexer and store.ShareIndexer. Run it if notifications go to the wrong users, or none, or share links stop working, after the users were changed outside of the apps. Exits non-zero, leaving the index as it was, if a user can not be loaded; fix or remove the user named and run it again.
+func main() {
+	var logger = log.New("Reindex.Main", log.DEFAULT_LOG_LEVEL)
+	var storeName *string = flag.String("store", StoreFS, fmt.Sprintf("store to reindex, '%s' or '%s'.", StoreFS, StoreSQLite))
+	var contentPath *string = flag.String("content-path", ".", "Base path of the user data of the fs store.")
+	var dbPath *string = flag.String("db-path", "", "path of the SQLite database. Default 'ebidlocal.db' in the content path.")
+	var logLevel *string = flag.String("log-level", "", "log level.")
+	flag.Parse()
+
+	if *logLevel != "" {
+		logger.LogLevel = log.GetLevel(*logLevel)
+	}
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	var users serverstore.WatchlistUserIndexer
+	switch *storeName {
+	case StoreFS:
+		users = serverstorefs.NewUserStore(filepath.Join(*contentPath, "web", "user"), "data.json", logger)
+	case StoreSQLite:
+		var dbConfig = sqldb.Config{Path: *dbPath}
+		db, err := sqldb.Open(*sqldb.Defaults(&dbConfig, *contentPath))
+		if err != nil {
+			logger.Fatal(err)
+		}
+		defer db.Close()
+		userStore := serverstoresqlite.NewUserStore(db, logger)
+		if _, err := userStore.Migrate(ctx); err != nil {
+			logger.Fatal(err)
+		}
+		users = userStore
+	default:
+		logger.Fatalf("Unknown store '%s', must be '%s' or '%s'", *storeName, StoreFS, StoreSQLite)
+	}
+
+	indexed, err := users.RebuildWatchlistIndex(ctx)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	fmt.Printf("Indexed the users of %d watch lists\n", indexed)
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/scirelli/auction-ebidlocal-search/internal/app/scanner"
 	"github.com/scirelli/auction-ebidlocal-search/internal/app/update"
 	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/log"
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/sqldb"
 )
 
 //LoadConfig a config file.
@@ -28,6 +29,10 @@ func LoadConfig(fileName string) (*AppConfig, error) {
 
 	json.Unmarshal(byteValue, &config)
 
+	if config.Store == "" {
+		config.Store = StoreFS
+	}
+
 	if config.LogLevel == "" {
 		config.Scanner.LogLevel = log.DEFAULT_LOG_LEVEL
 		config.Updater.LogLevel = log.DEFAULT_LOG_LEVEL
@@ -45,10 +50,20 @@ func LoadConfig(fileName string) (*AppConfig, error) {
 	return &config, nil
 }
 
+const (
+	//StoreFS keeps users and watch lists in directories of JSON files in the content path.
+	StoreFS = "fs"
+	//StoreSQLite keeps users and watch lists in a SQLite database, see sqldb.Config. The web files of the watch lists, such as email.html, stay on disk in the watch list dir.
+	StoreSQLite = "sqlite"
+)
+
 //AppConfig configuration data for entire application.
 type AppConfig struct {
 	Debug    bool   `json:"debug"`
 	LogLevel string `json:"logLevel"`
+	//Store where users and watch lists are kept, StoreFS or StoreSQLite. The scanner and the server must use the same store.
+	Store  string       `json:"store"`
+	SQLite sqldb.Config `json:"sqlite"`
 
 	Scanner  scanner.Config `json:"scanner"`
 	Updater  update.Config  `json:"updater"`
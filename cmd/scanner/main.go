@@ -2,17 +2,25 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/scirelli/auction-ebidlocal-search/internal/app/extract"
 	"github.com/scirelli/auction-ebidlocal-search/internal/app/notify"
 	"github.com/scirelli/auction-ebidlocal-search/internal/app/scanner"
+	serverstore "github.com/scirelli/auction-ebidlocal-search/internal/app/server/store"
+	serverstorefs "github.com/scirelli/auction-ebidlocal-search/internal/app/server/store/fs"
+	serverstoresqlite "github.com/scirelli/auction-ebidlocal-search/internal/app/server/store/sqlite"
 	"github.com/scirelli/auction-ebidlocal-search/internal/app/update"
 	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal"
+	ebidstore "github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/store"
 	storefs "github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/store/fs"
+	storesqlite "github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/store/sqlite"
 	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/log"
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/sqldb"
 )
 
 func main() {
@@ -29,6 +37,7 @@ func main() {
 	}
 	logger.Infof("Cwd '%s'\n", cwd)
 	contentPath = flag.String("content-path", "", fmt.Sprintf("Base path to save user and watchlist data. Default '%s'", "."))
+	var gcDryRun *bool = flag.Bool("gc-dry-run", false, "print the watch lists no user references, that would be garbage collected, and exit.")
 	flag.Parse()
 
 	logger.Infof("config path '%s'\n", *configPath)
@@ -45,25 +54,106 @@ func main() {
 		appConfig.Notifier.ContentPath = *contentPath
 	}
 
+	historyMaxSnapshots, historyMaxAge := appConfig.Updater.HistoryRetention()
+	archiveStore := storefs.NewItemArchiveStore(
+		storefs.ItemArchiveStoreConfig{
+			ArchiveDir: appConfig.Updater.ArchiveDir,
+		},
+		log.New("Updater.ItemArchiveStore", appConfig.Scanner.LogLevel),
+	)
+	if corrupt, err := archiveStore.Recover(ctx); err != nil {
+		logger.Fatal(err)
+	} else if len(corrupt) > 0 {
+		logger.Errorf("Corrupt archived items, they are replaced the next time the item is archived: %v", corrupt)
+	}
+
+	var store ebidstore.Storer
+	var notifierStore ebidstore.WatchlistContentStorer
+	var userStore serverstore.UserStorer
+	switch appConfig.Store {
+	case StoreSQLite:
+		db, err := sqldb.Open(*sqldb.Defaults(&appConfig.SQLite, appConfig.Updater.ContentPath))
+		if err != nil {
+			logger.Fatal(err)
+		}
+		defer db.Close()
+		sqliteStore := storesqlite.New(db, storesqlite.Config{
+			HistoryMaxSnapshots: historyMaxSnapshots,
+			HistoryMaxAge:       historyMaxAge,
+		}, log.New("Updater.SQLiteStore", appConfig.Scanner.LogLevel))
+		sqliteUserStore := serverstoresqlite.NewUserStore(db, log.New("Scanner.SQLiteUserStore", appConfig.Scanner.LogLevel))
+		for _, migrate := range []func(context.Context) (int, error){sqliteStore.Migrate, sqliteUserStore.Migrate} {
+			if applied, err := migrate(ctx); err != nil {
+				logger.Fatal(err)
+			} else if applied > 0 {
+				logger.Infof("Applied %d database migration(s)", applied)
+			}
+		}
+		store, notifierStore, userStore = sqliteStore, sqliteStore, sqliteUserStore
+	case StoreFS:
+		watchlistStore := storefs.NewWatchlistStore(
+			storefs.WatchlistStoreConfig{
+				WatchlistDir: appConfig.Updater.WatchlistDir,
+			},
+			log.New("Updater.FSStore", appConfig.Scanner.LogLevel),
+		)
+		contentStore := storefs.NewWatchlistContentStore(
+			storefs.WatchlistContentStoreConfig{
+				ContentPath:         appConfig.Updater.ContentPath,
+				HistoryMaxSnapshots: historyMaxSnapshots,
+				HistoryMaxAge:       historyMaxAge,
+			},
+		)
+		//Writes a crash left unfinished are cleaned up, and content that does not parse restored, before anything is read.
+		if corrupt, err := contentStore.Recover(ctx); err != nil {
+			logger.Fatal(err)
+		} else if len(corrupt) > 0 {
+			logger.Errorf("Corrupt watch list files, fix or remove them: %v", corrupt)
+		}
+		//Watch lists written by older versions are rewritten in the current format before they are scanned.
+		if migrated, err := watchlistStore.Migrate(ctx); err != nil {
+			logger.Fatal(err)
+		} else if len(migrated) > 0 {
+			logger.Infof("Migrated %d watch list(s)", len(migrated))
+		}
+		store = storefs.FSStore{
+			watchlistStore,
+			contentStore,
+		}
+		notifierStore = storefs.NewWatchlistContentStore(
+			storefs.WatchlistContentStoreConfig{
+				ContentPath: appConfig.Notifier.ContentPath,
+			},
+		)
+		userStore = serverstorefs.NewUserStore(appConfig.Scanner.UserDir, appConfig.Scanner.DataFileName, log.New("Scanner.UserStore", appConfig.Scanner.LogLevel))
+	default:
+		logger.Fatalf("Unknown store '%s', must be '%s' or '%s'", appConfig.Store, StoreFS, StoreSQLite)
+	}
+
+	//Watch lists no user references any more are collected so they are not searched forever.
+	gc := scanner.NewGarbageCollector(
+		appConfig.Scanner,
+		store,
+		userStore,
+	)
+	if *gcDryRun {
+		report, err := gc.Collect(ctx, true)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		b, _ := json.MarshalIndent(report, "", "  ")
+		fmt.Println(string(b))
+		cancel()
+		return
+	}
+
 	//scanner produces paths
-	scan := scanner.New(appConfig.Scanner)
+	scan := scanner.New(appConfig.Scanner, store)
 
 	//Updater subscribes to the paths and checks for changes
 	updater := update.New(
 		ctx,
-		storefs.FSStore{
-			storefs.NewWatchlistStore(
-				storefs.WatchlistStoreConfig{
-					WatchlistDir: appConfig.Updater.WatchlistDir,
-				},
-				log.New("Updater.FSStore", appConfig.Scanner.LogLevel),
-			),
-			storefs.NewWatchlistContentStore(
-				storefs.WatchlistContentStoreConfig{
-					ContentPath: appConfig.Updater.ContentPath,
-				},
-			),
-		},
+		store,
 		update.EbidlocalExtractor{
 			extract.NewAuctionItem(&extract.Config{
 				LogLevel: log.DEFAULT_LOG_LEVEL,
@@ -71,6 +161,7 @@ func main() {
 			ebidlocal.AuctionSearchFactory(appConfig.Scanner.SearchVersion, nil),
 		},
 		appConfig.Updater,
+		archiveStore,
 	)
 	pathsChan, _ := scan.SubscribeForPath()
 
@@ -78,17 +169,22 @@ func main() {
 	watchlistChangeEvent, _ := updater.SubscribeForChange()
 	email := notify.NewEmailNotify(
 		appConfig.Notifier,
-		storefs.NewWatchlistContentStore(
-			storefs.WatchlistContentStoreConfig{
-				ContentPath: appConfig.Notifier.ContentPath,
+		notifierStore,
+		storefs.NewItemArchiveStore(
+			storefs.ItemArchiveStoreConfig{
+				ArchiveDir: appConfig.Notifier.ArchiveDir,
 			},
+			log.New("Notifier.ItemArchiveStore", appConfig.Scanner.LogLevel),
 		),
 		notify.NewFilter(func(msg notify.NotificationMessage) bool {
-			return msg.User.Verified
-		}).Filter(ctx, notify.NewDedupeQueue().Enqueue(notify.NewWatchlistConvertData(appConfig.Notifier).Convert(watchlistChangeEvent))),
+			return msg.User.Verified && msg.User.WatchlistActive(msg.WatchlistID, time.Now())
+		}).Filter(ctx, notify.NewDedupeQueue().Enqueue(notify.NewWatchlistConvertData(appConfig.Notifier, userStore).Convert(watchlistChangeEvent))),
 	)
 
 	go scan.Scan(ctx)
+	if appConfig.Scanner.GCInterval > 0 {
+		go gc.Run(ctx)
+	}
 	go updater.Update(pathsChan)
 	email.Send()
 
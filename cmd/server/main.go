@@ -1,17 +1,21 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
 
 	"github.com/scirelli/auction-ebidlocal-search/internal/app/extract"
 	"github.com/scirelli/auction-ebidlocal-search/internal/app/server"
+	serverstore "github.com/scirelli/auction-ebidlocal-search/internal/app/server/store"
 	storefs "github.com/scirelli/auction-ebidlocal-search/internal/app/server/store/fs"
+	storesqlite "github.com/scirelli/auction-ebidlocal-search/internal/app/server/store/sqlite"
 	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal"
-	ebidstore "github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/store"
 	ebidfsstore "github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/store/fs"
+	ebidsqlitestore "github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/store/sqlite"
 	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/log"
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/sqldb"
 )
 
 func main() {
@@ -40,19 +44,53 @@ func main() {
 	if *contentPath != "" {
 		appConfig.Server.ContentPath = *contentPath
 	}
-	fsStore := ebidfsstore.FSStore{
-		ebidfsstore.NewWatchlistStore(ebidfsstore.WatchlistStoreConfig{
-			WatchlistDir: appConfig.Server.WatchlistDir,
-		}, logger),
-		&ebidstore.NopWatchlistContentStore{},
+	var store serverstore.Storer
+	switch appConfig.Store {
+	case StoreSQLite:
+		db, err := sqldb.Open(*sqldb.Defaults(&appConfig.SQLite, appConfig.Server.ContentPath))
+		if err != nil {
+			logger.Fatal(err)
+		}
+		defer db.Close()
+		userStore := storesqlite.NewUserStore(db, logger)
+		watchlistStore := ebidsqlitestore.New(db, ebidsqlitestore.Config{}, logger)
+		for _, migrate := range []func(context.Context) (int, error){watchlistStore.Migrate, userStore.Migrate} {
+			if applied, err := migrate(context.Background()); err != nil {
+				logger.Fatal(err)
+			} else if applied > 0 {
+				logger.Infof("Applied %d database migration(s)", applied)
+			}
+		}
+		store = storefs.FSStore{
+			userStore,
+			storefs.NewWatchlistStore(watchlistStore, logger),
+		}
+	case StoreFS:
+		userStore := storefs.NewUserStore(appConfig.Server.UserDir, appConfig.Server.DataFileName, logger)
+		//Writes a crash left unfinished are cleaned up before any user is read. The scanner recovers the watch lists.
+		if corrupt, err := userStore.Recover(context.Background()); err != nil {
+			logger.Fatal(err)
+		} else if len(corrupt) > 0 {
+			logger.Errorf("Corrupt user files, fix or remove them: %v", corrupt)
+		}
+		store = storefs.FSStore{
+			userStore,
+			storefs.NewWatchlistStore(ebidfsstore.FSStore{
+				ebidfsstore.NewWatchlistStore(ebidfsstore.WatchlistStoreConfig{
+					WatchlistDir: appConfig.Server.WatchlistDir,
+				}, logger),
+				ebidfsstore.NewWatchlistContentStore(ebidfsstore.WatchlistContentStoreConfig{
+					WatchlistDir: appConfig.Server.WatchlistDir,
+				}),
+			}, logger),
+		}
+	default:
+		logger.Fatalf("Unknown store '%s', must be '%s' or '%s'", appConfig.Store, StoreFS, StoreSQLite)
 	}
 
 	server.New(
 		appConfig.Server,
-		storefs.FSStore{
-			storefs.NewUserStore(appConfig.Server.UserDir, appConfig.Server.DataFileName, logger),
-			storefs.NewWatchlistStore(fsStore, logger),
-		},
+		store,
 		log.New("Server", appConfig.Server.LogLevel),
 		server.EbidlocalExtractor{
 			extract.NewAuctionItem(&extract.Config{
@@ -60,5 +98,8 @@ func main() {
 			}),
 			ebidlocal.AuctionSearchFactory(appConfig.Server.SearchVersion, nil),
 		},
+		ebidfsstore.NewItemArchiveStore(ebidfsstore.ItemArchiveStoreConfig{
+			ArchiveDir: appConfig.Server.ArchiveDir,
+		}, logger),
 	).Run()
 }
@@ -44,7 +44,7 @@ func NewAuctionItem(config *Config) *AuctionItem {
 					}
 
 					if v, exists := s.Find(selector).Attr("value"); exists {
-						if m.EndDate, err = time.ParseInLocation("2021-12-07 6:45:00 PM", v, loc); err != nil {
+						if m.EndDate, err = time.ParseInLocation("2006-01-02 3:04:05 PM", v, loc); err != nil {
 							logger.Infof("'%s' could not parse EndDate", v)
 						}
 					} else {
@@ -191,7 +191,8 @@ func (s *AuctionItem) extract(in <-chan model.SearchResult, out chan<- model.Auc
 		doc, err := goquery.NewDocumentFromReader(ioutil.NopCloser(strings.NewReader(result.Content)))
 		if err != nil {
 			s.logger.Errorf("AuctionItemExtractor could not parse html from read stream '%s'", err)
-			return
+			//Keep draining the input so the searcher writing to it is not left blocked.
+			continue
 		}
 
 		if os.Getenv("DEBUG") != "" {
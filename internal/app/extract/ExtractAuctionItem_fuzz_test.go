@@ -0,0 +1,48 @@
+package extract
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/model"
+	"github.com/scirelli/auction-ebidlocal-search/test/fixtures"
+)
+
+func FuzzAuctionItemExtract(f *testing.F) {
+	for _, test := range tests {
+		f.Add(test.Doc)
+	}
+	for _, seed := range fixtures.Corpus(f,
+		filepath.Join(goldenDir, "*", "*.html"),
+		"../../../test/fixtures/responses/doc_*",
+	) {
+		f.Add(seed)
+	}
+
+	extractor := NewAuctionItem(&Config{})
+	f.Fuzz(func(t *testing.T, doc string) {
+		defer fixtures.CheckGoroutineLeak(t, fixtures.FuzzTimeout)()
+		var in = make(chan model.SearchResult)
+
+		go func() {
+			defer close(in)
+			//Two results to make sure the extractor keeps reading after the first document.
+			for i := 0; i < 2; i++ {
+				in <- model.SearchResult{
+					AuctionID: "fuzz",
+					Keyword:   "fuzz",
+					Content:   doc,
+				}
+			}
+		}()
+
+		for _, item := range fixtures.ReceiveAll(t, extractor.Extract(in), fixtures.FuzzTimeout) {
+			if item.ParentAuctionID != "fuzz" {
+				t.Errorf("Expected parent auction id 'fuzz' got '%s'", item.ParentAuctionID)
+			}
+			if len(item.Keywords) != 1 || item.Keywords[0] != "fuzz" {
+				t.Errorf("Expected keywords '[fuzz]' got '%v'", item.Keywords)
+			}
+		}
+	})
+}
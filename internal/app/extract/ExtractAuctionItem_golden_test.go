@@ -0,0 +1,101 @@
+package extract
+
+import (
+	"encoding/json"
+	"flag"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/model"
+	v2 "github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/search/v2"
+	v3 "github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/search/v3"
+	ebidhttp "github.com/scirelli/auction-ebidlocal-search/internal/pkg/net/http"
+	"github.com/scirelli/auction-ebidlocal-search/test/fixtures"
+)
+
+//goldenDir captures are kept in a sub directory per search version, e.g. test/fixtures/golden/extract/v3/*.html
+const goldenDir = "../../../test/fixtures/golden/extract"
+
+var update = flag.Bool("update", false, "regenerate golden files instead of comparing against them.")
+
+type GoldenSearcher struct {
+	Client func(ebidhttp.HTTPClient) ebidhttp.HTTPClient
+	Search func(out chan<- model.SearchResult, auction string, keyword string) error
+}
+
+//GoldenAuctionItem AuctionItem as written to a golden file. URLs are written as strings so golden files don't depend on the url.URL struct layout of a Go release.
+type GoldenAuctionItem struct {
+	model.AuctionItem
+	ImageURLs []string `json:"imageUrls,omitempty"`
+	ItemURL   string   `json:"itemUrl,omitempty"`
+}
+
+func NewGoldenAuctionItem(item model.AuctionItem) GoldenAuctionItem {
+	var golden = GoldenAuctionItem{AuctionItem: item}
+
+	for _, u := range item.ImageURLs {
+		golden.ImageURLs = append(golden.ImageURLs, u.String())
+	}
+	if item.ItemURL != nil {
+		golden.ItemURL = item.ItemURL.String()
+	}
+
+	return golden
+}
+
+var goldenSearchers = map[string]GoldenSearcher{
+	"v2": {
+		Client: func(c ebidhttp.HTTPClient) (old ebidhttp.HTTPClient) {
+			old, v2.Client = v2.Client, c
+			return old
+		},
+		Search: v2.SearchAuction,
+	},
+	"v3": {
+		Client: func(c ebidhttp.HTTPClient) (old ebidhttp.HTTPClient) {
+			old, v3.Client = v3.Client, c
+			return old
+		},
+		Search: v3.SearchAuction,
+	},
+}
+
+//Test_AuctionItemGolden runs every HTML capture through the real search parsing and the AuctionItem extractor and compares the resulting []AuctionItem to the capture's golden file.
+//To add a regression case drop a capture into the version's directory and run `go test ./internal/app/extract/ -run Golden -update`
+func Test_AuctionItemGolden(t *testing.T) {
+	for version, searcher := range goldenSearchers {
+		for _, capture := range fixtures.Captures(t, filepath.Join(goldenDir, version), ".html") {
+			t.Run(version+"/"+filepath.Base(capture), func(t *testing.T) {
+				var results = make(chan model.SearchResult)
+				var items = make([]GoldenAuctionItem, 0)
+
+				defer searcher.Client(searcher.Client(&fixtures.MockClient{
+					DoFunc: func(req *http.Request) (*http.Response, error) {
+						return &http.Response{
+							Body:       fixtures.OpenFile(t, capture),
+							StatusCode: 200,
+							Request:    req,
+						}, nil
+					},
+				}))
+
+				go func() {
+					defer close(results)
+					if err := searcher.Search(results, "golden", "golden"); err != nil {
+						t.Error(err)
+					}
+				}()
+				for item := range NewAuctionItem(&Config{}).Extract(results) {
+					items = append(items, NewGoldenAuctionItem(item))
+				}
+
+				actual, err := json.MarshalIndent(items, "", "  ")
+				if err != nil {
+					t.Fatal(err)
+				}
+				fixtures.Golden(t, fixtures.GoldenFile(capture), append(actual, '\n'), *update)
+			})
+		}
+	}
+}
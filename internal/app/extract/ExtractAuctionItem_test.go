@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal"
 	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/model"
@@ -257,6 +258,20 @@ func Test_AuctionItem(t *testing.T) {
 	}
 }
 
+func Test_AuctionItemEndDate(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip(err)
+	}
+	in := make(chan model.SearchResult, 1)
+	in <- model.SearchResult{Content: fmt.Sprintf(simpleInput, "EndDate", "2021-12-07 6:45:00 PM")}
+	close(in)
+
+	for m := range NewAuctionItem(&Config{}).Extract(in) {
+		assert.True(t, m.EndDate.Equal(time.Date(2021, 12, 7, 18, 45, 0, 0, newYork)), "EndDate '%s'", m.EndDate)
+	}
+}
+
 func Skip_Test_Integration_AuctionItem(t *testing.T) {
 	extractor := NewAuctionItem(&Config{})
 	retrievedItems := false
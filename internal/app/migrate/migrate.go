@@ -0,0 +1,436 @@
+//Package migrate copies users and watch lists, with their content, scan state, changes, schedules and history, from one store to another, such as from the fs store to the sqlite store and back. Migrated records are recorded in a state file so an interrupted migration resumes where it stopped, and a migration run again while the apps are still writing to the source copies only what changed since, so the apps can be switched over once a run has nothing left to copy.
+package migrate
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	userstore "github.com/scirelli/auction-ebidlocal-search/internal/app/server/store"
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/store"
+	fileutils "github.com/scirelli/auction-ebidlocal-search/internal/pkg/fileUtils"
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/log"
+)
+
+//Stores a backend, the users of the server and the watch lists of the scanner. Schedules, changes and history are copied when both stores keep them.
+type Stores struct {
+	Name       string
+	Users      userstore.UserStorer
+	Watchlists store.Storer
+}
+
+//Config where the migration's state is kept.
+type Config struct {
+	//StateFile the migrated records and their checksums, a migration resumes from it. Empty starts over every time.
+	StateFile string       `json:"stateFile"`
+	LogLevel  log.LogLevel `json:"logLevel"`
+}
+
+//New constructor for a migration of from to to.
+func New(config Config, from Stores, to Stores) *Migrator {
+	return &Migrator{
+		config: config,
+		logger: log.New("Migrator", config.LogLevel),
+		from:   from,
+		to:     to,
+	}
+}
+
+//Migrator copies the records of one store to another.
+type Migrator struct {
+	config Config
+	logger log.Logger
+	from   Stores
+	to     Stores
+}
+
+//Report what a migration copied, or would copy on a dry run, and what a verification found.
+type Report struct {
+	DryRun     bool   `json:"dryRun"`
+	From       string `json:"from"`
+	To         string `json:"to"`
+	Users      Counts `json:"users"`
+	Watchlists Counts `json:"watchlists"`
+	//Failed records that could not be copied and why, keyed by kind and ID, see recordKey.
+	Failed map[string]string `json:"failed,omitempty"`
+	//Mismatched records whose copy does not match the source, or that are missing from one of the stores.
+	Mismatched map[string]string `json:"mismatched,omitempty"`
+}
+
+//Counts the records of a kind.
+type Counts struct {
+	//Source the records in the source store.
+	Source int `json:"source"`
+	//Target the records in the target store once the migration or verification finished.
+	Target int `json:"target"`
+	//Migrated the records copied, or that would be copied on a dry run.
+	Migrated []string `json:"migrated"`
+	//Unchanged the records the state file shows were copied and that have not changed since.
+	Unchanged int `json:"unchanged"`
+}
+
+//OK true if nothing failed or mismatched and both stores have the same number of records.
+func (r *Report) OK() bool {
+	return len(r.Failed) == 0 && len(r.Mismatched) == 0 && r.Users.Source == r.Users.Target && r.Watchlists.Source == r.Watchlists.Target
+}
+
+//state the checksums of the records already migrated.
+type state struct {
+	Migrated map[string]string `json:"migrated"`
+}
+
+//Migrate copies every user and watch list that is new or changed since the last run, then verifies the target. A dry run copies nothing and reports what would be copied. The state file is saved after each record so an interruption loses at most one record's work.
+func (m *Migrator) Migrate(ctx context.Context, dryRun bool) (*Report, error) {
+	var report = Report{DryRun: dryRun, From: m.from.Name, To: m.to.Name, Failed: make(map[string]string)}
+
+	done, err := m.loadState()
+	if err != nil {
+		return nil, err
+	}
+
+	userIDs, err := m.from.Users.ListUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	report.Users.Source = len(userIDs)
+	for _, id := range userIDs {
+		if err := m.migrateRecord(ctx, &report, &report.Users, done, recordKey("user", id), dryRun, func(from Stores) (string, error) {
+			return userChecksum(ctx, from, id)
+		}, func() error {
+			return m.copyUser(ctx, id)
+		}); err != nil {
+			return &report, err
+		}
+	}
+
+	watchlistIDs, err := m.from.Watchlists.ListWatchlists(ctx)
+	if err != nil {
+		return nil, err
+	}
+	report.Watchlists.Source = len(watchlistIDs)
+	for _, id := range watchlistIDs {
+		if err := m.migrateRecord(ctx, &report, &report.Watchlists, done, recordKey("watchlist", id), dryRun, func(from Stores) (string, error) {
+			return watchlistChecksum(ctx, from, id)
+		}, func() error {
+			return m.copyWatchlist(ctx, id)
+		}); err != nil {
+			return &report, err
+		}
+	}
+
+	if dryRun {
+		return &report, nil
+	}
+	return &report, m.verify(ctx, &report)
+}
+
+//Verify compares the checksum of every record of the source with its copy in the target, and the number of records in each.
+func (m *Migrator) Verify(ctx context.Context) (*Report, error) {
+	var report = Report{From: m.from.Name, To: m.to.Name}
+	return &report, m.verify(ctx, &report)
+}
+
+//migrateRecord copies the record unless the state shows it was copied and its checksum has not changed since.
+func (m *Migrator) migrateRecord(ctx context.Context, report *Report, counts *Counts, done *state, key string, dryRun bool, checksum func(Stores) (string, error), copyRecord func() error) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	sum, err := checksum(m.from)
+	if err != nil {
+		report.Failed[key] = err.Error()
+		return nil
+	}
+	if done.Migrated[key] == sum {
+		counts.Unchanged++
+		return nil
+	}
+	if dryRun {
+		counts.Migrated = append(counts.Migrated, key)
+		return nil
+	}
+
+	m.logger.Infof("Migrator.migrateRecord: Copying '%s' from '%s' to '%s'", key, m.from.Name, m.to.Name)
+	if err := copyRecord(); err != nil {
+		m.logger.Errorf("Migrator.migrateRecord: Failed to copy '%s'; %s", key, err)
+		report.Failed[key] = err.Error()
+		return nil
+	}
+	counts.Migrated = append(counts.Migrated, key)
+	done.Migrated[key] = sum
+	return m.saveState(done)
+}
+
+func (m *Migrator) copyUser(ctx context.Context, id string) error {
+	user, err := m.from.Users.LoadUser(ctx, id)
+	if err != nil {
+		return err
+	}
+	_, err = m.to.Users.SaveUser(ctx, user)
+	return err
+}
+
+//copyWatchlist copies the watch list's definition, schedules, history oldest first, content, changes and scan state. Snapshots the target already has are skipped, snapshots are never changed.
+func (m *Migrator) copyWatchlist(ctx context.Context, id string) error {
+	var from, to = m.from.Watchlists, m.to.Watchlists
+
+	definition, err := from.LoadWatchlist(ctx, id)
+	if err != nil {
+		return err
+	}
+	savedID, err := to.SaveWatchlist(ctx, definition)
+	if err != nil {
+		return err
+	}
+	if savedID != id {
+		return fmt.Errorf("the target saved the watch list as '%s', users reference it as '%s'", savedID, id)
+	}
+
+	if scheduler, ok := from.(store.WatchlistScheduler); ok {
+		if target, ok := to.(store.WatchlistScheduler); ok {
+			schedules, err := scheduler.LoadWatchlistSchedules(ctx, id)
+			if err != nil {
+				return err
+			}
+			if err = target.SaveWatchlistSchedules(ctx, id, schedules); err != nil {
+				return err
+			}
+		}
+	}
+
+	if history, ok := from.(store.WatchlistHistoryStorer); ok {
+		if target, ok := to.(store.WatchlistHistoryStorer); ok {
+			if err = copyHistory(ctx, history, target, to, id); err != nil {
+				return err
+			}
+		}
+	}
+
+	content, err := from.LoadWatchlistContent(ctx, id)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	//The latest snapshot is usually the content, saving it again would add it to the history twice.
+	if content != nil {
+		copied, err := to.LoadWatchlistContent(ctx, id)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if copied == nil || copied.ID() != content.ID() || !copied.Timestamp.Equal(content.Timestamp) {
+			if _, err = to.SaveWatchlistContent(ctx, content); err != nil {
+				return err
+			}
+		}
+	}
+
+	if changesets, ok := from.(store.WatchlistChangesetStorer); ok {
+		if target, ok := to.(store.WatchlistChangesetStorer); ok {
+			changeset, err := changesets.LoadWatchlistChangeset(ctx, id)
+			if err == nil {
+				err = target.SaveWatchlistChangeset(ctx, changeset)
+			}
+			if err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+	}
+
+	scanState, err := from.LoadWatchlistScanState(ctx, id)
+	if err != nil {
+		return err
+	}
+	return to.SaveWatchlistScanState(ctx, scanState)
+}
+
+//copyHistory saves the snapshots the target does not have, oldest first, as content so the target adds them to its history.
+func copyHistory(ctx context.Context, from store.WatchlistHistoryStorer, to store.WatchlistHistoryStorer, content store.WatchlistContentStorer, id string) error {
+	snapshots, err := from.ListWatchlistSnapshots(ctx, id)
+	if err != nil {
+		return err
+	}
+	existing, err := to.ListWatchlistSnapshots(ctx, id)
+	if err != nil {
+		return err
+	}
+	var copied = make(map[string]struct{}, len(existing))
+	for _, snapshot := range existing {
+		copied[snapshot.ID] = struct{}{}
+	}
+
+	for _, snapshot := range snapshots {
+		if _, exists := copied[snapshot.ID]; exists {
+			continue
+		}
+		snapshotContent, err := from.LoadWatchlistSnapshot(ctx, id, snapshot.ID)
+		if err != nil {
+			return err
+		}
+		if _, err = content.SaveWatchlistContent(ctx, snapshotContent); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//verify every record of the source must have a copy in the target with the same checksum.
+func (m *Migrator) verify(ctx context.Context, report *Report) error {
+	if report.Mismatched == nil {
+		report.Mismatched = make(map[string]string)
+	}
+
+	for _, kind := range []struct {
+		name     string
+		counts   *Counts
+		list     func(Stores) ([]string, error)
+		checksum func(Stores, string) (string, error)
+	}{
+		{"user", &report.Users, func(s Stores) ([]string, error) { return s.Users.ListUsers(ctx) }, func(s Stores, id string) (string, error) { return userChecksum(ctx, s, id) }},
+		{"watchlist", &report.Watchlists, func(s Stores) ([]string, error) { return s.Watchlists.ListWatchlists(ctx) }, func(s Stores, id string) (string, error) { return watchlistChecksum(ctx, s, id) }},
+	} {
+		sourceIDs, err := kind.list(m.from)
+		if err != nil {
+			return err
+		}
+		targetIDs, err := kind.list(m.to)
+		if err != nil {
+			return err
+		}
+		kind.counts.Source, kind.counts.Target = len(sourceIDs), len(targetIDs)
+
+		var inTarget = make(map[string]struct{}, len(targetIDs))
+		for _, id := range targetIDs {
+			inTarget[id] = struct{}{}
+		}
+		for _, id := range sourceIDs {
+			key := recordKey(kind.name, id)
+			if _, exists := inTarget[id]; !exists {
+				report.Mismatched[key] = "missing from the target"
+				continue
+			}
+			delete(inTarget, id)
+			sourceSum, err := kind.checksum(m.from, id)
+			if err != nil {
+				report.Mismatched[key] = err.Error()
+				continue
+			}
+			if targetSum, err := kind.checksum(m.to, id); err != nil {
+				report.Mismatched[key] = err.Error()
+			} else if sourceSum != targetSum {
+				report.Mismatched[key] = "checksums differ"
+			}
+		}
+		for id := range inTarget {
+			report.Mismatched[recordKey(kind.name, id)] = "missing from the source"
+		}
+	}
+	return nil
+}
+
+func userChecksum(ctx context.Context, stores Stores, id string) (string, error) {
+	user, err := stores.Users.LoadUser(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	return checksum(user)
+}
+
+//watchlistChecksum the checksum of everything copyWatchlist copies that both stores keep. Snapshots are summed by their ID and content.
+func watchlistChecksum(ctx context.Context, stores Stores, id string) (string, error) {
+	var watchlists = stores.Watchlists
+	var documents []interface{}
+
+	definition, err := watchlists.LoadWatchlist(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	//The version is of the document the definition was read from, the target writes the current version.
+	definition.Version = 0
+	content, err := watchlists.LoadWatchlistContent(ctx, id)
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	scanState, err := watchlists.LoadWatchlistScanState(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	documents = append(documents, definition, content, scanState)
+
+	if scheduler, ok := watchlists.(store.WatchlistScheduler); ok {
+		schedules, err := scheduler.LoadWatchlistSchedules(ctx, id)
+		if err != nil {
+			return "", err
+		}
+		documents = append(documents, schedules)
+	}
+	if changesets, ok := watchlists.(store.WatchlistChangesetStorer); ok {
+		changeset, err := changesets.LoadWatchlistChangeset(ctx, id)
+		if err != nil && !os.IsNotExist(err) {
+			return "", err
+		}
+		documents = append(documents, changeset)
+	}
+	if history, ok := watchlists.(store.WatchlistHistoryStorer); ok {
+		snapshots, err := history.ListWatchlistSnapshots(ctx, id)
+		if err != nil {
+			return "", err
+		}
+		for _, snapshot := range snapshots {
+			snapshotContent, err := history.LoadWatchlistSnapshot(ctx, id, snapshot.ID)
+			if err != nil {
+				return "", err
+			}
+			documents = append(documents, snapshot.ID, snapshotContent)
+		}
+	}
+	return checksum(documents)
+}
+
+//checksum the SHA-256 of the value's JSON, the same for a record loaded from any store.
+func checksum(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func recordKey(kind string, id string) string {
+	return kind + ":" + id
+}
+
+func (m *Migrator) loadState() (*state, error) {
+	var s = state{Migrated: make(map[string]string)}
+	if m.config.StateFile == "" {
+		return &s, nil
+	}
+	b, err := os.ReadFile(m.config.StateFile)
+	if os.IsNotExist(err) {
+		return &s, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(b, &s); err != nil {
+		return nil, fmt.Errorf("state file '%s'; %w", m.config.StateFile, err)
+	}
+	if s.Migrated == nil {
+		s.Migrated = make(map[string]string)
+	}
+	return &s, nil
+}
+
+func (m *Migrator) saveState(s *state) error {
+	if m.config.StateFile == "" {
+		return nil
+	}
+	b, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return fileutils.WriteFile(m.config.StateFile, b, 0644)
+}
@@ -0,0 +1,123 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/scirelli/auction-ebidlocal-search/internal/app/server/model"
+	serverstorefs "github.com/scirelli/auction-ebidlocal-search/internal/app/server/store/fs"
+	serverstoresqlite "github.com/scirelli/auction-ebidlocal-search/internal/app/server/store/sqlite"
+	ebidmodel "github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/model"
+	storefs "github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/store/fs"
+	storesqlite "github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/store/sqlite"
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/log"
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/sqldb"
+)
+
+func fsStores(contentPath string) Stores {
+	var logger = log.New("Test", log.DEFAULT_LOG_LEVEL)
+	return Stores{
+		Name:  "fs",
+		Users: serverstorefs.NewUserStore(filepath.Join(contentPath, "web", "user"), "data.json", logger),
+		Watchlists: storefs.FSStore{
+			WatchlistStorer:        storefs.NewWatchlistStore(storefs.WatchlistStoreConfig{WatchlistDir: filepath.Join(contentPath, "web", "watchlists")}, logger),
+			WatchlistContentStorer: storefs.NewWatchlistContentStore(storefs.WatchlistContentStoreConfig{ContentPath: contentPath}),
+		},
+	}
+}
+
+func sqliteStores(t *testing.T) Stores {
+	var ctx = context.Background()
+
+	db, err := sqldb.Open(*sqldb.Defaults(&sqldb.Config{}, t.TempDir()))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	t.Cleanup(func() { db.Close() })
+	watchlists := storesqlite.New(db, storesqlite.Config{}, nil)
+	users := serverstoresqlite.NewUserStore(db, nil)
+	_, err = watchlists.Migrate(ctx)
+	assert.NoError(t, err)
+	_, err = users.Migrate(ctx)
+	assert.NoError(t, err)
+	return Stores{Name: "sqlite", Users: users, Watchlists: watchlists}
+}
+
+func TestMigrate(t *testing.T) {
+	var ctx = context.Background()
+	var contentPath = t.TempDir()
+	var fs = fsStores(contentPath)
+	var sqlite = sqliteStores(t)
+	var now = time.Now().UTC()
+
+	id, err := fs.Watchlists.SaveWatchlist(ctx, ebidmodel.WatchlistDefinition{Keywords: []string{"drill"}})
+	assert.NoError(t, err)
+	for i := 3; i > 0; i-- {
+		_, err = fs.Watchlists.SaveWatchlistContent(ctx, &ebidmodel.WatchlistContent{
+			WatchlistID:  id,
+			Timestamp:    now.Add(-time.Duration(i) * time.Minute),
+			AuctionItems: []ebidmodel.AuctionItem{{ItemName: "drill", TotalBids: i}},
+		})
+		assert.NoError(t, err)
+	}
+	var scanState = ebidmodel.WatchlistScanState{WatchlistID: id}
+	scanState.Failed(now, errors.New("timeout"))
+	assert.NoError(t, fs.Watchlists.SaveWatchlistScanState(ctx, &scanState))
+	_, err = fs.Users.SaveUser(ctx, &model.User{ID: "u1", Name: "one", Watchlists: map[string]string{"tools": id}})
+	assert.NoError(t, err)
+
+	var stateFile = filepath.Join(t.TempDir(), "state.json")
+	migrator := New(Config{StateFile: stateFile}, fs, sqlite)
+
+	report, err := migrator.Migrate(ctx, true)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"user:u1"}, report.Users.Migrated)
+	assert.Equal(t, []string{"watchlist:" + id}, report.Watchlists.Migrated)
+	ids, _ := sqlite.Users.ListUsers(ctx)
+	assert.Empty(t, ids, "A dry run copies nothing")
+	assert.NoFileExists(t, stateFile)
+
+	report, err = migrator.Migrate(ctx, false)
+	assert.NoError(t, err)
+	assert.True(t, report.OK(), "%+v", report)
+	assert.Len(t, report.Watchlists.Migrated, 1)
+
+	snapshots, err := sqlite.Watchlists.(*storesqlite.Store).ListWatchlistSnapshots(ctx, id)
+	assert.NoError(t, err)
+	assert.Len(t, snapshots, 3, "The history is copied without adding the content to it again")
+	state, err := sqlite.Watchlists.LoadWatchlistScanState(ctx, id)
+	assert.NoError(t, err)
+	assert.Equal(t, "timeout", state.LastError)
+
+	//Resumed, only what changed is copied again.
+	_, err = fs.Users.SaveUser(ctx, &model.User{ID: "u2", Name: "two"})
+	assert.NoError(t, err)
+	report, err = New(Config{StateFile: stateFile}, fs, sqlite).Migrate(ctx, false)
+	assert.NoError(t, err)
+	assert.True(t, report.OK(), "%+v", report)
+	assert.Equal(t, []string{"user:u2"}, report.Users.Migrated)
+	assert.Equal(t, 1, report.Users.Unchanged)
+	assert.Empty(t, report.Watchlists.Migrated)
+
+	//And back.
+	var back = fsStores(t.TempDir())
+	report, err = New(Config{}, sqlite, back).Migrate(ctx, false)
+	assert.NoError(t, err)
+	assert.True(t, report.OK(), "%+v", report)
+	report, err = New(Config{}, fs, back).Verify(ctx)
+	assert.NoError(t, err)
+	assert.True(t, report.OK(), "%+v", report)
+
+	//A record changed in the target no longer matches.
+	_, err = sqlite.Users.SaveUser(ctx, &model.User{ID: "u2", Name: "changed"})
+	assert.NoError(t, err)
+	report, err = migrator.Verify(ctx)
+	assert.NoError(t, err)
+	assert.False(t, report.OK())
+	assert.Equal(t, map[string]string{"user:u2": "checksums differ"}, report.Mismatched)
+}
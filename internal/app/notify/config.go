@@ -60,6 +60,14 @@ func DefaultConfig(config *Config) *Config {
 		config.WatchlistDir = filepath.Join(config.ContentPath, "web", "watchlists")
 		logger.Infof("Defaulting watchlist dir to '%s'\n", config.WatchlistDir)
 	}
+	if config.ArchiveDir == "" {
+		config.ArchiveDir = filepath.Join(config.ContentPath, "archive", "items")
+		logger.Infof("Defaulting archive dir to '%s'\n", config.ArchiveDir)
+	}
+	if config.TypicalPricesRefresh == 0 {
+		config.TypicalPricesRefresh = 60 * 60
+		logger.Infof("Defaulting typical prices refresh to '%d' seconds\n", config.TypicalPricesRefresh)
+	}
 	if config.TemplateFile == "" {
 		config.TemplateFile = filepath.Join(config.ContentPath, "assets", "templates", "email.html.tmpl")
 		logger.Infof("Defaulting template dir to '%s'\n", config.TemplateFile)
@@ -75,8 +83,15 @@ type Config struct {
 	UserDir      string `json:"userDir"`
 	ServerUrl    string `json:"serverUrl"`
 	Type         string `json:"type"`
+	//WatchlistDir where the web files of each watch list, such as email.html, are written. They are kept on disk with either store.
 	WatchlistDir string `json:"watchlistDir"`
 	TemplateFile string `json:"templateFile"`
+	//TypicalPrices adds what items like each item usually go for to the email, from the item archive.
+	TypicalPrices bool `json:"typicalPrices"`
+	//TypicalPricesRefresh seconds the typical prices are kept before they are read from the item archive again.
+	TypicalPricesRefresh int64 `json:"typicalPricesRefreshSeconds"`
+	//ArchiveDir where the items whose auction closed are kept, see model.ArchivedItem.
+	ArchiveDir string `json:"archiveDir"`
 
 	Debug    bool         `json:"debug"`
 	LogLevel log.LogLevel `json:"logLevel"`
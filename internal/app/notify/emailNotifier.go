@@ -12,10 +12,13 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/analytics"
 	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/model"
 	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/store"
+	fileutils "github.com/scirelli/auction-ebidlocal-search/internal/pkg/fileUtils"
 	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/log"
 	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/notify/email"
 )
@@ -32,10 +35,18 @@ type EmailNotify struct {
 	MessageChan <-chan NotificationMessage
 	template    *template.Template
 	store       store.WatchlistContentStorer
+	archive     store.ItemArchiver
 	config      Config
+	now         func() time.Time
+
+	typicalMu sync.Mutex
+	//typical the typical prices of the item archive, built at typicalBuilt.
+	typical      *analytics.Index
+	typicalBuilt time.Time
 }
 
-func NewEmailNotify(config Config, store store.WatchlistContentStorer, messageChan <-chan NotificationMessage) *EmailNotify {
+//NewEmailNotify the archive is where typical prices are looked up when config.TypicalPrices is on, nil leaves them out.
+func NewEmailNotify(config Config, store store.WatchlistContentStorer, archive store.ItemArchiver, messageChan <-chan NotificationMessage) *EmailNotify {
 	var logger = log.New("EmailNotify", log.DEFAULT_LOG_LEVEL)
 
 	t, err := template.New("email.html.tmpl").Funcs(template.FuncMap{
@@ -59,6 +70,8 @@ func NewEmailNotify(config Config, store store.WatchlistContentStorer, messageCh
 		config:      config,
 		template:    t,
 		store:       store,
+		archive:     archive,
+		now:         time.Now,
 	}
 }
 
@@ -77,6 +90,8 @@ func (en *EmailNotify) Notify(message NotificationMessage) error {
 	var wllink, emailLink string
 	var err error
 	var content *model.WatchlistContent
+	var changes *model.WatchlistChangeset
+	var typicalPrices = en.typicalPrices()
 	for wlname, wl := range message.User.Watchlists {
 		for _, wlID := range strings.Split(wl, ",") {
 			var emailBody *bytes.Buffer = &bytes.Buffer{}
@@ -88,10 +103,13 @@ func (en *EmailNotify) Notify(message NotificationMessage) error {
 				en.Logger.Debugf("wlname: '%s'; wl: '%s'; wllink '%s'; error '%s'", wlname, wl, wllink, err)
 				content = &model.WatchlistContent{}
 			}
+			changes = en.changes(wlID)
 
 			if err := en.template.Execute(emailBody, struct {
 				ServerURL     string
-				Rows          map[string][]model.AuctionItem
+				Rows          []model.AuctionItemGroup
+				Changes       *model.WatchlistChangeset
+				TypicalPrices *analytics.Index
 				WatchlistLink string
 				WatchlistName string
 				EmailLink     string
@@ -99,7 +117,9 @@ func (en *EmailNotify) Notify(message NotificationMessage) error {
 				TimestampEpoc string
 			}{
 				ServerURL:     en.config.ServerUrl,
-				Rows:          model.AuctionItemGroupByKeyword(content.AuctionItems).Group(),
+				Rows:          model.AuctionItemGroupByKeyword(content.AuctionItems).Ranked(),
+				Changes:       changes,
+				TypicalPrices: typicalPrices,
 				WatchlistLink: wllink,
 				WatchlistName: wlname,
 				EmailLink:     emailLink,
@@ -118,12 +138,16 @@ func (en *EmailNotify) Notify(message NotificationMessage) error {
 					f.Close()
 				}
 
-				if err := os.WriteFile(filepath.Join(en.config.WatchlistDir, wlID, "email.html"), emailBody.Bytes(), 0644); err != nil {
+				if err := en.saveEmail(wlID, emailBody.Bytes()); err != nil {
 					en.Logger.Errorf("Failed to save email html '%s'", err)
 				}
+				subject := fmt.Sprintf("Your watch list has updates '%s'", wlname)
+				if changes != nil {
+					subject = fmt.Sprintf("%s; %s", subject, changes.Summary())
+				}
 				return email.NewEmail(
 					[]string{message.User.Email},
-					fmt.Sprintf("Your watch list has updates '%s'", wlname),
+					subject,
 					eb,
 				).Send()
 			}
@@ -131,3 +155,51 @@ func (en *EmailNotify) Notify(message NotificationMessage) error {
 	}
 	return errors.New("Failed to notify user watch list not found among user's watch lists.")
 }
+
+//typicalPrices what archived items like the watch list's items went for, nil if typical prices are off or there is no archive. The archive is read once every config.TypicalPricesRefresh seconds, not for every email; if it can not be read the typical prices read before are used.
+func (en *EmailNotify) typicalPrices() *analytics.Index {
+	if !en.config.TypicalPrices || en.archive == nil {
+		return nil
+	}
+	en.typicalMu.Lock()
+	defer en.typicalMu.Unlock()
+
+	if en.typical != nil && en.now().Sub(en.typicalBuilt) < time.Duration(en.config.TypicalPricesRefresh)*time.Second {
+		return en.typical
+	}
+	items, err := en.archive.QueryArchivedItems(context.Background(), model.ArchiveQuery{})
+	if err != nil {
+		en.Logger.Errorf("Failed to read the item archive, typical prices are left out or out of date; %s", err)
+		return en.typical
+	}
+	en.typical, en.typicalBuilt = analytics.NewIndex(items), en.now()
+	return en.typical
+}
+
+//saveEmail writes the email to email.html in the watch list's directory of config.WatchlistDir, where the server serves it from, while holding the lock on the directory like the fs store's writers. The web files stay on disk whatever the store, the directory is made if the store does not keep watch lists there, such as the SQLite store.
+func (en *EmailNotify) saveEmail(watchlistID string, body []byte) error {
+	var dir = filepath.Join(en.config.WatchlistDir, watchlistID)
+
+	if err := os.MkdirAll(dir, 0775); err != nil {
+		return err
+	}
+	unlock, err := fileutils.Lock(dir)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return fileutils.WriteFile(filepath.Join(dir, "email.html"), body, 0644)
+}
+
+//changes what changed the last time the watch list was updated, nil if the store does not keep changes or there are none.
+func (en *EmailNotify) changes(watchlistID string) *model.WatchlistChangeset {
+	changesets, ok := en.store.(store.WatchlistChangesetStorer)
+	if !ok {
+		return nil
+	}
+	changes, err := changesets.LoadWatchlistChangeset(context.Background(), watchlistID)
+	if err != nil || changes.IsEmpty() {
+		return nil
+	}
+	return changes
+}
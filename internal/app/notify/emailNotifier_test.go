@@ -0,0 +1,100 @@
+package notify
+
+import (
+	"context"
+	"net/smtp"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	servermodel "github.com/scirelli/auction-ebidlocal-search/internal/app/server/model"
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/model"
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/store"
+	storesqlite "github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/store/sqlite"
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/log"
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/notify/email"
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/sqldb"
+)
+
+//countingArchive an archive that counts how often it is queried.
+type countingArchive struct {
+	store.ItemArchiver
+	items   []model.ArchivedItem
+	queried int
+}
+
+func (a *countingArchive) QueryArchivedItems(ctx context.Context, query model.ArchiveQuery) ([]model.ArchivedItem, error) {
+	a.queried++
+	return a.items, nil
+}
+
+func TestEmailNotifyTypicalPricesRefresh(t *testing.T) {
+	var now = time.Now()
+	var archive = &countingArchive{}
+	var en = &EmailNotify{
+		Logger:  log.New("Test", log.DEFAULT_LOG_LEVEL),
+		config:  *DefaultConfig(&Config{ContentPath: t.TempDir(), TypicalPrices: true, TypicalPricesRefresh: 60}),
+		archive: archive,
+		now:     func() time.Time { return now },
+	}
+
+	first := en.typicalPrices()
+	assert.NotNil(t, first)
+	assert.Same(t, first, en.typicalPrices())
+	assert.Equal(t, 1, archive.queried, "The archive is read once for many emails")
+
+	now = now.Add(61 * time.Second)
+	assert.NotSame(t, first, en.typicalPrices())
+	assert.Equal(t, 2, archive.queried, "The archive is read again once the typical prices are out of date")
+
+	en.config.TypicalPrices = false
+	assert.Nil(t, en.typicalPrices())
+}
+
+func TestEmailNotifySQLiteStore(t *testing.T) {
+	var ctx = context.Background()
+	var contentPath = t.TempDir()
+	var itemURL, _ = url.Parse("https://auction.ebidlocal.com/cgi-bin/mmlist.cgi?auction/1")
+
+	db, err := sqldb.Open(*sqldb.Defaults(&sqldb.Config{}, contentPath))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	t.Cleanup(func() { db.Close() })
+	sqliteStore := storesqlite.New(db, storesqlite.Config{}, nil)
+	_, err = sqliteStore.Migrate(ctx)
+	assert.NoError(t, err)
+
+	listID, err := sqliteStore.SaveWatchlist(ctx, model.WatchlistDefinition{Keywords: model.Watchlist{"lamp"}})
+	assert.NoError(t, err)
+	_, err = sqliteStore.SaveWatchlistContent(ctx, &model.WatchlistContent{
+		WatchlistID:  listID,
+		AuctionItems: []model.AuctionItem{{Id: "1", ParentAuctionID: "auction", ItemURL: itemURL, ImageURLs: []*url.URL{itemURL}, ItemName: "Brass lamp", Keywords: []string{"lamp"}}},
+	})
+	assert.NoError(t, err)
+
+	var sent []byte
+	defer func(sendMail email.MailerFunc) { email.SendMail = sendMail }(email.SendMail)
+	email.SendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		sent = msg
+		return nil
+	}
+
+	config := DefaultConfig(&Config{ContentPath: contentPath, TemplateFile: filepath.Join("..", "..", "..", "assets", "templates", "email.html.tmpl")})
+	en := NewEmailNotify(*config, sqliteStore, nil, nil)
+	err = en.Notify(NotificationMessage{
+		User:        &servermodel.User{ID: "user", Email: "user@example.com", Watchlists: map[string]string{"lamps": listID}},
+		WatchlistID: listID,
+	})
+	assert.NoError(t, err)
+	assert.Contains(t, string(sent), "Brass lamp")
+
+	b, err := os.ReadFile(filepath.Join(config.WatchlistDir, listID, "email.html"))
+	assert.NoError(t, err, "The SQLite store keeps no watch list directory, the email is written to disk all the same")
+	assert.Contains(t, string(b), "Brass lamp")
+	assert.Contains(t, string(b), `<td colspan="6"><hr/></td>`, "The divider spans the columns without the typical price")
+}
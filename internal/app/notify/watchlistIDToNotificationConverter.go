@@ -4,6 +4,7 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -17,21 +18,32 @@ type WatchlistIDToNotificationMessageConverter interface {
 	Convert(<-chan string) <-chan NotificationMessage
 }
 
-func NewWatchlistConvertData(config Config) *WatchlistConvertData {
+//NewWatchlistConvertData the users are read from users, the fs user store in the config's user directory if it is nil.
+func NewWatchlistConvertData(config Config, users store.UserStorer) *WatchlistConvertData {
 	DefaultConfig(&config)
+	var logger = log.New("WatchlistConvertData", log.DEFAULT_LOG_LEVEL)
 
+	if users == nil {
+		users = fs.NewUserStore(config.UserDir, config.DataFileName, logger)
+	}
 	return &WatchlistConvertData{
-		logger: log.New("WatchlistConvertData", log.DEFAULT_LOG_LEVEL),
+		logger: logger,
 		config: config,
+		users:  users,
 	}
 }
 
 type WatchlistConvertData struct {
 	logger log.Logger
 	config Config
+	users  store.UserStorer
 }
 
+//Convert a message for each user of each watch list. The users are looked up in the user store's watch list index if it has one, otherwise from a cache of every user rebuilt every 350 seconds.
 func (e *WatchlistConvertData) Convert(watchlistIDChan <-chan string) <-chan NotificationMessage {
+	if index, ok := e.users.(store.WatchlistUserIndexer); ok {
+		return e.convertIndexed(index, watchlistIDChan)
+	}
 	var messageChan = make(chan NotificationMessage)
 
 	go func() {
@@ -61,6 +73,51 @@ func (e *WatchlistConvertData) Convert(watchlistIDChan <-chan string) <-chan Not
 	return messageChan
 }
 
+//convertIndexed the users are loaded when their watch list changes, so a user saved a moment ago is notified too.
+func (e *WatchlistConvertData) convertIndexed(index store.WatchlistUserIndexer, watchlistIDChan <-chan string) <-chan NotificationMessage {
+	var messageChan = make(chan NotificationMessage)
+
+	go func() {
+		for wlid := range watchlistIDChan {
+			for _, user := range e.watchlistUsers(index, wlid) {
+				e.logger.Infof("Sending notification message: %s, about watch list '%s'", user, wlid)
+				messageChan <- NotificationMessage{
+					User:        user,
+					WatchlistID: wlid,
+				}
+			}
+		}
+		close(messageChan)
+	}()
+
+	return messageChan
+}
+
+//watchlistUsers the users the index has for the watch list. A user that no longer references it, from an index in need of a rebuild, is skipped.
+func (e *WatchlistConvertData) watchlistUsers(index store.WatchlistUserIndexer, watchlistID string) []*model.User {
+	var users []*model.User
+
+	userIDs, err := index.WatchlistUsers(context.Background(), watchlistID)
+	if err != nil {
+		e.logger.Errorf("Error finding the users of watch list '%s': %v\n", watchlistID, err)
+		return users
+	}
+	for _, userID := range userIDs {
+		user, err := e.users.LoadUser(context.Background(), userID)
+		if err != nil {
+			e.logger.Warnf("Skipping user '%s'", userID)
+			continue
+		}
+		listIDs := store.UserWatchlistIDs(user)
+		if i := sort.SearchStrings(listIDs, watchlistID); i == len(listIDs) || listIDs[i] != watchlistID {
+			e.logger.Warnf("User '%s' is indexed under watch list '%s' they no longer have, the index needs rebuilding", userID, watchlistID)
+			continue
+		}
+		users = append(users, user)
+	}
+	return users
+}
+
 func (e *WatchlistConvertData) findAllUsersDataFiles() []string {
 	e.logger.Infof("Searching for users")
 	var userPaths []string
@@ -102,11 +159,16 @@ func (e *WatchlistConvertData) allUsers() (userIds []string) {
 }
 
 func (e *WatchlistConvertData) createUserCache() map[string][]*model.User {
-	var userStore store.UserStorer = fs.NewUserStore(e.config.UserDir, e.config.DataFileName, e.logger)
 	var watchlistToUsers = make(map[string][]*model.User)
 
-	for _, userID := range e.allUsers() {
-		user, err := userStore.LoadUser(context.Background(), userID)
+	e.logger.Infof("Searching for users")
+	userIDs, err := e.users.ListUsers(context.Background())
+	if err != nil {
+		e.logger.Errorf("Error listing the users: %v\n", err)
+		return watchlistToUsers
+	}
+	for _, userID := range userIDs {
+		user, err := e.users.LoadUser(context.Background(), userID)
 		if err != nil {
 			e.logger.Warnf("Skipping user '%s'", userID)
 			continue
@@ -1,7 +1,9 @@
 package notify
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -10,6 +12,7 @@ import (
 	"testing"
 
 	"github.com/scirelli/auction-ebidlocal-search/internal/app/server/model"
+	"github.com/scirelli/auction-ebidlocal-search/internal/app/server/store/memory"
 	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/log"
 	"github.com/stretchr/testify/assert"
 )
@@ -130,14 +133,13 @@ func Test_allUsers(t *testing.T) {
 func TestConvert(t *testing.T) {
 	for _, test := range dataFixture() {
 		t.Run(test.Name, func(t *testing.T) {
-			var e WatchlistConvertData = WatchlistConvertData{
-				logger: log.New("email_test", log.DEFAULT_LOG_LEVEL),
-				config: config,
-			}
+			var users = memory.NewUserStore()
+			var e = NewWatchlistConvertData(config, users)
 
-			for _, user := range test.Users {
-				_, tearDown := setupfixture(t, test.Config, user)
-				defer tearDown()
+			for i, user := range test.Users {
+				user.ID = fmt.Sprintf("user%d", i)
+				_, err := users.SaveUser(context.Background(), &user)
+				assert.NoError(t, err)
 			}
 
 			var watchlistIDchan = make(chan string)
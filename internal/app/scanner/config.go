@@ -48,6 +48,18 @@ func Defaults(config *Config) *Config {
 		config.ScanInterval = 10
 		logger.Infof("Defaulting scan interval to '%d'\n", config.ScanInterval)
 	}
+	if config.UserDir == "" {
+		config.UserDir = filepath.Join(config.ContentPath, "web", "user")
+		logger.Infof("Defaulting user dir to '%s'\n", config.UserDir)
+	}
+	if config.GCInterval == 0 {
+		config.GCInterval = 60 * 60
+		logger.Infof("Defaulting garbage collection interval to '%d'\n", config.GCInterval)
+	}
+	if config.GCGracePeriod == 0 {
+		config.GCGracePeriod = 60 * 60
+		logger.Infof("Defaulting garbage collection grace period to '%d'\n", config.GCGracePeriod)
+	}
 	if config.SearchVersion == "" {
 		config.SearchVersion = "v1"
 		logger.Infof("Defaulting SearchVersion to '%s'\n", config.SearchVersion)
@@ -65,6 +77,17 @@ type Config struct {
 	ScanInterval int64  `json:"scanIntervalSeconds"`
     SearchVersion string `json:"searchVersion"`
 
+	//UserDir where users are read from to find the watch lists they reference.
+	UserDir string `json:"userDir"`
+	//GCInterval seconds between collecting the watch lists no user references, negative turns collecting off.
+	GCInterval int64 `json:"gcIntervalSeconds"`
+	//GCGracePeriod seconds a watch list is kept after it is created even if no user references it.
+	GCGracePeriod int64 `json:"gcGracePeriodSeconds"`
+	//GCArchiveDir when set unreferenced watch lists are moved here instead of being deleted. It must not be inside WatchlistDir or they would still be scanned.
+	GCArchiveDir string `json:"gcArchiveDir"`
+	//GCDryRun only report what would be collected.
+	GCDryRun bool `json:"gcDryRun"`
+
 	Debug    bool         `json:"debug"`
 	LogLevel log.LogLevel `json:"logLevel"`
 }
@@ -0,0 +1,128 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	userstore "github.com/scirelli/auction-ebidlocal-search/internal/app/server/store"
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/store"
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/log"
+)
+
+//NewGarbageCollector constructor for the watch list garbage collector. Watch lists are archived to config.GCArchiveDir when it is set, otherwise they are deleted.
+func NewGarbageCollector(config Config, watchlists store.WatchlistStorer, users userstore.UserStorer) *GarbageCollector {
+	return &GarbageCollector{
+		config:     config,
+		logger:     log.New("Scanner.GarbageCollector", config.LogLevel),
+		watchlists: watchlists,
+		users:      users,
+		now:        time.Now,
+	}
+}
+
+//GarbageCollector removes the watch lists no user references any more, so the scanner stops searching for them.
+type GarbageCollector struct {
+	config     Config
+	logger     log.Logger
+	watchlists store.WatchlistStorer
+	users      userstore.UserStorer
+	now        func() time.Time
+}
+
+//GCReport what a collection removed, or would remove on a dry run.
+type GCReport struct {
+	DryRun bool `json:"dryRun"`
+	//Referenced the number of watch lists users reference.
+	Referenced int `json:"referenced"`
+	//Removed the watch lists that were deleted or archived.
+	Removed []string `json:"removed"`
+	//Young watch lists no user references that are kept because they were created within the grace period, a user may be being saved.
+	Young []string `json:"young"`
+	//Unreadable watch lists no user references that are kept because their definition can not be loaded, and why.
+	Unreadable map[string]string `json:"unreadable,omitempty"`
+	//Failed watch lists that could not be removed and why.
+	Failed map[string]string `json:"failed,omitempty"`
+}
+
+//Run collects on the config's GC interval until the context is done.
+func (gc *GarbageCollector) Run(ctx context.Context) {
+	interval := time.Duration(gc.config.GCInterval) * time.Second
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	gc.logger.Infof("Collecting unreferenced watch lists every '%s'", interval)
+	for {
+		select {
+		case <-ctx.Done():
+			gc.logger.Info("Garbage collector stopped.")
+			return
+		case <-ticker.C:
+			report, err := gc.Collect(ctx, gc.config.GCDryRun)
+			if err != nil {
+				gc.logger.Error(err)
+				continue
+			}
+			gc.logger.Infof("Collected watch lists: %+v", report)
+		}
+	}
+}
+
+//Collect removes the watch lists no user references. A dry run only reports what would be removed. Nothing is removed if a user can not be loaded, the watch lists they reference would look unreferenced.
+func (gc *GarbageCollector) Collect(ctx context.Context, dryRun bool) (GCReport, error) {
+	var report = GCReport{DryRun: dryRun, Removed: []string{}, Young: []string{}}
+
+	//Watch lists are listed before users are read, so a list created while collecting is never seen without its user.
+	ids, err := gc.watchlists.ListWatchlists(ctx)
+	if err != nil {
+		return report, err
+	}
+	references, err := userstore.WatchlistReferences(ctx, gc.users)
+	if err != nil {
+		return report, err
+	}
+	report.Referenced = len(references)
+
+	grace := time.Duration(gc.config.GCGracePeriod) * time.Second
+	for _, id := range ids {
+		if _, referenced := references[id]; referenced {
+			continue
+		}
+		list, err := gc.watchlists.LoadWatchlist(ctx, id)
+		if err != nil {
+			gc.logger.Errorf("Keeping unreadable watch list '%s'; %s", id, err)
+			if report.Unreadable == nil {
+				report.Unreadable = make(map[string]string)
+			}
+			report.Unreadable[id] = err.Error()
+			continue
+		}
+		if gc.now().Sub(list.Metadata.Created) < grace {
+			report.Young = append(report.Young, id)
+			continue
+		}
+		if !dryRun {
+			if err := gc.remove(ctx, id); err != nil {
+				if report.Failed == nil {
+					report.Failed = make(map[string]string)
+				}
+				report.Failed[id] = err.Error()
+				continue
+			}
+		}
+		report.Removed = append(report.Removed, id)
+	}
+
+	return report, nil
+}
+
+func (gc *GarbageCollector) remove(ctx context.Context, id string) error {
+	if gc.config.GCArchiveDir == "" {
+		return gc.watchlists.DeleteWatchlist(ctx, id)
+	}
+	archiver, ok := gc.watchlists.(store.WatchlistArchiver)
+	if !ok {
+		return fmt.Errorf("the watch list store can not archive")
+	}
+	return archiver.ArchiveWatchlist(ctx, id, gc.config.GCArchiveDir)
+}
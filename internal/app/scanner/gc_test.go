@@ -0,0 +1,90 @@
+package scanner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/scirelli/auction-ebidlocal-search/internal/app/server/model"
+	serverfs "github.com/scirelli/auction-ebidlocal-search/internal/app/server/store/fs"
+	ebidmodel "github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/model"
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/store/fs"
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/log"
+)
+
+func TestGarbageCollectorCollect(t *testing.T) {
+	var ctx = context.Background()
+	var dir = t.TempDir()
+	var now = time.Now()
+	var config = Defaults(&Config{ContentPath: dir, GCArchiveDir: filepath.Join(dir, "archive")})
+
+	var logger = log.New("Test", log.DEFAULT_LOG_LEVEL)
+	var watchlists = fs.NewWatchlistStore(fs.WatchlistStoreConfig{WatchlistDir: config.WatchlistDir}, logger)
+	var users = serverfs.NewUserStore(config.UserDir, config.DataFileName, logger)
+
+	referenced, _ := watchlists.SaveWatchlist(ctx, ebidmodel.WatchlistDefinition{Keywords: ebidmodel.Watchlist{"drill"}, Metadata: ebidmodel.WatchlistMetadata{Created: now.Add(-48 * time.Hour)}})
+	orphan, _ := watchlists.SaveWatchlist(ctx, ebidmodel.WatchlistDefinition{Keywords: ebidmodel.Watchlist{"kayak"}, Metadata: ebidmodel.WatchlistMetadata{Created: now.Add(-48 * time.Hour)}})
+	young, _ := watchlists.SaveWatchlist(ctx, ebidmodel.WatchlistDefinition{Keywords: ebidmodel.Watchlist{"canoe"}, Metadata: ebidmodel.WatchlistMetadata{Created: now}})
+	_, err := users.SaveUser(ctx, &model.User{ID: "1", Watchlists: map[string]string{"Tools": referenced}})
+	assert.NoError(t, err)
+
+	gc := NewGarbageCollector(*config, watchlists, users)
+
+	report, err := gc.Collect(ctx, true)
+	assert.NoError(t, err)
+	assert.Equal(t, GCReport{DryRun: true, Referenced: 1, Removed: []string{orphan}, Young: []string{young}}, report)
+	ids, _ := watchlists.ListWatchlists(ctx)
+	assert.Len(t, ids, 3, "A dry run removes nothing")
+
+	report, err = gc.Collect(ctx, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{orphan}, report.Removed)
+	ids, _ = watchlists.ListWatchlists(ctx)
+	assert.ElementsMatch(t, []string{referenced, young}, ids)
+	_, err = os.Stat(filepath.Join(config.GCArchiveDir, orphan, "data.json"))
+	assert.NoError(t, err, "Collected watch lists are archived")
+}
+
+func TestGarbageCollectorCollectUnreadable(t *testing.T) {
+	var ctx = context.Background()
+	var dir = t.TempDir()
+	var config = Defaults(&Config{ContentPath: dir})
+
+	var logger = log.New("Test", log.DEFAULT_LOG_LEVEL)
+	var watchlists = fs.NewWatchlistStore(fs.WatchlistStoreConfig{WatchlistDir: config.WatchlistDir}, logger)
+	var users = serverfs.NewUserStore(config.UserDir, config.DataFileName, logger)
+	var old = time.Now().Add(-48 * time.Hour)
+
+	referenced, _ := watchlists.SaveWatchlist(ctx, ebidmodel.WatchlistDefinition{Keywords: ebidmodel.Watchlist{"drill"}, Metadata: ebidmodel.WatchlistMetadata{Created: old}})
+	orphan, _ := watchlists.SaveWatchlist(ctx, ebidmodel.WatchlistDefinition{Keywords: ebidmodel.Watchlist{"kayak"}, Metadata: ebidmodel.WatchlistMetadata{Created: old}})
+	_, err := users.SaveUser(ctx, &model.User{ID: "1", Watchlists: map[string]string{"Tools": referenced}})
+	assert.NoError(t, err)
+	gc := NewGarbageCollector(*config, watchlists, users)
+
+	t.Run("A user that can not be loaded stops the collection", func(t *testing.T) {
+		userFile := filepath.Join(config.UserDir, "1", config.DataFileName)
+		b, _ := os.ReadFile(userFile)
+		assert.NoError(t, os.WriteFile(userFile, []byte(`{"id": "1", "watchlists": {"Too`), 0644))
+		defer os.WriteFile(userFile, b, 0644)
+
+		_, err := gc.Collect(ctx, false)
+		assert.Error(t, err)
+		ids, _ := watchlists.ListWatchlists(ctx)
+		assert.ElementsMatch(t, []string{referenced, orphan}, ids, "Nothing is removed")
+	})
+
+	t.Run("A watch list whose definition can not be loaded is kept", func(t *testing.T) {
+		assert.NoError(t, os.WriteFile(filepath.Join(config.WatchlistDir, orphan, "data.json"), []byte(`{"version": `), 0644))
+
+		report, err := gc.Collect(ctx, false)
+		assert.NoError(t, err)
+		assert.Empty(t, report.Removed)
+		assert.Contains(t, report.Unreadable, orphan)
+		ids, _ := watchlists.ListWatchlists(ctx)
+		assert.ElementsMatch(t, []string{referenced, orphan}, ids)
+	})
+}
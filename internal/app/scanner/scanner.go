@@ -2,22 +2,23 @@ package scanner
 
 import (
 	"context"
-	"io/fs"
 	"path/filepath"
 	"time"
 
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/store"
 	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/log"
 	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/publish"
 )
 
-//New constructor for scanner app. The scanner publishes watch lists it finds.
-func New(config Config) *Scanner {
+//New constructor for scanner app. The scanner publishes the watch lists in the store, except those that are paused, snoozed or expired for every user. If the store can not schedule every watch list is published.
+func New(config Config, watchlists store.WatchlistStorer) *Scanner {
 	changePublsr := publish.NewStringChange()
 	changePublsr.PublishTTL = 10 * 60 * time.Second // The Scanner sets a long publish time because down stream handlers (watch list updater) an take a long time to process a list. Since the app was changed to process one list at a time (due to memory limitations) the publisher should give enough time for ebidlocal requests to finish.
 	return &Scanner{
 		config:       config,
 		logger:       log.New("Scanner.New", log.DEFAULT_LOG_LEVEL),
 		changePublsr: changePublsr,
+		watchlists:   watchlists,
 	}
 }
 
@@ -26,25 +27,23 @@ type Scanner struct {
 	config       Config
 	logger       log.Logger
 	changePublsr publish.StringPublisher
+	watchlists   store.WatchlistStorer
 }
 
 func (s *Scanner) SubscribeForPath() (readChan <-chan string, unsubscribe func() error) {
 	return s.changePublsr.Subscribe()
 }
 
-// Scan directory for watch lists and publishes the path. Use SubscribeForPath to be notified of found watch lists.
-// Walk the watch list directory on an internval.
+// Scan lists the watch lists in the store and publishes the path of each one's data file in the watch list directory, the path the updater takes the watch list's ID from. Use SubscribeForPath to be notified of found watch lists.
+// List the watch lists on an internval.
 func (s *Scanner) Scan(ctx context.Context) error {
 	timeBetweenRuns := time.Duration(s.config.ScanInterval) * time.Second
-	watchlistDir := s.config.WatchlistDir
 
-	s.logger.Infof("Scanning '%s' at interval '%s'", watchlistDir, timeBetweenRuns)
+	s.logger.Infof("Scanning '%s' at interval '%s'", s.config.WatchlistDir, timeBetweenRuns)
 	for {
 		startTime := time.Now()
 
-		if err := filepath.WalkDir(watchlistDir, s.walkCalback); err != nil {
-			s.logger.Errorf("Error walking the path %q: %v\n", watchlistDir, err)
-		}
+		s.scan(ctx)
 
 		select {
 		case <-ctx.Done():
@@ -58,15 +57,33 @@ func (s *Scanner) Scan(ctx context.Context) error {
 	}
 }
 
-func (s *Scanner) walkCalback(path string, d fs.DirEntry, err error) error {
+func (s *Scanner) scan(ctx context.Context) {
+	ids, err := s.watchlists.ListWatchlists(ctx)
 	if err != nil {
-		s.logger.Infof("prevent panic by handling failure accessing a path %q: %v\n", path, err)
-		return err
+		s.logger.Errorf("Error listing the watch lists: %v\n", err)
+		return
 	}
-	if d.Name() == s.config.DataFileName {
-		s.logger.Infof("Scan.walkCallback: Found file: %q\n", path)
+	for _, id := range ids {
+		path := filepath.Join(s.config.WatchlistDir, id, s.config.DataFileName)
+		s.logger.Infof("Scan.scan: Found watch list: %q\n", path)
+		if !s.active(id) {
+			s.logger.Infof("Scan.scan: Skipping inactive watch list %q\n", path)
+			continue
+		}
 		s.changePublsr.Publish(path)
 	}
+}
 
-	return nil
+//active a watch list whose schedules can not be read is searched.
+func (s *Scanner) active(watchlistID string) bool {
+	scheduler, ok := s.watchlists.(store.WatchlistScheduler)
+	if !ok {
+		return true
+	}
+	schedules, err := scheduler.LoadWatchlistSchedules(context.Background(), watchlistID)
+	if err != nil {
+		s.logger.Errorf("Scan.active: Failed to read the schedules of '%s'; %s", watchlistID, err)
+		return true
+	}
+	return schedules.Active(time.Now())
 }
@@ -0,0 +1,157 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/analytics"
+	ebidmodel "github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/model"
+)
+
+//archiveDateLayouts the layouts from and to are accepted in, a date is midnight UTC.
+var archiveDateLayouts = []string{time.RFC3339, "2006-01-02"}
+
+func (s *Server) registerArchiveRoutes(router *mux.Router) *mux.Router {
+	router.Path("/items").Methods("GET").Handler(http.HandlerFunc(s.queryArchivedItemsHandlerFunc)).Name("queryArchivedItems")
+	router.Path("/analytics").Methods("GET").Handler(http.HandlerFunc(s.archiveAnalyticsHandlerFunc)).Name("archiveAnalytics")
+	router.Path("/items/{itemID}").Methods("GET").Handler(http.HandlerFunc(s.archivedItemHandlerFunc)).Name("archivedItem")
+	return router
+}
+
+//queryArchivedItemsHandlerFunc the items whose auction closed, what they sold for. ?keyword= is a query in the watch list query language, ?from= and ?to= the range they closed in, ?minPrice= and ?maxPrice= the range of their final bid and ?limit= the most items sent.
+func (s *Server) queryArchivedItemsHandlerFunc(w http.ResponseWriter, r *http.Request) {
+	if s.archive == nil {
+		respondError(w, http.StatusNotFound, "No item archive")
+		return
+	}
+	query, err := parseArchiveQuery(r.URL.Query())
+	if err == nil {
+		err = query.Validate()
+	}
+	if err != nil {
+		respondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid archive query; %s", err))
+		return
+	}
+
+	items, err := s.archive.QueryArchivedItems(r.Context(), query)
+	if err != nil {
+		s.logger.Error(err)
+		respondError(w, http.StatusInternalServerError, "Failed to query the item archive")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, struct {
+		Items []ebidmodel.ArchivedItem `json:"items"`
+	}{Items: items})
+}
+
+//archiveAnalyticsHandlerFunc what the archived items usually go for, grouped ?by=keyword or ?by=name with the trend split by ?period=day, week or month. The items are selected the same way as queryArchivedItemsHandlerFunc, ?limit= is the most groups sent.
+func (s *Server) archiveAnalyticsHandlerFunc(w http.ResponseWriter, r *http.Request) {
+	if s.archive == nil {
+		respondError(w, http.StatusNotFound, "No item archive")
+		return
+	}
+	var groupBy = analytics.GroupBy(r.URL.Query().Get("by"))
+	var period = analytics.Period(r.URL.Query().Get("period"))
+	if groupBy == "" {
+		groupBy = analytics.ByKeyword
+	}
+	if period == "" {
+		period = analytics.Month
+	}
+	query, err := parseArchiveQuery(r.URL.Query())
+	for _, validate := range []func() error{query.Validate, groupBy.Validate, period.Validate} {
+		if err == nil {
+			err = validate()
+		}
+	}
+	if err != nil {
+		respondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid analytics query; %s", err))
+		return
+	}
+	limit := query.Limit
+	query.Limit = 0
+
+	items, err := s.archive.QueryArchivedItems(r.Context(), query)
+	if err != nil {
+		s.logger.Error(err)
+		respondError(w, http.StatusInternalServerError, "Failed to query the item archive")
+		return
+	}
+	stats := analytics.Aggregate(items, groupBy, period)
+	if limit > 0 && len(stats) > limit {
+		stats = stats[:limit]
+	}
+
+	respondJSON(w, http.StatusOK, struct {
+		By     analytics.GroupBy      `json:"by"`
+		Period analytics.Period       `json:"period"`
+		Groups []analytics.PriceStats `json:"groups"`
+	}{By: groupBy, Period: period, Groups: stats})
+}
+
+func (s *Server) archivedItemHandlerFunc(w http.ResponseWriter, r *http.Request) {
+	if s.archive == nil {
+		respondError(w, http.StatusNotFound, "No item archive")
+		return
+	}
+	item, err := s.archive.LoadArchivedItem(r.Context(), mux.Vars(r)["itemID"])
+	if os.IsNotExist(err) {
+		respondError(w, http.StatusNotFound, "Item Not Found")
+		return
+	} else if err != nil {
+		s.logger.Error(err)
+		respondError(w, http.StatusBadRequest, "Failed to load item")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, item)
+}
+
+func parseArchiveQuery(values url.Values) (ebidmodel.ArchiveQuery, error) {
+	var query = ebidmodel.ArchiveQuery{Keyword: values.Get("keyword")}
+	var err error
+
+	if query.From, err = parseArchiveDate(values.Get("from")); err != nil {
+		return query, fmt.Errorf("from; %w", err)
+	}
+	if query.To, err = parseArchiveDate(values.Get("to")); err != nil {
+		return query, fmt.Errorf("to; %w", err)
+	}
+	for _, param := range []struct {
+		name  string
+		value *float64
+	}{{"minPrice", &query.MinPrice}, {"maxPrice", &query.MaxPrice}} {
+		if raw := values.Get(param.name); raw != "" {
+			if *param.value, err = strconv.ParseFloat(raw, 64); err != nil {
+				return query, fmt.Errorf("%s; %w", param.name, err)
+			}
+		}
+	}
+	if raw := values.Get("limit"); raw != "" {
+		if query.Limit, err = strconv.Atoi(raw); err != nil {
+			return query, fmt.Errorf("limit; %w", err)
+		}
+	}
+	return query, nil
+}
+
+func parseArchiveDate(raw string) (time.Time, error) {
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	var err error
+	for _, layout := range archiveDateLayouts {
+		var t time.Time
+		if t, err = time.Parse(layout, raw); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
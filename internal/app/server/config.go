@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/score"
 	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/log"
 )
 
@@ -47,10 +48,18 @@ func Defaults(config *Config) *Config {
 		config.DataFileName = "data.json"
 		logger.Infof("Defaulting DataFileName to '%s'\n", config.DataFileName)
 	}
+	if config.ContentFileName == "" {
+		config.ContentFileName = "models.json"
+		logger.Infof("Defaulting ContentFileName to '%s'\n", config.ContentFileName)
+	}
 	if config.WatchlistDir == "" {
 		config.WatchlistDir = filepath.Join(config.ContentPath, "web", "watchlists")
 		logger.Infof("Defaulting watchlist dir to '%s'\n", config.WatchlistDir)
 	}
+	if config.ArchiveDir == "" {
+		config.ArchiveDir = filepath.Join(config.ContentPath, "archive", "items")
+		logger.Infof("Defaulting archive dir to '%s'\n", config.ArchiveDir)
+	}
 	if config.VerificationTemplateFile == "" {
 		config.VerificationTemplateFile = filepath.Join(config.ContentPath, "assets", "templates", "verification.template.html.tmpl")
 		logger.Infof("Defaulting verification template dir to '%s'\n", config.VerificationTemplateFile)
@@ -59,6 +68,14 @@ func Defaults(config *Config) *Config {
 		config.VerificationWindowMinutes = 1
 		logger.Infof("Defaulting verification window to '%d' minute(s)\n", config.VerificationWindowMinutes)
 	}
+	if config.SynonymsFile == "" {
+		config.SynonymsFile = filepath.Join(config.ContentPath, "assets", "synonyms.json")
+		logger.Infof("Defaulting synonyms file to '%s'\n", config.SynonymsFile)
+	}
+	if config.ScoreWeights == (score.Weights{}) {
+		config.ScoreWeights = score.DefaultWeights
+		logger.Infof("Defaulting score weights to '%+v'\n", config.ScoreWeights)
+	}
 	if config.ServerUrl == "" {
 		config.ServerUrl = "http://localhost:8282"
 		logger.Infof("Defaulting ServerUrl to '%s'\n", config.ServerUrl)
@@ -87,8 +104,14 @@ type Config struct {
 
 	ContentPath  string `json:"contentPath"`
 	UserDir      string `json:"userDir"`
+	//DataFileName the file of a user's data in their directory, and of a watch list's definition in its directory.
 	DataFileName string `json:"dataFileName"`
+	//ContentFileName the file of a watch list's content in its directory.
+	ContentFileName string `json:"contentFileName"`
+	//WatchlistDir where the files of each watch list are served from. The definition and content are loaded from the store if they are not on disk, the SQLite store keeps them in its database; the web files, such as email.html, are always on disk.
 	WatchlistDir string `json:"watchlistDir"`
+	//ArchiveDir where the items whose auction closed are kept, see model.ArchivedItem.
+	ArchiveDir string `json:"archiveDir"`
 
 	VerificationTemplateFile  string        `json:"verificationTemplateFile"`
 	VerificationWindowMinutes time.Duration `json:"verificationWindowMinutes"`
@@ -96,6 +119,10 @@ type Config struct {
 	UiUrl                     string        `json:"uiUrl"`
 
     SearchVersion string `json:"searchVersion"`
+	//ScoreWeights how quick search results are ranked.
+	ScoreWeights score.Weights `json:"scoreWeights"`
+	//SynonymsFile the shared synonym dictionary quick search queries are expanded with.
+	SynonymsFile string `json:"synonymsFile"`
 
 	Debug    bool         `json:"debug"`
 	LogLevel log.LogLevel `json:"logLevel"`
@@ -45,3 +45,15 @@ func (e *VerifyNotVerifiedError) Error() string {
 func (e *VerifyNotVerifiedError) String() string {
 	return "User Not Verified"
 }
+
+//================================================
+
+type SubscribedWatchlistError struct{}
+
+func (e *SubscribedWatchlistError) Error() string {
+	return "Only The Owner Can Edit A Subscribed Watch List"
+}
+
+func (e *SubscribedWatchlistError) String() string {
+	return "Only The Owner Can Edit A Subscribed Watch List"
+}
@@ -0,0 +1,93 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/scirelli/auction-ebidlocal-search/internal/app/server/model"
+	"github.com/scirelli/auction-ebidlocal-search/internal/app/server/store"
+	ebidmodel "github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/model"
+)
+
+//watchlistHistoryHandlerFunc the saved versions of the content of the user's watch list, oldest first.
+func (s *Server) watchlistHistoryHandlerFunc(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+
+	listID, ok := s.userWatchlistID(w, r, params["userID"], params["name"])
+	if !ok {
+		return
+	}
+	snapshots, err := s.store.ListWatchlistSnapshots(r.Context(), listID)
+	if err != nil {
+		s.logger.Error(err)
+		respondError(w, http.StatusInternalServerError, "Failed to list watch list history")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, struct {
+		WatchlistID string                        `json:"watchlistID"`
+		Snapshots   []ebidmodel.WatchlistSnapshot `json:"snapshots"`
+	}{WatchlistID: listID, Snapshots: snapshots})
+}
+
+//watchlistSnapshotHandlerFunc a saved version of the content of the user's watch list.
+func (s *Server) watchlistSnapshotHandlerFunc(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+
+	listID, ok := s.userWatchlistID(w, r, params["userID"], params["name"])
+	if !ok {
+		return
+	}
+	content, err := s.store.LoadWatchlistSnapshot(r.Context(), listID, params["snapshotID"])
+	if os.IsNotExist(err) {
+		respondError(w, http.StatusNotFound, "Snapshot Not Found")
+		return
+	} else if err != nil {
+		s.logger.Error(err)
+		respondError(w, http.StatusBadRequest, "Failed to load snapshot")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, content)
+}
+
+//userWatchlistID the ID of the user's watch list, responds with not found if the user or their watch list does not exist, see pickWatchlistID.
+func (s *Server) userWatchlistID(w http.ResponseWriter, r *http.Request, userID string, name string) (string, bool) {
+	user, err := s.store.LoadUser(r.Context(), userID)
+	if err != nil {
+		s.logger.Error(err)
+		respondError(w, http.StatusNotFound, "User Not Found")
+		return "", false
+	}
+	return pickWatchlistID(w, r, user, name)
+}
+
+//pickWatchlistID the ID of the user's watch list, responds with not found if they have no such watch list. A watch list with more than one comma separated ID needs the one to use in the watchlistID query parameter, responds with bad request without it.
+func pickWatchlistID(w http.ResponseWriter, r *http.Request, user *model.User, name string) (string, bool) {
+	entry, exists := user.Watchlists[name]
+	if !exists {
+		respondError(w, http.StatusNotFound, "Watch list Not Found")
+		return "", false
+	}
+
+	listIDs := store.WatchlistIDs(entry)
+	if len(listIDs) == 1 {
+		return listIDs[0], true
+	}
+	wanted := r.URL.Query().Get("watchlistID")
+	for _, listID := range listIDs {
+		if listID == wanted {
+			return listID, true
+		}
+	}
+	if wanted != "" || len(listIDs) == 0 {
+		respondError(w, http.StatusNotFound, "Watch list Not Found")
+		return "", false
+	}
+	respondError(w, http.StatusBadRequest, fmt.Sprintf("Watch list '%s' has more than one watch list ID, pick one with the watchlistID query parameter: %s", name, strings.Join(listIDs, ", ")))
+	return "", false
+}
@@ -0,0 +1,68 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/scirelli/auction-ebidlocal-search/internal/app/server/model"
+	"github.com/scirelli/auction-ebidlocal-search/internal/app/server/store/memory"
+	ebidmodel "github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/model"
+	ebidmemory "github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/store/memory"
+)
+
+func TestWatchlistHistoryHandler(t *testing.T) {
+	var ctx = context.Background()
+	var watchlists = ebidmemory.New(ebidmemory.Config{})
+	var store = newTestStore()
+	store.Store = memory.New(watchlists)
+	var s = newTestServer(t, store)
+
+	kayaks, err := store.SaveWatchlist(ctx, &model.Watchlist{List: []string{"kayak"}})
+	assert.NoError(t, err)
+	canoes, err := store.SaveWatchlist(ctx, &model.Watchlist{List: []string{"canoe"}})
+	assert.NoError(t, err)
+	for _, listID := range []string{kayaks, canoes} {
+		_, err = watchlists.SaveWatchlistContent(ctx, &ebidmodel.WatchlistContent{WatchlistID: listID})
+		assert.NoError(t, err)
+	}
+	_, err = store.SaveUser(ctx, &model.User{ID: "u1", Watchlists: map[string]string{"Kayaks": kayaks, "Boats": kayaks + "," + canoes}})
+	assert.NoError(t, err)
+
+	history := func(name string, target string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		s.watchlistHistoryHandlerFunc(w, mux.SetURLVars(httptest.NewRequest(http.MethodGet, target, nil), map[string]string{"userID": "u1", "name": name}))
+		return w
+	}
+	var body struct {
+		WatchlistID string                        `json:"watchlistID"`
+		Snapshots   []ebidmodel.WatchlistSnapshot `json:"snapshots"`
+	}
+
+	w := history("Kayaks", "/")
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, kayaks, body.WatchlistID)
+	assert.Len(t, body.Snapshots, 1)
+
+	w = history("Boats", "/")
+	assert.Equal(t, http.StatusBadRequest, w.Code, "A watch list with more than one ID needs the one to use")
+	assert.Contains(t, w.Body.String(), kayaks)
+	assert.Contains(t, w.Body.String(), canoes)
+
+	w = history("Boats", "/?watchlistID="+canoes)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, canoes, body.WatchlistID)
+
+	w = history("Boats", "/?watchlistID=unknown")
+	assert.Equal(t, http.StatusNotFound, w.Code, "Only the watch list's own IDs are picked")
+
+	w = history("Canoes", "/")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,207 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/scirelli/auction-ebidlocal-search/internal/app/server/model"
+	"github.com/scirelli/auction-ebidlocal-search/internal/app/server/store"
+)
+
+//importReport what an import created, or would create on a dry run, and why the rest were not.
+type importReport struct {
+	DryRun     bool           `json:"dryRun"`
+	Created    []importResult `json:"created"`
+	Duplicates []importResult `json:"duplicates"`
+	Errors     []importError  `json:"errors"`
+}
+
+type importResult struct {
+	Row         int    `json:"row"`
+	Name        string `json:"name"`
+	WatchlistID string `json:"watchlistID"`
+	//DuplicateOf the name of the watch list with the same ID, the user's or one earlier in the import.
+	DuplicateOf string `json:"duplicateOf,omitempty"`
+}
+
+type importError struct {
+	Row   int    `json:"row"`
+	Name  string `json:"name,omitempty"`
+	Error string `json:"error"`
+}
+
+//exportUserWatchlistsHandlerFunc the user's watch lists as JSON, or as CSV with ?format=csv. An entry with more than one watch list ID is exported as a watch list per ID with the entry's name, each with the entry's schedule.
+func (s *Server) exportUserWatchlistsHandlerFunc(w http.ResponseWriter, r *http.Request) {
+	userID := mux.Vars(r)["userID"]
+
+	user, err := s.store.LoadUser(r.Context(), userID)
+	if err != nil {
+		s.logger.Error(err)
+		respondError(w, http.StatusNotFound, "User Not Found")
+		return
+	}
+
+	var names = make([]string, 0, len(user.Watchlists))
+	for name := range user.Watchlists {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var lists = make([]model.Watchlist, 0, len(names))
+	for _, name := range names {
+		for _, listID := range store.WatchlistIDs(user.Watchlists[name]) {
+			list, err := s.store.LoadWatchlist(r.Context(), listID)
+			if err != nil {
+				s.logger.Errorf("Failed to load watch list '%s' (%s) for export; %s", name, listID, err)
+				respondError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to load watch list '%s'", name))
+				return
+			}
+			list.Name, list.Schedule = name, user.Schedules[name]
+			if list.Owner != user.ID {
+				list.Owner = ""
+			}
+			lists = append(lists, *list)
+		}
+	}
+
+	var b bytes.Buffer
+	var contentType, fileName = "application/json", "watchlists.json"
+	if r.URL.Query().Get("format") == "csv" {
+		contentType, fileName = "text/csv", "watchlists.csv"
+		err = model.WriteWatchlistsCSV(&b, lists)
+	} else {
+		err = model.WriteWatchlistsJSON(&b, lists)
+	}
+	if err != nil {
+		s.logger.Error(err)
+		respondError(w, http.StatusInternalServerError, "Failed to export watch lists")
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": fileName}))
+	w.WriteHeader(http.StatusOK)
+	w.Write(b.Bytes())
+}
+
+//importUserWatchlistsHandlerFunc creates watch lists from a JSON or CSV export. Watch lists the user already has, by ID, are skipped. A watch list named like one created earlier in the import is added to its entry, the way an entry with more than one watch list ID is exported. With ?dryRun=true nothing is created, the report is what would be.
+func (s *Server) importUserWatchlistsHandlerFunc(w http.ResponseWriter, r *http.Request) {
+	var lists []model.ImportedWatchlist
+	var err error
+
+	userID := mux.Vars(r)["userID"]
+	dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dryRun"))
+
+	defer r.Body.Close()
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "text/csv" {
+		lists, err = model.ReadWatchlistsCSV(r.Body)
+	} else {
+		lists, err = model.ReadWatchlistsJSON(r.Body)
+	}
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	user, err := s.store.LoadUser(r.Context(), userID)
+	if err != nil {
+		s.logger.Error(err)
+		respondError(w, http.StatusNotFound, "User Not Found")
+		return
+	}
+
+	var report = importReport{DryRun: dryRun, Created: []importResult{}, Duplicates: []importResult{}, Errors: []importError{}}
+	var names = make(map[string]string)
+	for name, entry := range user.Watchlists {
+		for _, listID := range store.WatchlistIDs(entry) {
+			names[listID] = name
+		}
+	}
+	var imported = make(map[string]bool)
+	for i := range lists {
+		list := &lists[i]
+		if err := validateImport(list); err != nil {
+			report.Errors = append(report.Errors, importError{Row: list.Row, Name: list.Name, Error: err.Error()})
+			continue
+		}
+
+		var withSynonyms = list.Watchlist
+		addUserSynonyms(user, &withSynonyms)
+		result := importResult{Row: list.Row, Name: list.Name, WatchlistID: withSynonyms.ID()}
+		if name, exists := names[result.WatchlistID]; exists {
+			result.DuplicateOf = name
+			report.Duplicates = append(report.Duplicates, result)
+			continue
+		}
+		entry, exists := user.Watchlists[list.Name]
+		if exists && !imported[list.Name] {
+			report.Errors = append(report.Errors, importError{Row: list.Row, Name: list.Name, Error: fmt.Sprintf("Watch list '%s' already exists", list.Name)})
+			continue
+		}
+		names[result.WatchlistID], imported[list.Name] = list.Name, true
+		user.Watchlists[list.Name] = strings.Join(append(store.WatchlistIDs(entry), result.WatchlistID), ",")
+
+		if !dryRun {
+			var add = s.addUserWatchlist
+			if exists {
+				add = s.addUserWatchlistPart
+			}
+			if result.WatchlistID, err = add(r.Context(), user.ID, &list.Watchlist); err != nil {
+				s.logger.Error(err)
+				report.Errors = append(report.Errors, importError{Row: list.Row, Name: list.Name, Error: "Failed to create watch list."})
+				continue
+			}
+		}
+		report.Created = append(report.Created, result)
+	}
+
+	s.logger.Infof("Imported %d watch lists for '%s', dry run %t", len(report.Created), user.ID, dryRun)
+	respondJSON(w, http.StatusOK, report)
+}
+
+//validateImport the checks createUserWatchlistHandlerFunc makes.
+func validateImport(list *model.ImportedWatchlist) error {
+	if list.Err != nil {
+		return list.Err
+	}
+	if list.Name == "" {
+		return fmt.Errorf("Watch list name is required")
+	}
+	if !list.IsValid() {
+		return fmt.Errorf("User watchlist is required.")
+	}
+	return validateWatchlist(&list.Watchlist)
+}
+
+//addUserWatchlistPart adds the watch list's ID to the user's entry of the same name, which already has one. The entry's schedule is kept.
+func (s *Server) addUserWatchlistPart(ctx context.Context, userID string, list *model.Watchlist) (listID string, err error) {
+	user, err := s.store.LoadUser(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	if _, subscribed := user.Subscriptions[list.Name]; subscribed {
+		return "", &SubscribedWatchlistError{}
+	}
+
+	addUserSynonyms(user, list)
+	list.Owner, list.Created = user.ID, time.Now()
+	if listID, err = s.store.SaveWatchlist(ctx, list); err != nil {
+		return "", err
+	}
+
+	user.Watchlists[list.Name] = strings.Join(append(store.WatchlistIDs(user.Watchlists[list.Name]), listID), ",")
+	if _, err = s.store.SaveUser(ctx, user); err != nil {
+		return "", err
+	}
+	s.syncWatchlistSchedules(ctx, listID)
+	return listID, nil
+}
@@ -0,0 +1,139 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/scirelli/auction-ebidlocal-search/internal/app/server/model"
+	ebidmodel "github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/model"
+)
+
+func importWatchlists(s *Server, userID string, target string, contentType string, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+	r.Header.Set("Content-Type", contentType)
+	s.importUserWatchlistsHandlerFunc(w, mux.SetURLVars(r, map[string]string{"userID": userID}))
+	return w
+}
+
+func exportWatchlists(s *Server, userID string, target string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	s.exportUserWatchlistsHandlerFunc(w, mux.SetURLVars(httptest.NewRequest(http.MethodGet, target, nil), map[string]string{"userID": userID}))
+	return w
+}
+
+func TestImportWatchlistsDryRun(t *testing.T) {
+	var ctx = context.Background()
+	var store = newTestStore()
+	var s = newTestServer(t, store)
+	_, err := store.SaveUser(ctx, &model.User{ID: "u1", Watchlists: map[string]string{}})
+	assert.NoError(t, err)
+
+	w := importWatchlists(s, "u1", "/?dryRun=true", "text/csv", "name,keyword\nBoats,kayak\n")
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var report importReport
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	assert.True(t, report.DryRun)
+	var listID = (&model.Watchlist{List: []string{"kayak"}}).ID()
+	if assert.Len(t, report.Created, 1) {
+		assert.Equal(t, listID, report.Created[0].WatchlistID)
+	}
+
+	user, err := store.LoadUser(ctx, "u1")
+	assert.NoError(t, err)
+	assert.Empty(t, user.Watchlists, "Nothing is created on a dry run")
+	_, err = store.LoadWatchlist(ctx, listID)
+	assert.Error(t, err)
+}
+
+func TestImportWatchlistsDuplicates(t *testing.T) {
+	var ctx = context.Background()
+	var store = newTestStore()
+	var s = newTestServer(t, store)
+	kayaks, err := store.SaveWatchlist(ctx, &model.Watchlist{List: []string{"kayak"}})
+	assert.NoError(t, err)
+	_, err = store.SaveUser(ctx, &model.User{ID: "u1", Watchlists: map[string]string{"Boats": "other," + kayaks}})
+	assert.NoError(t, err)
+
+	w := importWatchlists(s, "u1", "/", "application/json", `[{"name": "Kayaks", "list": ["kayak"]}, {"name": "Tools", "list": ["drill"]}, {"name": "Drills", "list": ["drill"]}]`)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var report importReport
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	if assert.Len(t, report.Duplicates, 2) {
+		assert.Equal(t, importResult{Row: 0, Name: "Kayaks", WatchlistID: kayaks, DuplicateOf: "Boats"}, report.Duplicates[0], "A watch list the user has in an entry with more than one ID")
+		assert.Equal(t, "Tools", report.Duplicates[1].DuplicateOf, "A watch list earlier in the import")
+	}
+	if assert.Len(t, report.Created, 1) {
+		assert.Equal(t, "Tools", report.Created[0].Name)
+	}
+}
+
+func TestImportWatchlistsErrors(t *testing.T) {
+	var ctx = context.Background()
+	var store = newTestStore()
+	var s = newTestServer(t, store)
+	_, err := store.SaveUser(ctx, &model.User{ID: "u1", Watchlists: map[string]string{"Boats": "a"}})
+	assert.NoError(t, err)
+
+	w := importWatchlists(s, "u1", "/", "text/csv", "name,keyword,options\nBoats,canoe,\n,drill,\nTools,,\nLamps,lamp,{bad\nChairs,chair,\n")
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var report importReport
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	var rows []int
+	for _, e := range report.Errors {
+		assert.NotEmpty(t, e.Error)
+		rows = append(rows, e.Row)
+	}
+	assert.Equal(t, []int{2, 3, 4, 5}, rows, "A name taken, no name, no keywords and options that are not JSON are reported by line")
+	if assert.Len(t, report.Created, 1) {
+		assert.Equal(t, "Chairs", report.Created[0].Name)
+	}
+
+	w = importWatchlists(s, "u1", "/", "text/csv", "keyword\nchair\n")
+	assert.Equal(t, http.StatusBadRequest, w.Code, "A file that can not be read at all")
+}
+
+func TestExportImportWatchlistsWithMoreThanOneID(t *testing.T) {
+	var ctx = context.Background()
+	var store = newTestStore()
+	var s = newTestServer(t, store)
+	kayaks, err := store.SaveWatchlist(ctx, &model.Watchlist{List: []string{"kayak"}})
+	assert.NoError(t, err)
+	canoes, err := store.SaveWatchlist(ctx, &model.Watchlist{List: []string{"canoe"}})
+	assert.NoError(t, err)
+	var paused = ebidmodel.WatchlistSchedule{Paused: true}
+	_, err = store.SaveUser(ctx, &model.User{ID: "u1", Watchlists: map[string]string{"Boats": kayaks + "," + canoes}, Schedules: map[string]ebidmodel.WatchlistSchedule{"Boats": paused}})
+	assert.NoError(t, err)
+	_, err = store.SaveUser(ctx, &model.User{ID: "u2", Watchlists: map[string]string{}})
+	assert.NoError(t, err)
+
+	for _, format := range []struct{ query, contentType string }{{"", "application/json"}, {"?format=csv", "text/csv"}} {
+		t.Run(format.contentType, func(t *testing.T) {
+			_, err = store.SaveUser(ctx, &model.User{ID: "u2", Watchlists: map[string]string{}})
+			assert.NoError(t, err)
+
+			w := exportWatchlists(s, "u1", "/"+format.query)
+			assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+			assert.Contains(t, w.Header().Get("Content-Type"), format.contentType)
+
+			w = importWatchlists(s, "u2", "/", format.contentType, w.Body.String())
+			assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+			var report importReport
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+			assert.Empty(t, report.Errors)
+			assert.Len(t, report.Created, 2)
+
+			user, err := store.LoadUser(ctx, "u2")
+			assert.NoError(t, err)
+			assert.Equal(t, kayaks+","+canoes, user.Watchlists["Boats"], "Every ID of the entry is exported and imported into one entry")
+			assert.Equal(t, paused, user.Schedules["Boats"], "The schedule is carried through")
+		})
+	}
+}
@@ -4,10 +4,12 @@ import (
 	b64 "encoding/base64"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 
+	ebidmodel "github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/model"
 	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/generator"
 	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/log"
 )
@@ -37,6 +39,34 @@ type User struct {
 
 	//Wathclist names to ids
 	Watchlists map[string]string `json:"watchlists"`
+	//Synonyms the user's additions to the shared synonym dictionary, copied into the options of the watch lists they create.
+	Synonyms [][]string `json:"synonyms,omitempty"`
+	//Shares share tokens to the names of the user's watch lists they share.
+	Shares map[string]string `json:"shares,omitempty"`
+	//Subscriptions names of watch lists the user subscribed to, to the share they subscribed with. Only the owner edits a subscribed watch list.
+	Subscriptions map[string]Subscription `json:"subscriptions,omitempty"`
+	//Schedules names of watch lists to when they are searched, a watch list without a schedule always is.
+	Schedules map[string]ebidmodel.WatchlistSchedule `json:"schedules,omitempty"`
+}
+
+//WatchlistActive true if one of the user's watch lists with the ID is searched at now, see ebidmodel.WatchlistSchedule.
+func (u User) WatchlistActive(watchlistID string, now time.Time) bool {
+	for name, listIDs := range u.Watchlists {
+		for _, listID := range strings.Split(listIDs, ",") {
+			if strings.TrimSpace(listID) == watchlistID && u.Schedules[name].Active(now) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+//Subscription a watch list shared by another user. The subscriber's entry in Watchlists follows the owner's edits.
+type Subscription struct {
+	//Owner the ID of the user who shared the watch list.
+	Owner string `json:"owner"`
+	//Token the owner's share token.
+	Token string `json:"token"`
 }
 
 func (u User) String() string {
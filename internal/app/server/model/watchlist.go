@@ -1,10 +1,58 @@
 package model
 
+import (
+	"time"
+
+	ebidmodel "github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/model"
+)
+
 type Watchlist struct {
-	List []string `json:"list"`
-	Name string   `json:"name"`
+	List        []string                   `json:"list"`
+	Name        string                     `json:"name"`
+	Description string                     `json:"description,omitempty"`
+	Options     ebidmodel.WatchlistOptions `json:"options"`
+	//Owner and Created are set by the server, see ebidmodel.WatchlistMetadata.
+	Owner   string    `json:"owner,omitempty"`
+	Created time.Time `json:"created"`
+	//Schedule the user's schedule for the watch list, it is kept with the user, see User.Schedules.
+	Schedule ebidmodel.WatchlistSchedule `json:"schedule"`
 }
 
 func (wl *Watchlist) IsValid() bool {
 	return len(wl.List) > 0
 }
+
+//WatchlistPatch edits a user's watch list. Keywords are removed before they are added, removed keywords are matched the way the watch list normalizes them.
+type WatchlistPatch struct {
+	Add    []string `json:"add,omitempty"`
+	Remove []string `json:"remove,omitempty"`
+	//Rename the user's new name for the watch list.
+	Rename string `json:"rename,omitempty"`
+	//Schedule replaces the user's schedule for the watch list, pause, snooze or set it to expire.
+	Schedule *ebidmodel.WatchlistSchedule `json:"schedule,omitempty"`
+}
+
+//Apply the patch's keyword changes to the list. Changed is false when the keywords are the same, the watch list keeps its ID.
+func (p WatchlistPatch) Apply(list []string) (keywords []string, changed bool) {
+	var remove = make(map[string]struct{}, len(p.Remove))
+	for _, keyword := range p.Remove {
+		remove[normalizeKeyword(keyword)] = struct{}{}
+	}
+
+	var before = append(ebidmodel.Watchlist{}, list...)
+	var after = make(ebidmodel.Watchlist, 0, len(list)+len(p.Add))
+	for _, keyword := range list {
+		if _, removed := remove[normalizeKeyword(keyword)]; !removed {
+			after = append(after, keyword)
+		}
+	}
+	after = append(after, p.Add...)
+
+	var normalized = append(ebidmodel.Watchlist{}, after...)
+	return after, before.Normalize().ID() != normalized.Normalize().ID()
+}
+
+func normalizeKeyword(keyword string) string {
+	var w = ebidmodel.Watchlist{keyword}
+	return (*w.Normalize())[0]
+}
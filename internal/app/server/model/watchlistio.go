@@ -0,0 +1,171 @@
+package model
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	ebidmodel "github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/model"
+)
+
+//CSVHeader the columns of a watch list CSV file. A watch list is a row per keyword, description, options and schedule are read from the first of its rows that has them. Options and schedule are JSON. A user's entry with more than one watch list ID is a watch list per ID with the same name, told apart by part, blank for the first and 2 and on for the rest.
+var CSVHeader = []string{"name", "keyword", "description", "options", "schedule", "part"}
+
+//ImportedWatchlist a watch list read from an import.
+type ImportedWatchlist struct {
+	Watchlist
+	//Row where the watch list was read from for error reports, its index in a JSON import or the line of its first row in a CSV import.
+	Row int
+	//Err why the watch list could not be read.
+	Err error
+}
+
+//ID the ID the watch list is stored under, see ebidmodel.WatchlistDefinition.
+func (wl Watchlist) ID() string {
+	var d = ebidmodel.WatchlistDefinition{Keywords: append(ebidmodel.Watchlist{}, wl.List...), Options: wl.Options}
+	return d.Normalize().ID()
+}
+
+//ReadWatchlistsJSON reads an array of watch lists.
+func ReadWatchlistsJSON(r io.Reader) ([]ImportedWatchlist, error) {
+	var raw []json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, err
+	}
+
+	var lists = make([]ImportedWatchlist, len(raw))
+	for i, b := range raw {
+		lists[i].Row = i
+		lists[i].Err = json.Unmarshal(b, &lists[i].Watchlist)
+	}
+	return lists, nil
+}
+
+//WriteWatchlistsJSON writes the watch lists as an array.
+func WriteWatchlistsJSON(w io.Writer, lists []Watchlist) error {
+	return json.NewEncoder(w).Encode(lists)
+}
+
+//ReadWatchlistsCSV reads watch lists from CSV with a header row, see CSVHeader. Rows with the same name are the keywords of the same watch list, a row that can not be read is a watch list of its own with an error.
+func ReadWatchlistsCSV(r io.Reader) ([]ImportedWatchlist, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	var columns = make(map[string]int)
+	for i, column := range header {
+		columns[strings.ToLower(strings.TrimSpace(column))] = i
+	}
+	for _, required := range CSVHeader[:2] {
+		if _, exists := columns[required]; !exists {
+			return nil, fmt.Errorf("missing column '%s'", required)
+		}
+	}
+	field := func(record []string, column string) string {
+		if i, exists := columns[column]; exists && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var lists []ImportedWatchlist
+	var byPart = make(map[[2]string]int)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var line int
+			if parseErr, ok := err.(*csv.ParseError); ok {
+				line = parseErr.StartLine
+			}
+			lists = append(lists, ImportedWatchlist{Row: line, Err: err})
+			continue
+		}
+		line, _ := reader.FieldPos(0)
+
+		name := field(record, "name")
+		if name == "" {
+			lists = append(lists, ImportedWatchlist{Row: line, Err: fmt.Errorf("watch list name is required")})
+			continue
+		}
+		part := [2]string{name, field(record, "part")}
+		if part[1] == "1" {
+			part[1] = ""
+		}
+		i, exists := byPart[part]
+		if !exists {
+			i, byPart[part] = len(lists), len(lists)
+			lists = append(lists, ImportedWatchlist{Row: line, Watchlist: Watchlist{Name: name}})
+		}
+		list := &lists[i]
+
+		if keyword := field(record, "keyword"); keyword != "" {
+			list.List = append(list.List, keyword)
+		}
+		if description := field(record, "description"); description != "" && list.Description == "" {
+			list.Description = description
+		}
+		if options := field(record, "options"); options != "" && list.Options.IsZero() && list.Err == nil {
+			if err := json.Unmarshal([]byte(options), &list.Options); err != nil {
+				list.Err = fmt.Errorf("line %d: options: %w", line, err)
+			}
+		}
+		if schedule := field(record, "schedule"); schedule != "" && list.Schedule.IsZero() && list.Err == nil {
+			if err := json.Unmarshal([]byte(schedule), &list.Schedule); err != nil {
+				list.Err = fmt.Errorf("line %d: schedule: %w", line, err)
+			}
+		}
+	}
+	return lists, nil
+}
+
+//WriteWatchlistsCSV writes the watch lists as CSV with a header row, see CSVHeader. Watch lists with the same name are the parts of the user's entry in order.
+func WriteWatchlistsCSV(w io.Writer, lists []Watchlist) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(CSVHeader); err != nil {
+		return err
+	}
+
+	var parts = make(map[string]int)
+	for _, list := range lists {
+		var options, schedule, part string
+		if !list.Options.IsZero() {
+			b, err := json.Marshal(list.Options)
+			if err != nil {
+				return err
+			}
+			options = string(b)
+		}
+		if !list.Schedule.IsZero() {
+			b, err := json.Marshal(list.Schedule)
+			if err != nil {
+				return err
+			}
+			schedule = string(b)
+		}
+		if parts[list.Name]++; parts[list.Name] > 1 {
+			part = strconv.Itoa(parts[list.Name])
+		}
+		for i, keyword := range list.List {
+			var record = []string{list.Name, keyword, "", "", "", part}
+			if i == 0 {
+				record[2], record[3], record[4] = list.Description, options, schedule
+			}
+			if err := writer.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
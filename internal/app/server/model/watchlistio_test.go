@@ -0,0 +1,49 @@
+package model
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	ebidmodel "github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/model"
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/match"
+)
+
+func TestWatchlistsCSV(t *testing.T) {
+	var lists = []Watchlist{
+		{Name: "Tools", Description: "Shop, garage", List: []string{"drill", `"table saw"`}, Options: ebidmodel.WatchlistOptions{Match: match.Options{Fuzzy: 1}}},
+		{Name: "Boats", List: []string{"kayak"}, Schedule: ebidmodel.WatchlistSchedule{Paused: true}},
+		{Name: "Boats", List: []string{"canoe"}},
+	}
+
+	var b bytes.Buffer
+	assert.NoError(t, WriteWatchlistsCSV(&b, lists))
+
+	imported, err := ReadWatchlistsCSV(&b)
+	assert.NoError(t, err)
+	assert.Len(t, imported, 3, "The parts of an entry with more than one watch list ID are watch lists of their own")
+	for i, list := range imported {
+		assert.NoError(t, list.Err)
+		assert.Equal(t, lists[i], list.Watchlist)
+		assert.Equal(t, lists[i].ID(), list.ID())
+	}
+	assert.Equal(t, 2, imported[0].Row, "Rows are reported by line, the header is line 1")
+}
+
+func TestReadWatchlistsCSVErrors(t *testing.T) {
+	_, err := ReadWatchlistsCSV(strings.NewReader("keyword\ndrill\n"))
+	assert.Error(t, err, "The name column is required")
+
+	imported, err := ReadWatchlistsCSV(strings.NewReader("Keyword,Name,Options\ndrill,Tools,\n,,\nsaw,Tools,\nkayak,Boats,{bad\n"))
+	assert.NoError(t, err)
+	assert.Len(t, imported, 3)
+
+	assert.Equal(t, []string{"drill", "saw"}, imported[0].List, "Rows with the same name are one watch list")
+	assert.NoError(t, imported[0].Err)
+	assert.Equal(t, 3, imported[1].Row)
+	assert.Error(t, imported[1].Err, "A row without a name")
+	assert.Equal(t, "Boats", imported[2].Name)
+	assert.Error(t, imported[2].Err, "Options that are not JSON")
+}
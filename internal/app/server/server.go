@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
 	"io"
@@ -13,6 +14,8 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/gorilla/handlers"
@@ -23,19 +26,27 @@ import (
 	"github.com/scirelli/auction-ebidlocal-search/internal/app/server/model"
 	"github.com/scirelli/auction-ebidlocal-search/internal/app/server/store"
 	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/filter"
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/match"
 	ebidmodel "github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/model"
+	ebidstore "github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/store"
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/query"
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/score"
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/search"
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/synonym"
 	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/iter/stringiter"
 	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/log"
 	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/notify/email"
 	stringutils "github.com/scirelli/auction-ebidlocal-search/internal/pkg/stringUtils"
 )
 
-func New(config Config, store store.Storer, logger log.Logger, searchExtractor SearchExtractor) *Server {
+//New the archive is where the items whose auction closed are queried, nil has no archive.
+func New(config Config, store store.Storer, logger log.Logger, searchExtractor SearchExtractor, archive ebidstore.ItemArchiver) *Server {
 	var server = Server{
 		config:          config,
 		logger:          logger,
 		store:           store,
 		searchExtractor: searchExtractor,
+		archive:         archive,
 	}
 
 	t, err := template.New("verification.template.html.tmpl").Funcs(template.FuncMap{
@@ -50,6 +61,10 @@ func New(config Config, store store.Storer, logger log.Logger, searchExtractor S
 		logger.Fatal(err)
 	}
 
+	if server.synonyms, err = synonym.Load(config.SynonymsFile); err != nil {
+		logger.Warnf("Server.New: No shared synonyms; %s", err)
+	}
+
 	server.template = t
 	server.addr = fmt.Sprintf("%s:%d", config.Address, config.Port)
 	server.registerHTTPHandlers()
@@ -64,6 +79,8 @@ type Server struct {
 	config          Config
 	template        *template.Template
 	searchExtractor SearchExtractor
+	synonyms        *synonym.Dictionary
+	archive         ebidstore.ItemArchiver
 }
 
 func (s *Server) Run() {
@@ -77,6 +94,7 @@ func (s *Server) registerHTTPHandlers() {
 	s.registerUserRoutes(r.PathPrefix("/user").Subrouter())
 	s.registerWatchlistRoutes(r.PathPrefix("/watchlist").Subrouter())
 	s.registerSearchRoutes(r.PathPrefix("/search").Subrouter())
+	s.registerArchiveRoutes(r.PathPrefix("/archive").Subrouter())
 
 	r.PathPrefix("/").Handler(http.FileServer(http.Dir(filepath.Join(s.config.ContentPath, "/web/static"))))
 
@@ -92,6 +110,9 @@ func (s *Server) registerUserRoutes(router *mux.Router) *mux.Router {
 	router.Path("/{userID}/watchlist").Methods("POST", "UPDATE").Handler(handlers.ContentTypeHandler(http.HandlerFunc(s.createUserWatchlistHandlerFunc), "application/json")).Name("createAndEditWatchlist")
 	router.Path("/{userID}/watchlist").Methods("DELETE").Handler(handlers.ContentTypeHandler(http.HandlerFunc(s.deleteUserWatchlistHandlerFunc), "application/json")).Name("deleteWatchlist")
 
+	router.Path("/{userID}/watchlists/export").Methods("GET").Handler(http.HandlerFunc(s.exportUserWatchlistsHandlerFunc)).Name("exportUserWatchlists")
+	router.Path("/{userID}/watchlists/import").Methods("POST").Handler(handlers.ContentTypeHandler(http.HandlerFunc(s.importUserWatchlistsHandlerFunc), "application/json", "text/csv")).Name("importUserWatchlists")
+
 	router.Path("/{userID}/data.json").Methods("GET").Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		userID := mux.Vars(r)["userID"]
 		user, err := s.store.LoadUser(r.Context(), userID)
@@ -115,18 +136,25 @@ func (s *Server) registerUserRoutes(router *mux.Router) *mux.Router {
 		})
 	})).Name("userData")
 
-	router.PathPrefix("/{userID}/watchlist/{listID}/").Methods("GET").Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		params := mux.Vars(r)
-		userID := params["userID"]
-		listID := params["listID"]
+	router.Path("/{userID}/watchlist/{name}").Methods("GET").Handler(http.HandlerFunc(s.getUserWatchlistHandlerFunc)).Name("getUserWatchlistDefinition")
+	router.Path("/{userID}/watchlist/{name}").Methods("PATCH").Handler(handlers.ContentTypeHandler(http.HandlerFunc(s.patchUserWatchlistHandlerFunc), "application/json")).Name("patchUserWatchlist")
 
-		d := filepath.Join(s.config.WatchlistDir, listID)
-		rm := fmt.Sprintf("/user/%s/watchlist/%s", userID, listID)
-		http.StripPrefix(rm, http.FileServer(http.Dir(d))).ServeHTTP(w, r)
+	router.Path("/{userID}/watchlist/{name}/share").Methods("POST").Handler(http.HandlerFunc(s.shareUserWatchlistHandlerFunc)).Name("shareUserWatchlist")
+	router.Path("/{userID}/watchlist/{name}/subscribers").Methods("GET").Handler(http.HandlerFunc(s.watchlistSubscribersHandlerFunc)).Name("watchlistSubscribers")
+	router.Path("/{userID}/watchlist/{name}/history").Methods("GET").Handler(http.HandlerFunc(s.watchlistHistoryHandlerFunc)).Name("watchlistHistory")
+	router.Path("/{userID}/watchlist/{name}/history/{snapshotID}").Methods("GET").Handler(http.HandlerFunc(s.watchlistSnapshotHandlerFunc)).Name("watchlistSnapshot")
+	router.Path("/{userID}/subscriptions").Methods("POST").Handler(handlers.ContentTypeHandler(http.HandlerFunc(s.subscribeUserHandlerFunc), "application/json")).Name("subscribeUser")
+
+	router.PathPrefix("/{userID}/watchlist/{listID}/").Methods("GET").Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID := mux.Vars(r)["userID"]
+		rm := fmt.Sprintf("/user/%s/watchlist", userID)
+		http.StripPrefix(rm, s.watchlistFileServer()).ServeHTTP(w, r)
 	})).Name("getUserWatchlist")
 
 	router.Methods("POST").Handler(handlers.ContentTypeHandler(http.HandlerFunc(s.createUserHandlerFunc), "application/json")).Name("createUser")
 
+	router.Path("/{userID}/synonyms").Methods("PUT").Handler(handlers.ContentTypeHandler(http.HandlerFunc(s.saveUserSynonymsHandlerFunc), "application/json")).Name("saveUserSynonyms")
+
 	router.Path("/{userID}/verify/send").Methods("PUT").Handler(handlers.ContentTypeHandler(http.HandlerFunc(s.sendUserVerificationHandlerFunc), "application/json", "text/plain")).Name("sendUserVerification")
 	router.Path("/{userID}/verify/{nonce}").Methods("UPDATE", "GET").Handler(handlers.ContentTypeHandler(http.HandlerFunc(s.verifyUserHandlerFunc), "application/json", "text/plain")).Name("verifyUser")
 	router.Path("/{userID}/verify").Methods("GET").Handler(http.HandlerFunc(s.isVerifiedUserHandlerFunc)).Name("isVerifiedUser")
@@ -142,7 +170,8 @@ func (s *Server) registerUserRoutes(router *mux.Router) *mux.Router {
 }
 
 func (s *Server) registerWatchlistRoutes(router *mux.Router) *mux.Router {
-	router.Methods("GET").Handler(http.StripPrefix("/watchlist", http.FileServer(http.Dir(s.config.WatchlistDir))))
+	router.Path("/shared/{token}").Methods("GET").Handler(http.HandlerFunc(s.sharedWatchlistHandlerFunc)).Name("sharedWatchlist")
+	router.Methods("GET").Handler(http.StripPrefix("/watchlist", s.watchlistFileServer()))
 	return router
 }
 
@@ -157,10 +186,32 @@ func (s *Server) registerSearchRoutes(router *mux.Router) *mux.Router {
 			respondError(w, http.StatusBadRequest, "Missing query value")
 			return
 		}
-		for result := range ebidmodel.FilterAuctionItemChan(s.searchExtractor.Extract(s.searchExtractor.Search(stringiter.SliceStringIterator(q)))).Filter(ebidmodel.FilterFunc(filter.ByKeyword)) {
+		//An optional watch list whose options and filter pipeline are used for the results.
+		var options ebidmodel.WatchlistOptions
+		if watchlistID := query.Get("watchlist"); watchlistID != "" {
+			wl, err := s.store.LoadWatchlist(r.Context(), watchlistID)
+			if err != nil {
+				s.logger.Error(err)
+				respondError(w, http.StatusNotFound, "Unknown watch list")
+				return
+			}
+			options = wl.Options
+		}
+		queryTerms, err := search.NewSynonymQueryTerms(stringutils.FilterEmpty(q), s.synonyms.Merge(options.Synonyms))
+		if err != nil {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		pipeline, err := filter.BuildPipeline(options)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid watch list filters; %s", err))
+			return
+		}
+		for result := range pipeline.Chan(queryTerms.Tag(s.searchExtractor.Extract(s.searchExtractor.Search(stringiter.SliceStringIterator(queryTerms.Terms))))) {
 			s.logger.Info(result)
 			results = append(results, result)
 		}
+		sort.Sort(ebidmodel.ByScore(score.New(s.config.ScoreWeights, match.New(options.Match), q, time.Now()).Items(results)))
 		respondJSON(w, http.StatusOK, results)
 	})).Name("Quick-Search")
 	return router
@@ -224,11 +275,18 @@ func (s *Server) createUserWatchlistHandlerFunc(w http.ResponseWriter, r *http.R
 		respondError(w, http.StatusBadRequest, "User watchlist is required.")
 		return
 	}
+	if err := validateWatchlist(&wl); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	listID, err := s.addUserWatchlist(r.Context(), userID, &wl)
 	if os.IsNotExist(err) {
 		respondError(w, http.StatusNotFound, "Unknown User")
 		return
+	} else if _, subscribed := err.(*SubscribedWatchlistError); subscribed {
+		respondError(w, http.StatusForbidden, err.Error())
+		return
 	} else if err != nil {
 		respondError(w, http.StatusInternalServerError, "Failed to create watch list.")
 		return
@@ -241,6 +299,39 @@ func (s *Server) createUserWatchlistHandlerFunc(w http.ResponseWriter, r *http.R
 	}{WatchlistID: listID})
 }
 
+//saveUserSynonymsHandlerFunc replaces the user's synonym groups. They are used by the watch lists the user creates from then on.
+func (s *Server) saveUserSynonymsHandlerFunc(w http.ResponseWriter, r *http.Request) {
+	var groups [][]string
+
+	userID := mux.Vars(r)["userID"]
+
+	defer r.Body.Close()
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&groups); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := synonym.Validate(groups); err != nil {
+		respondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid synonyms; %s", err))
+		return
+	}
+
+	user, err := s.store.LoadUser(r.Context(), userID)
+	if err != nil {
+		s.logger.Error(err)
+		respondError(w, http.StatusNotFound, "User Not Found")
+		return
+	}
+	user.Synonyms = groups
+	if _, err = s.store.SaveUser(r.Context(), user); err != nil {
+		s.logger.Error(err)
+		respondError(w, http.StatusInternalServerError, "Failed to save synonyms")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, groups)
+}
+
 func (s *Server) deleteUserWatchlistHandlerFunc(w http.ResponseWriter, r *http.Request) {
 	var wl model.Watchlist
 
@@ -267,6 +358,159 @@ func (s *Server) deleteUserWatchlistHandlerFunc(w http.ResponseWriter, r *http.R
 	respondJSON(w, http.StatusNoContent, "Deleted")
 }
 
+//getUserWatchlistHandlerFunc the keywords and options of the user's watch list, by the user's name for it.
+func (s *Server) getUserWatchlistHandlerFunc(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+
+	user, err := s.store.LoadUser(r.Context(), params["userID"])
+	if err != nil {
+		s.logger.Error(err)
+		respondError(w, http.StatusNotFound, "User Not Found")
+		return
+	}
+	listID, ok := pickWatchlistID(w, r, user, params["name"])
+	if !ok {
+		return
+	}
+	list, err := s.store.LoadWatchlist(r.Context(), listID)
+	if err != nil {
+		s.logger.Error(err)
+		respondError(w, http.StatusInternalServerError, "Failed to load watch list")
+		return
+	}
+	list.Name, list.Schedule = params["name"], user.Schedules[params["name"]]
+	if list.Owner != user.ID {
+		list.Owner = ""
+	}
+
+	respondJSON(w, http.StatusOK, struct {
+		WatchlistID string `json:"watchlistID"`
+		Active      bool   `json:"active"`
+		*model.Watchlist
+	}{WatchlistID: listID, Active: list.Schedule.Active(time.Now()), Watchlist: list})
+}
+
+//patchUserWatchlistHandlerFunc adds, removes and renames. Changing the keywords changes the watch list's ID, the user's entry is pointed at the new watch list and the content of the old one is carried over.
+func (s *Server) patchUserWatchlistHandlerFunc(w http.ResponseWriter, r *http.Request) {
+	var patch model.WatchlistPatch
+
+	params := mux.Vars(r)
+	userID, name := params["userID"], params["name"]
+
+	defer r.Body.Close()
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&patch); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	for _, q := range patch.Add {
+		if err := query.Validate(q); err != nil {
+			respondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid query '%s'; %s", q, err))
+			return
+		}
+	}
+	if patch.Schedule != nil {
+		if err := patch.Schedule.Validate(); err != nil {
+			respondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid watch list schedule; %s", err))
+			return
+		}
+	}
+
+	user, err := s.store.LoadUser(r.Context(), userID)
+	if err != nil {
+		s.logger.Error(err)
+		respondError(w, http.StatusNotFound, "User Not Found")
+		return
+	}
+	listID, ok := pickWatchlistID(w, r, user, name)
+	if !ok {
+		return
+	}
+	var entry = user.Watchlists[name]
+	if _, taken := user.Watchlists[patch.Rename]; taken && patch.Rename != name {
+		respondError(w, http.StatusConflict, fmt.Sprintf("Watch list '%s' already exists", patch.Rename))
+		return
+	}
+
+	list, err := s.store.LoadWatchlist(r.Context(), listID)
+	if err != nil {
+		s.logger.Error(err)
+		respondError(w, http.StatusInternalServerError, "Failed to load watch list")
+		return
+	}
+
+	keywords, changed := patch.Apply(list.List)
+	if len(keywords) == 0 {
+		respondError(w, http.StatusBadRequest, "User watchlist is required.")
+		return
+	}
+	if _, subscribed := user.Subscriptions[name]; subscribed && changed {
+		respondError(w, http.StatusForbidden, (&SubscribedWatchlistError{}).Error())
+		return
+	}
+	var previousID = listID
+	if changed {
+		list.List, list.Owner, list.Created = keywords, user.ID, time.Now()
+		var newID string
+		if newID, err = s.store.SaveWatchlist(r.Context(), list); err != nil {
+			s.logger.Error(err)
+			respondError(w, http.StatusInternalServerError, "Failed to save watch list")
+			return
+		}
+		if err = s.store.CopyWatchlistContent(r.Context(), listID, newID); err != nil {
+			//The new watch list's content is rebuilt on the next scan, only its history is lost.
+			s.logger.Errorf("Failed to copy the content of watch list '%s' to '%s'; %s", listID, newID, err)
+		}
+		//The old watch list is left for the scanner's garbage collector, other users may reference it.
+		listID = newID
+	}
+	if patch.Rename != "" {
+		delete(user.Watchlists, name)
+		renameShare(user, name, patch.Rename)
+		setSchedule(user, patch.Rename, user.Schedules[name])
+		delete(user.Schedules, name)
+		name = patch.Rename
+	}
+	if patch.Schedule != nil {
+		setSchedule(user, name, *patch.Schedule)
+	}
+	user.Watchlists[name] = replaceWatchlistID(entry, previousID, listID)
+	if _, err = s.store.SaveUser(r.Context(), user); err != nil {
+		s.logger.Error(err)
+		respondError(w, http.StatusInternalServerError, "Failed to save watch list")
+		return
+	}
+
+	if changed {
+		s.followOwner(r.Context(), user, name, previousID)
+	}
+	s.syncWatchlistSchedules(r.Context(), previousID, listID)
+
+	s.logger.Infof("Edited watch list '%s' (%s)", name, listID)
+	list.Name, list.Schedule = name, user.Schedules[name]
+	if list.Owner != user.ID {
+		list.Owner = ""
+	}
+	w.Header().Set("Location", fmt.Sprintf("/user/%s/watchlist/%s", url.PathEscape(userID), url.PathEscape(listID)))
+	respondJSON(w, http.StatusOK, struct {
+		WatchlistID string `json:"watchlistID"`
+		Active      bool   `json:"active"`
+		*model.Watchlist
+	}{WatchlistID: listID, Active: list.Schedule.Active(time.Now()), Watchlist: list})
+}
+
+//replaceWatchlistID the user's entry with the watch list ID replaced, the other IDs of an entry with more than one are kept.
+func replaceWatchlistID(entry string, previousID string, listID string) string {
+	var listIDs = store.WatchlistIDs(entry)
+
+	for i, id := range listIDs {
+		if id == previousID {
+			listIDs[i] = listID
+		}
+	}
+	return strings.Join(listIDs, ",")
+}
+
 func (s *Server) sendUserVerificationHandlerFunc(w http.ResponseWriter, r *http.Request) {
 	var nonce uuid.UUID
 	var userID string
@@ -479,26 +723,147 @@ func (s *Server) createUserSpace(u *model.User) (string, error) {
 	return userDir, nil
 }
 
+//validateWatchlist the watch list's queries, options and filters.
+func validateWatchlist(wl *model.Watchlist) error {
+	for _, q := range wl.List {
+		if err := query.Validate(q); err != nil {
+			return fmt.Errorf("Invalid query '%s'; %s", q, err)
+		}
+	}
+	if err := wl.Options.Validate(); err != nil {
+		return fmt.Errorf("Invalid watch list options; %s", err)
+	}
+	if _, err := filter.BuildPipeline(wl.Options); err != nil {
+		return fmt.Errorf("Invalid watch list filters; %s", err)
+	}
+	if err := wl.Schedule.Validate(); err != nil {
+		return fmt.Errorf("Invalid watch list schedule; %s", err)
+	}
+	return nil
+}
+
 //addUserWatchlist add a watch list to a user's group of watch lists.
 func (s *Server) addUserWatchlist(ctx context.Context, userID string, list *model.Watchlist) (listID string, err error) {
 	user, err := s.store.LoadUser(ctx, userID)
 	if err != nil {
 		return "", err
 	}
+	if _, subscribed := user.Subscriptions[list.Name]; subscribed {
+		return "", &SubscribedWatchlistError{}
+	}
+
+	addUserSynonyms(user, list)
+	list.Owner, list.Created = user.ID, time.Now()
 
 	if listID, err = s.store.SaveWatchlist(ctx, list); err != nil {
 		return "", err
 	}
 
+	previousID := user.Watchlists[list.Name]
 	user.Watchlists[list.Name] = listID
+	setSchedule(user, list.Name, list.Schedule)
 	if _, err = s.store.SaveUser(ctx, user); err != nil {
 		//Don't care about the watch list that was saved, since watch lists can be shared among users. No reason to delete it.
 		return "", err
 	}
+	s.followOwner(ctx, user, list.Name, previousID)
+	s.syncWatchlistSchedules(ctx, previousID, listID)
 
 	return listID, nil
 }
 
+//addUserSynonyms copies the user's synonyms for the watch list's terms to its options. Only those are copied, so unrelated synonyms don't change its ID.
+func addUserSynonyms(user *model.User, list *model.Watchlist) {
+	var terms []string
+	for _, raw := range list.List {
+		if q, err := query.Parse(raw); err == nil {
+			terms = append(terms, q.Terms()...)
+		}
+	}
+	list.Options.Synonyms = append(list.Options.Synonyms, synonym.New(user.Synonyms).Groups(terms)...)
+}
+
+//setSchedule the user's schedule for the named watch list, a zero schedule is removed.
+func setSchedule(user *model.User, name string, schedule ebidmodel.WatchlistSchedule) {
+	if schedule.IsZero() {
+		delete(user.Schedules, name)
+		return
+	}
+	if user.Schedules == nil {
+		user.Schedules = make(map[string]ebidmodel.WatchlistSchedule)
+	}
+	user.Schedules[name] = schedule
+}
+
+//syncWatchlistSchedules saves the schedules of every user of each watch list with the watch list, where the scanner and updater read them. A watch list one of its users has not scheduled is always searched. A watch list whose users can not all be loaded keeps the schedules it has, they would be missing theirs.
+func (s *Server) syncWatchlistSchedules(ctx context.Context, listIDs ...string) {
+	var synced = make(map[string]struct{})
+	for _, listID := range listIDs {
+		if _, done := synced[listID]; done || listID == "" {
+			continue
+		}
+		synced[listID] = struct{}{}
+
+		schedules, err := s.watchlistSchedules(ctx, listID)
+		if err != nil {
+			s.logger.Errorf("Failed to find the schedules of watch list '%s', keeping the ones it has; %s", listID, err)
+			continue
+		}
+		if err := s.store.SaveWatchlistSchedules(ctx, listID, schedules); err != nil {
+			s.logger.Errorf("Failed to save the schedules of watch list '%s'; %s", listID, err)
+		}
+	}
+}
+
+//watchlistUsers the IDs of the users of the watch list, from the store's watch list index if it keeps one, otherwise every user is loaded to find them.
+func (s *Server) watchlistUsers(ctx context.Context, listID string) ([]string, error) {
+	if index, ok := s.store.(store.WatchlistUserIndexer); ok {
+		return index.WatchlistUsers(ctx, listID)
+	}
+	references, err := store.WatchlistReferences(ctx, s.store)
+	if err != nil {
+		return nil, err
+	}
+	return references[listID], nil
+}
+
+//watchlistSchedules the schedules the users gave the watch list, none if one of them did not schedule it. An error if one of its users can not be loaded.
+func (s *Server) watchlistSchedules(ctx context.Context, listID string) (ebidmodel.WatchlistSchedules, error) {
+	var schedules ebidmodel.WatchlistSchedules
+
+	userIDs, err := s.watchlistUsers(ctx, listID)
+	if err != nil {
+		return nil, err
+	}
+	var seen = make(map[string]struct{})
+	for _, userID := range userIDs {
+		if _, done := seen[userID]; done {
+			continue
+		}
+		seen[userID] = struct{}{}
+
+		user, err := s.store.LoadUser(ctx, userID)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("user '%s'; %w", userID, err)
+		}
+		for name, listIDs := range user.Watchlists {
+			for _, id := range strings.Split(listIDs, ",") {
+				if strings.TrimSpace(id) != listID {
+					continue
+				}
+				schedule, scheduled := user.Schedules[name]
+				if !scheduled {
+					return nil, nil
+				}
+				schedules = append(schedules, schedule)
+			}
+		}
+	}
+	return schedules, nil
+}
+
 //deleteUserWatchlist delete a watch list from a user's group of watch lists.
 func (s *Server) deleteUserWatchlist(ctx context.Context, userID string, list *model.Watchlist) error {
 	user, err := s.store.LoadUser(ctx, userID)
@@ -506,10 +871,15 @@ func (s *Server) deleteUserWatchlist(ctx context.Context, userID string, list *m
 		return err
 	}
 
+	listID := user.Watchlists[list.Name]
+	s.unshare(ctx, user, list.Name)
+	delete(user.Subscriptions, list.Name)
+	delete(user.Schedules, list.Name)
 	delete(user.Watchlists, list.Name)
 	if _, err = s.store.SaveUser(ctx, user); err != nil {
 		return err
 	}
+	s.syncWatchlistSchedules(ctx, listID)
 
 	return nil
 }
@@ -0,0 +1,126 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/scirelli/auction-ebidlocal-search/internal/app/server/model"
+	"github.com/scirelli/auction-ebidlocal-search/internal/app/server/store/memory"
+	ebidmodel "github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/model"
+	ebidmemory "github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/store/memory"
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/log"
+)
+
+//testStore a memory store whose users in unreadable can not be loaded, like a half written or unparsable user file. It counts how often every user is listed.
+type testStore struct {
+	*memory.Store
+	unreadable map[string]bool
+	listed     *int
+}
+
+func newTestStore() testStore {
+	return testStore{Store: memory.New(nil), unreadable: map[string]bool{}, listed: new(int)}
+}
+
+func (s testStore) ListUsers(ctx context.Context) ([]string, error) {
+	*s.listed++
+	return s.Store.ListUsers(ctx)
+}
+
+func (s testStore) LoadUser(ctx context.Context, userID string) (*model.User, error) {
+	if s.unreadable[userID] {
+		return nil, fmt.Errorf("user '%s' is unreadable", userID)
+	}
+	return s.Store.LoadUser(ctx, userID)
+}
+
+//newTestServer a server without routes or templates, its handlers are called directly.
+func newTestServer(t *testing.T, store testStore) *Server {
+	return &Server{
+		config: Config{},
+		logger: log.New("Test", log.DEFAULT_LOG_LEVEL),
+		store:  store,
+	}
+}
+
+func TestSyncWatchlistSchedules(t *testing.T) {
+	var ctx = context.Background()
+	var watchlists = ebidmemory.New(ebidmemory.Config{})
+	var store = newTestStore()
+	store.Store = memory.New(watchlists)
+	var s = newTestServer(t, store)
+
+	listID, err := store.SaveWatchlist(ctx, &model.Watchlist{List: []string{"kayak"}})
+	assert.NoError(t, err)
+	_, err = store.SaveUser(ctx, &model.User{ID: "1", Watchlists: map[string]string{"Boats": listID}, Schedules: map[string]ebidmodel.WatchlistSchedule{"Boats": {Paused: true}}})
+	assert.NoError(t, err)
+	_, err = store.SaveUser(ctx, &model.User{ID: "2", Watchlists: map[string]string{"Kayaks": listID}})
+	assert.NoError(t, err)
+
+	s.syncWatchlistSchedules(ctx, listID)
+	schedules, err := watchlists.LoadWatchlistSchedules(ctx, listID)
+	assert.NoError(t, err)
+	assert.Empty(t, schedules, "User 2 did not schedule the watch list, it is always searched")
+
+	store.unreadable["2"] = true
+	s.syncWatchlistSchedules(ctx, listID)
+	schedules, err = watchlists.LoadWatchlistSchedules(ctx, listID)
+	assert.NoError(t, err)
+	assert.Empty(t, schedules, "Without user 2 the watch list would be paused, the schedules it has are kept")
+
+	store.unreadable["2"] = false
+	_, err = store.SaveUser(ctx, &model.User{ID: "2", Watchlists: map[string]string{"Kayaks": listID}, Schedules: map[string]ebidmodel.WatchlistSchedule{"Kayaks": {Paused: true}}})
+	assert.NoError(t, err)
+	s.syncWatchlistSchedules(ctx, listID)
+	schedules, err = watchlists.LoadWatchlistSchedules(ctx, listID)
+	assert.NoError(t, err)
+	assert.Equal(t, ebidmodel.WatchlistSchedules{{Paused: true}, {Paused: true}}, schedules)
+}
+
+func TestUserWatchlistHandlersPickWatchlistID(t *testing.T) {
+	var ctx = context.Background()
+	var store = newTestStore()
+	var s = newTestServer(t, store)
+
+	kayaks, err := store.SaveWatchlist(ctx, &model.Watchlist{List: []string{"kayak"}})
+	assert.NoError(t, err)
+	canoes, err := store.SaveWatchlist(ctx, &model.Watchlist{List: []string{"canoe"}})
+	assert.NoError(t, err)
+	_, err = store.SaveUser(ctx, &model.User{ID: "u1", Watchlists: map[string]string{"Boats": kayaks + "," + canoes}})
+	assert.NoError(t, err)
+
+	var body struct {
+		WatchlistID string `json:"watchlistID"`
+	}
+	get := func(target string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		s.getUserWatchlistHandlerFunc(w, mux.SetURLVars(httptest.NewRequest(http.MethodGet, target, nil), map[string]string{"userID": "u1", "name": "Boats"}))
+		return w
+	}
+
+	w := get("/")
+	assert.Equal(t, http.StatusBadRequest, w.Code, "A watch list with more than one ID needs the one to use")
+	assert.Contains(t, w.Body.String(), canoes)
+
+	w = get("/?watchlistID=" + canoes)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, canoes, body.WatchlistID)
+
+	w = httptest.NewRecorder()
+	s.patchUserWatchlistHandlerFunc(w, mux.SetURLVars(httptest.NewRequest(http.MethodPatch, "/?watchlistID="+canoes, strings.NewReader(`{"add": ["raft"]}`)), map[string]string{"userID": "u1", "name": "Boats"}))
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.NotEqual(t, canoes, body.WatchlistID)
+	user, err := store.LoadUser(ctx, "u1")
+	assert.NoError(t, err)
+	assert.Equal(t, kayaks+","+body.WatchlistID, user.Watchlists["Boats"], "Only the picked ID is replaced")
+}
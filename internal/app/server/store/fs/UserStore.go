@@ -3,14 +3,23 @@ package fs
 import (
 	"context"
 	"encoding/json"
-	"io/ioutil"
+	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 
 	. "github.com/scirelli/auction-ebidlocal-search/internal/app/server/model"
+	"github.com/scirelli/auction-ebidlocal-search/internal/app/server/store"
+	fileutils "github.com/scirelli/auction-ebidlocal-search/internal/pkg/fileUtils"
 	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/log"
 )
 
+//WatchlistIndexFileName the index of the users of each watch list in the user directory, see store.WatchlistUserIndexer.
+const WatchlistIndexFileName = "watchlist-index.json"
+
+//ShareIndexFileName the index of the owner of each share token in the user directory, see store.ShareIndexer.
+const ShareIndexFileName = "share-index.json"
+
 //NewUserStore constructor for the UserStore
 func NewUserStore(baseUserDir string, dataFileName string, log log.Logger) *UserStore {
 	return &UserStore{
@@ -26,8 +35,10 @@ type UserStore struct {
 	logger       log.Logger
 }
 
+//SaveUser writes the user file then indexes the user. If the user can not be indexed the user file is put back the way it was, so the user and the index files never disagree.
 func (s *UserStore) SaveUser(ctx context.Context, u *User) (string, error) {
 	var userDir string = filepath.Join(s.baseUserDir, u.ID)
+	var userDataFile string = filepath.Join(userDir, s.dataFileName)
 
 	s.logger.Infof("Saving user '%s' at '%s'\n", u.ID, userDir)
 	if err := os.MkdirAll(userDir, 0775); err != nil {
@@ -40,7 +51,65 @@ func (s *UserStore) SaveUser(ctx context.Context, u *User) (string, error) {
 		s.logger.Error(err)
 		return "", err
 	}
-	return u.ID, ioutil.WriteFile(filepath.Join(userDir, s.dataFileName), file, 0644)
+	unlock, err := fileutils.Lock(userDir)
+	if err != nil {
+		s.logger.Error(err)
+		return "", err
+	}
+	defer unlock()
+	previous, err := os.ReadFile(userDataFile)
+	if err != nil && !os.IsNotExist(err) {
+		s.logger.Error(err)
+		return "", err
+	}
+	var existed = err == nil
+	if err := fileutils.WriteFile(userDataFile, file, 0644); err != nil {
+		s.logger.Error(err)
+		return "", err
+	}
+	if err := s.index(ctx, u); err != nil {
+		s.logger.Errorf("UserStore.SaveUser: Putting back user '%s', it could not be indexed; %s", u.ID, err)
+		s.restoreUser(userDataFile, previous, existed)
+		return "", err
+	}
+	return u.ID, nil
+}
+
+//index updates both index files with the user's watch lists and shares.
+func (s *UserStore) index(ctx context.Context, u *User) error {
+	if err := s.updateIndex(ctx, func(index map[string][]string) {
+		indexUser(index, u.ID, store.UserWatchlistIDs(u))
+	}); err != nil {
+		return err
+	}
+	return s.updateShareIndex(ctx, func(index map[string]string) {
+		indexShares(index, u.ID, u.Shares)
+	})
+}
+
+//restoreUser puts back the user file a failed save replaced, or removes it if there was none. The watch list index may already have the change, the index files are removed so they are built from the user files again the next time they are needed. The caller holds the lock of the user's directory.
+func (s *UserStore) restoreUser(userDataFile string, previous []byte, existed bool) {
+	var err error
+	if existed {
+		err = fileutils.WriteFile(userDataFile, previous, 0644)
+	} else {
+		err = os.Remove(userDataFile)
+	}
+	if err != nil {
+		s.logger.Errorf("UserStore.restoreUser: Was not able to put back '%s'; %s", userDataFile, err)
+	}
+
+	unlock, err := fileutils.Lock(s.baseUserDir)
+	if err != nil {
+		s.logger.Error(err)
+		return
+	}
+	defer unlock()
+	for _, name := range []string{WatchlistIndexFileName, ShareIndexFileName} {
+		if err := os.Remove(filepath.Join(s.baseUserDir, name)); err != nil && !os.IsNotExist(err) {
+			s.logger.Errorf("UserStore.restoreUser: Was not able to remove the index '%s'; %s", name, err)
+		}
+	}
 }
 
 func (s *UserStore) LoadUser(ctx context.Context, userID string) (*User, error) {
@@ -68,6 +137,238 @@ func (s *UserStore) LoadUser(ctx context.Context, userID string) (*User, error)
 	return &usr, nil
 }
 
+//DeleteUser removes the user's directory and takes the user out of the index files. A missing user is a not exist error.
 func (s *UserStore) DeleteUser(ctx context.Context, userID string) error {
-	return os.RemoveAll(filepath.Join(s.baseUserDir, userID))
+	var userDir string = filepath.Join(s.baseUserDir, userID)
+
+	if unlock, err := fileutils.Lock(userDir); err == nil {
+		defer unlock()
+	}
+	if _, err := os.Stat(filepath.Join(userDir, s.dataFileName)); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(userDir); err != nil {
+		return err
+	}
+	if err := s.updateIndex(ctx, func(index map[string][]string) {
+		indexUser(index, userID, nil)
+	}); err != nil {
+		return err
+	}
+	return s.updateShareIndex(ctx, func(index map[string]string) {
+		indexShares(index, userID, nil)
+	})
+}
+
+//Recover removes the temp files of writes that never finished from the user directory. Returns the user files that do not parse.
+func (s *UserStore) Recover(ctx context.Context) (corrupt []string, err error) {
+	removed, corrupt, err := fileutils.Recover(s.baseUserDir)
+	for _, path := range removed {
+		s.logger.Warnf("UserStore.Recover: Removed unfinished write '%s'", path)
+	}
+	return corrupt, err
+}
+
+//ListUsers the IDs of the directories that have a user in them.
+func (s *UserStore) ListUsers(ctx context.Context) ([]string, error) {
+	var ids []string
+
+	entries, err := os.ReadDir(s.baseUserDir)
+	if os.IsNotExist(err) {
+		return ids, nil
+	} else if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if _, err := os.Stat(filepath.Join(s.baseUserDir, entry.Name(), s.dataFileName)); entry.IsDir() && err == nil {
+			ids = append(ids, entry.Name())
+		}
+	}
+	return ids, nil
+}
+
+//WatchlistUsers the IDs of the users that reference the watch list, from the index file. The index is built the first time if there is none, such as in a user directory from before there was one.
+func (s *UserStore) WatchlistUsers(ctx context.Context, watchlistID string) ([]string, error) {
+	var index = make(map[string][]string)
+	err := s.readIndex(WatchlistIndexFileName, &index)
+	if os.IsNotExist(err) {
+		if err = s.updateIndex(ctx, func(map[string][]string) {}); err == nil {
+			err = s.readIndex(WatchlistIndexFileName, &index)
+		}
+	}
+	if err != nil {
+		s.logger.Error(err)
+		return nil, err
+	}
+	if ids, exists := index[watchlistID]; exists {
+		return ids, nil
+	}
+	return []string{}, nil
+}
+
+//ShareOwner the owner of the share token from the share index file, built the first time if there is none like the watch list index.
+func (s *UserStore) ShareOwner(ctx context.Context, token string) (string, error) {
+	var index = make(map[string]string)
+
+	err := s.readIndex(ShareIndexFileName, &index)
+	if os.IsNotExist(err) {
+		if err = s.updateShareIndex(ctx, func(map[string]string) {}); err == nil {
+			err = s.readIndex(ShareIndexFileName, &index)
+		}
+	}
+	if err != nil {
+		s.logger.Error(err)
+		return "", err
+	}
+	if userID, exists := index[token]; exists {
+		return userID, nil
+	}
+	return "", os.ErrNotExist
+}
+
+//RebuildWatchlistIndex replaces the index files with ones built from the user files. A user file that does not parse is an error and the index files are left as they were.
+func (s *UserStore) RebuildWatchlistIndex(ctx context.Context) (int, error) {
+	if err := os.MkdirAll(s.baseUserDir, 0775); err != nil {
+		return 0, err
+	}
+	unlock, err := fileutils.Lock(s.baseUserDir)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	index, err := store.WatchlistReferences(ctx, s)
+	if err != nil {
+		return 0, err
+	}
+	shares, err := s.buildShareIndex(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if err = s.writeIndex(ShareIndexFileName, shares); err != nil {
+		return 0, err
+	}
+	return len(index), s.writeIndex(WatchlistIndexFileName, index)
+}
+
+//updateIndex changes the index file while holding the lock of the user directory, so the server and scanner never lose each other's changes. With no index file yet it is built from the user files first, failing if one does not parse rather than leaving its user out.
+func (s *UserStore) updateIndex(ctx context.Context, update func(index map[string][]string)) error {
+	if err := os.MkdirAll(s.baseUserDir, 0775); err != nil {
+		return err
+	}
+	unlock, err := fileutils.Lock(s.baseUserDir)
+	if err != nil {
+		s.logger.Error(err)
+		return err
+	}
+	defer unlock()
+
+	var index = make(map[string][]string)
+	err = s.readIndex(WatchlistIndexFileName, &index)
+	if os.IsNotExist(err) {
+		s.logger.Infof("Building the watch list index of '%s'", s.baseUserDir)
+		index, err = store.WatchlistReferences(ctx, s)
+	}
+	if err != nil {
+		s.logger.Error(err)
+		return err
+	}
+	update(index)
+	return s.writeIndex(WatchlistIndexFileName, index)
+}
+
+//updateShareIndex changes the share index file like updateIndex does the watch list index file.
+func (s *UserStore) updateShareIndex(ctx context.Context, update func(index map[string]string)) error {
+	if err := os.MkdirAll(s.baseUserDir, 0775); err != nil {
+		return err
+	}
+	unlock, err := fileutils.Lock(s.baseUserDir)
+	if err != nil {
+		s.logger.Error(err)
+		return err
+	}
+	defer unlock()
+
+	var index = make(map[string]string)
+	err = s.readIndex(ShareIndexFileName, &index)
+	if os.IsNotExist(err) {
+		s.logger.Infof("Building the share index of '%s'", s.baseUserDir)
+		index, err = s.buildShareIndex(ctx)
+	}
+	if err != nil {
+		s.logger.Error(err)
+		return err
+	}
+	update(index)
+	return s.writeIndex(ShareIndexFileName, index)
+}
+
+//buildShareIndex the owner of each share token of the user files. A user that can not be loaded is an error, like store.WatchlistReferences.
+func (s *UserStore) buildShareIndex(ctx context.Context) (map[string]string, error) {
+	var index = make(map[string]string)
+
+	userIDs, err := s.ListUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, userID := range userIDs {
+		user, err := s.LoadUser(ctx, userID)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("user '%s'; %w", userID, err)
+		}
+		indexShares(index, user.ID, user.Shares)
+	}
+	return index, nil
+}
+
+func (s *UserStore) readIndex(name string, index interface{}) error {
+	b, err := os.ReadFile(filepath.Join(s.baseUserDir, name))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, index)
+}
+
+func (s *UserStore) writeIndex(name string, index interface{}) error {
+	b, err := json.Marshal(index)
+	if err != nil {
+		return err
+	}
+	return fileutils.WriteFile(filepath.Join(s.baseUserDir, name), b, 0644)
+}
+
+//indexUser replaces the watch lists the index has the user under with listIDs. The user IDs of a watch list are kept sorted.
+func indexUser(index map[string][]string, userID string, listIDs []string) {
+	for listID, userIDs := range index {
+		if i := sort.SearchStrings(userIDs, userID); i < len(userIDs) && userIDs[i] == userID {
+			userIDs = append(userIDs[:i], userIDs[i+1:]...)
+		}
+		if len(userIDs) == 0 {
+			delete(index, listID)
+		} else {
+			index[listID] = userIDs
+		}
+	}
+	for _, listID := range listIDs {
+		userIDs := index[listID]
+		i := sort.SearchStrings(userIDs, userID)
+		userIDs = append(userIDs, "")
+		copy(userIDs[i+1:], userIDs[i:])
+		userIDs[i] = userID
+		index[listID] = userIDs
+	}
+}
+
+//indexShares replaces the share tokens the index has the user own with the user's shares.
+func indexShares(index map[string]string, userID string, shares map[string]string) {
+	for token, ownerID := range index {
+		if ownerID == userID {
+			delete(index, token)
+		}
+	}
+	for token := range shares {
+		index[token] = userID
+	}
 }
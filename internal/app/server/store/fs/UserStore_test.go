@@ -0,0 +1,115 @@
+package fs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/scirelli/auction-ebidlocal-search/internal/app/server/model"
+	"github.com/scirelli/auction-ebidlocal-search/internal/app/server/store"
+	"github.com/scirelli/auction-ebidlocal-search/internal/app/server/store/storetest"
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/log"
+)
+
+func newTestUserStore(t *testing.T) *UserStore {
+	return NewUserStore(t.TempDir(), "data.json", log.New("Test", log.DEFAULT_LOG_LEVEL))
+}
+
+func TestUserStoreConformance(t *testing.T) {
+	storetest.UserStorer(t, func(t *testing.T) store.UserStorer { return newTestUserStore(t) })
+	storetest.WatchlistUserIndexer(t, func(t *testing.T) store.UserStorer { return newTestUserStore(t) })
+	storetest.ShareIndexer(t, func(t *testing.T) store.UserStorer { return newTestUserStore(t) })
+}
+
+func TestUserStoreBuildsMissingIndex(t *testing.T) {
+	var ctx = context.Background()
+	var users = newTestUserStore(t)
+	var indexFile = filepath.Join(users.baseUserDir, WatchlistIndexFileName)
+
+	_, err := users.SaveUser(ctx, &model.User{ID: "u1", Watchlists: map[string]string{"tools": "a"}})
+	assert.NoError(t, err)
+	assert.FileExists(t, indexFile)
+
+	//A user directory from before there was an index.
+	assert.NoError(t, os.Remove(indexFile))
+	ids, err := users.WatchlistUsers(ctx, "a")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"u1"}, ids)
+	assert.FileExists(t, indexFile)
+
+	assert.NoError(t, os.Remove(indexFile))
+	_, err = users.SaveUser(ctx, &model.User{ID: "u2", Watchlists: map[string]string{"tools": "a"}})
+	assert.NoError(t, err)
+	ids, err = users.WatchlistUsers(ctx, "a")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"u1", "u2"}, ids, "Saving a user builds the index of the users saved before")
+
+	ids, err = users.ListUsers(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"u1", "u2"}, ids, "The index is not a user")
+}
+
+func TestUserStoreRebuildCorruptUser(t *testing.T) {
+	var ctx = context.Background()
+	var users = newTestUserStore(t)
+	var indexFile = filepath.Join(users.baseUserDir, WatchlistIndexFileName)
+
+	_, err := users.SaveUser(ctx, &model.User{ID: "u1", Watchlists: map[string]string{"tools": "a"}})
+	assert.NoError(t, err)
+	index, err := os.ReadFile(indexFile)
+	assert.NoError(t, err)
+
+	assert.NoError(t, os.MkdirAll(filepath.Join(users.baseUserDir, "u2"), 0775))
+	assert.NoError(t, os.WriteFile(filepath.Join(users.baseUserDir, "u2", "data.json"), []byte("{"), 0644))
+	_, err = users.RebuildWatchlistIndex(ctx)
+	if assert.Error(t, err, "The index is not rebuilt without a user that can not be loaded") {
+		assert.Contains(t, err.Error(), "user 'u2'")
+	}
+	actual, err := os.ReadFile(indexFile)
+	assert.NoError(t, err)
+	assert.Equal(t, index, actual, "The index is left as it was")
+
+	assert.NoError(t, os.Remove(indexFile))
+	_, err = users.WatchlistUsers(ctx, "a")
+	if assert.Error(t, err, "A missing index is not built without a user that can not be loaded") {
+		assert.Contains(t, err.Error(), "user 'u2'")
+	}
+}
+
+func TestUserStoreSaveUserIndexFails(t *testing.T) {
+	var ctx = context.Background()
+	var users = newTestUserStore(t)
+	var shareIndexFile = filepath.Join(users.baseUserDir, ShareIndexFileName)
+
+	_, err := users.SaveUser(ctx, &model.User{ID: "u1", Watchlists: map[string]string{"tools": "a"}})
+	assert.NoError(t, err)
+
+	//The watch list index is updated, the share index can not be.
+	assert.NoError(t, os.Remove(shareIndexFile))
+	assert.NoError(t, os.Mkdir(shareIndexFile, 0775))
+	_, err = users.SaveUser(ctx, &model.User{ID: "u1", Watchlists: map[string]string{"tools": "b"}})
+	assert.Error(t, err)
+	user, err := users.LoadUser(ctx, "u1")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"tools": "a"}, user.Watchlists, "The user is put back")
+	ids, err := users.WatchlistUsers(ctx, "b")
+	assert.NoError(t, err)
+	assert.Empty(t, ids, "The index does not have the change")
+	ids, err = users.WatchlistUsers(ctx, "a")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"u1"}, ids)
+
+	_, err = os.Stat(shareIndexFile)
+	assert.True(t, os.IsNotExist(err), "The indexes are built again when needed")
+	assert.NoError(t, os.Mkdir(shareIndexFile, 0775))
+	_, err = users.SaveUser(ctx, &model.User{ID: "u2", Watchlists: map[string]string{"tools": "b"}})
+	assert.Error(t, err)
+	_, err = users.LoadUser(ctx, "u2")
+	assert.True(t, os.IsNotExist(err), "A new user is removed")
+	ids, err = users.ListUsers(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"u1"}, ids)
+}
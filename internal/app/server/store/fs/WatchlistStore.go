@@ -2,7 +2,8 @@ package fs
 
 import (
 	"context"
-	"errors"
+	"fmt"
+	"os"
 
 	"github.com/scirelli/auction-ebidlocal-search/internal/app/server/model"
 	wlmodel "github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/model"
@@ -25,16 +26,79 @@ type EbidlocalAsWatchlistStore struct {
 }
 
 func (wl *EbidlocalAsWatchlistStore) SaveWatchlist(ctx context.Context, list *model.Watchlist) (ID string, err error) {
-	if ID, err = wl.store.SaveWatchlist(ctx, wlmodel.Watchlist(list.List)); err != nil {
+	var definition = wlmodel.WatchlistDefinition{
+		Keywords: wlmodel.Watchlist(list.List),
+		Metadata: wlmodel.WatchlistMetadata{
+			Name:        list.Name,
+			Description: list.Description,
+			Owner:       list.Owner,
+			Created:     list.Created,
+		},
+		Options: list.Options,
+	}
+	if ID, err = wl.store.SaveWatchlist(ctx, definition); err != nil {
 		return "", err
 	}
 	return ID, nil
 }
 
+//LoadWatchlist the watch list's keywords, metadata and options. The name is the one the watch list was created with, a user's name for it is in the user's Watchlists.
 func (wl *EbidlocalAsWatchlistStore) LoadWatchlist(ctx context.Context, watchlistID string) (*model.Watchlist, error) {
-	return nil, errors.New("Not implemented")
+	definition, err := wl.store.LoadWatchlist(ctx, watchlistID)
+	if err != nil {
+		return nil, err
+	}
+	return &model.Watchlist{
+		List:        definition.Keywords,
+		Name:        definition.Metadata.Name,
+		Description: definition.Metadata.Description,
+		Options:     definition.Options,
+		Owner:       definition.Metadata.Owner,
+		Created:     definition.Metadata.Created,
+	}, nil
 }
 
+//DeleteWatchlist deletes the watch list for every user, remove it from the users that reference it first, see store.WatchlistReferences.
 func (wl *EbidlocalAsWatchlistStore) DeleteWatchlist(ctx context.Context, watchlistID string) error {
-	return errors.New("Not implemented")
+	return wl.store.DeleteWatchlist(ctx, watchlistID)
+}
+
+//CopyWatchlistContent an error if the Ebidlocal store can not copy content.
+func (wl *EbidlocalAsWatchlistStore) CopyWatchlistContent(ctx context.Context, fromWatchlistID string, toWatchlistID string) error {
+	if copier, ok := wl.store.(ebidstore.WatchlistContentCopier); ok {
+		return copier.CopyWatchlistContent(ctx, fromWatchlistID, toWatchlistID)
+	}
+	return fmt.Errorf("the Ebidlocal store can not copy content")
+}
+
+//SaveWatchlistSchedules an error if the Ebidlocal store can not schedule.
+func (wl *EbidlocalAsWatchlistStore) SaveWatchlistSchedules(ctx context.Context, watchlistID string, schedules wlmodel.WatchlistSchedules) error {
+	if scheduler, ok := wl.store.(ebidstore.WatchlistScheduler); ok {
+		return scheduler.SaveWatchlistSchedules(ctx, watchlistID, schedules)
+	}
+	return fmt.Errorf("the Ebidlocal store can not schedule")
+}
+
+//ListWatchlistSnapshots no snapshots if the Ebidlocal store does not keep history.
+func (wl *EbidlocalAsWatchlistStore) ListWatchlistSnapshots(ctx context.Context, watchlistID string) ([]wlmodel.WatchlistSnapshot, error) {
+	if history, ok := wl.store.(ebidstore.WatchlistHistoryStorer); ok {
+		return history.ListWatchlistSnapshots(ctx, watchlistID)
+	}
+	return []wlmodel.WatchlistSnapshot{}, nil
+}
+
+//LoadWatchlistSnapshot os.ErrNotExist if the Ebidlocal store does not keep history.
+func (wl *EbidlocalAsWatchlistStore) LoadWatchlistSnapshot(ctx context.Context, watchlistID string, snapshotID string) (*wlmodel.WatchlistContent, error) {
+	if history, ok := wl.store.(ebidstore.WatchlistHistoryStorer); ok {
+		return history.LoadWatchlistSnapshot(ctx, watchlistID, snapshotID)
+	}
+	return nil, os.ErrNotExist
+}
+
+func (wl *EbidlocalAsWatchlistStore) LoadWatchlistDefinition(ctx context.Context, watchlistID string) (wlmodel.WatchlistDefinition, error) {
+	return wl.store.LoadWatchlist(ctx, watchlistID)
+}
+
+func (wl *EbidlocalAsWatchlistStore) LoadWatchlistContent(ctx context.Context, watchlistID string) (*wlmodel.WatchlistContent, error) {
+	return wl.store.LoadWatchlistContent(ctx, watchlistID)
 }
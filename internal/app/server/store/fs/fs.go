@@ -1,8 +1,44 @@
 package fs
 
-import "github.com/scirelli/auction-ebidlocal-search/internal/app/server/store"
+import (
+	"context"
+	"fmt"
+
+	"github.com/scirelli/auction-ebidlocal-search/internal/app/server/store"
+)
 
 type FSStore struct {
 	store.UserStorer
 	store.WatchlistStorer
 }
+
+//WatchlistUsers from the user store's index, every user is loaded to find them if the user store keeps no index.
+func (fs FSStore) WatchlistUsers(ctx context.Context, watchlistID string) ([]string, error) {
+	if index, ok := fs.UserStorer.(store.WatchlistUserIndexer); ok {
+		return index.WatchlistUsers(ctx, watchlistID)
+	}
+	references, err := store.WatchlistReferences(ctx, fs.UserStorer)
+	if err != nil {
+		return nil, err
+	}
+	if userIDs, exists := references[watchlistID]; exists {
+		return userIDs, nil
+	}
+	return []string{}, nil
+}
+
+//ShareOwner from the user store's index, every user is loaded to find them if the user store keeps no index.
+func (fs FSStore) ShareOwner(ctx context.Context, token string) (string, error) {
+	if index, ok := fs.UserStorer.(store.ShareIndexer); ok {
+		return index.ShareOwner(ctx, token)
+	}
+	return store.ShareOwner(ctx, fs.UserStorer, token)
+}
+
+//RebuildWatchlistIndex an error if the user store keeps no index.
+func (fs FSStore) RebuildWatchlistIndex(ctx context.Context) (int, error) {
+	if index, ok := fs.UserStorer.(store.WatchlistUserIndexer); ok {
+		return index.RebuildWatchlistIndex(ctx)
+	}
+	return 0, fmt.Errorf("the user store keeps no watch list index")
+}
@@ -0,0 +1,175 @@
+//Package memory a store of users, and of watch lists through an ebidlocal store, in memory, for tests. Safe for concurrent use.
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/scirelli/auction-ebidlocal-search/internal/app/server/model"
+	"github.com/scirelli/auction-ebidlocal-search/internal/app/server/store"
+	"github.com/scirelli/auction-ebidlocal-search/internal/app/server/store/fs"
+	ebidstore "github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/store"
+	ebidmemory "github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/store/memory"
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/log"
+)
+
+//New the server store, its watch lists are kept by watchlists, an ebidlocal memory store if it is nil.
+func New(watchlists ebidstore.Storer) *Store {
+	if watchlists == nil {
+		watchlists = ebidmemory.New(ebidmemory.Config{})
+	}
+	return &Store{
+		UserStore:                 NewUserStore(),
+		EbidlocalAsWatchlistStore: fs.NewWatchlistStore(watchlists, log.New("MemoryStore", log.DEFAULT_LOG_LEVEL)),
+	}
+}
+
+//Store both the user and watch list store.
+type Store struct {
+	*UserStore
+	*fs.EbidlocalAsWatchlistStore
+}
+
+//NewUserStore an empty user store.
+func NewUserStore() *UserStore {
+	return &UserStore{
+		users:      make(map[string][]byte),
+		watchlists: make(map[string]map[string]struct{}),
+		shares:     make(map[string]string),
+	}
+}
+
+//UserStore keeps each user as JSON, so what a caller does to a user after saving or loading it does not change the stored one.
+type UserStore struct {
+	mu    sync.RWMutex
+	users map[string][]byte
+	//watchlists the index of watch list IDs to the IDs of the users that reference them.
+	watchlists map[string]map[string]struct{}
+	//shares the index of share tokens to the IDs of the users that share with them.
+	shares map[string]string
+}
+
+func (s *UserStore) SaveUser(ctx context.Context, u *model.User) (string, error) {
+	b, err := json.Marshal(u)
+	if err != nil {
+		return "", err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.users[u.ID] = b
+	s.unindex(u.ID)
+	s.index(u)
+	return u.ID, nil
+}
+
+//LoadUser os.ErrNotExist if there is no such user, like the fs store.
+func (s *UserStore) LoadUser(ctx context.Context, userID string) (*model.User, error) {
+	var usr model.User
+
+	s.mu.RLock()
+	b, exists := s.users[userID]
+	s.mu.RUnlock()
+	if !exists {
+		return nil, os.ErrNotExist
+	}
+	if err := json.Unmarshal(b, &usr); err != nil {
+		return nil, err
+	}
+	return &usr, nil
+}
+
+func (s *UserStore) DeleteUser(ctx context.Context, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.users[userID]; !exists {
+		return os.ErrNotExist
+	}
+	delete(s.users, userID)
+	s.unindex(userID)
+	return nil
+}
+
+func (s *UserStore) ListUsers(ctx context.Context) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var ids = make([]string, 0, len(s.users))
+	for id := range s.users {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+//WatchlistUsers the IDs of the users that reference the watch list sorted, from the index.
+func (s *UserStore) WatchlistUsers(ctx context.Context, watchlistID string) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var ids = make([]string, 0, len(s.watchlists[watchlistID]))
+	for id := range s.watchlists[watchlistID] {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+//ShareOwner the ID of the user that shares with the token, from the index.
+func (s *UserStore) ShareOwner(ctx context.Context, token string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if userID, exists := s.shares[token]; exists {
+		return userID, nil
+	}
+	return "", os.ErrNotExist
+}
+
+//RebuildWatchlistIndex the indexes are kept with the users so they never need repairing, rebuilt all the same. A user that does not parse is an error and the indexes are left as they were, like the fs store.
+func (s *UserStore) RebuildWatchlistIndex(ctx context.Context) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var users = make([]model.User, 0, len(s.users))
+	for id, b := range s.users {
+		var usr model.User
+		if err := json.Unmarshal(b, &usr); err != nil {
+			return 0, fmt.Errorf("user '%s'; %w", id, err)
+		}
+		users = append(users, usr)
+	}
+	s.watchlists = make(map[string]map[string]struct{})
+	s.shares = make(map[string]string)
+	for i := range users {
+		s.index(&users[i])
+	}
+	return len(s.watchlists), nil
+}
+
+//index adds the user to the index of each of their watch lists and shares. The caller holds the lock.
+func (s *UserStore) index(u *model.User) {
+	for token := range u.Shares {
+		s.shares[token] = u.ID
+	}
+	for _, listID := range store.UserWatchlistIDs(u) {
+		if s.watchlists[listID] == nil {
+			s.watchlists[listID] = make(map[string]struct{})
+		}
+		s.watchlists[listID][u.ID] = struct{}{}
+	}
+}
+
+//unindex removes the user from the indexes. The caller holds the lock.
+func (s *UserStore) unindex(userID string) {
+	for token, ownerID := range s.shares {
+		if ownerID == userID {
+			delete(s.shares, token)
+		}
+	}
+	for listID, users := range s.watchlists {
+		delete(users, userID)
+		if len(users) == 0 {
+			delete(s.watchlists, listID)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package memory
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/scirelli/auction-ebidlocal-search/internal/app/server/model"
+	"github.com/scirelli/auction-ebidlocal-search/internal/app/server/store"
+	"github.com/scirelli/auction-ebidlocal-search/internal/app/server/store/storetest"
+)
+
+func TestUserStoreConformance(t *testing.T) {
+	storetest.UserStorer(t, func(t *testing.T) store.UserStorer { return NewUserStore() })
+	storetest.WatchlistUserIndexer(t, func(t *testing.T) store.UserStorer { return NewUserStore() })
+	storetest.ShareIndexer(t, func(t *testing.T) store.UserStorer { return NewUserStore() })
+}
+
+func TestUserStoreRebuildCorruptUser(t *testing.T) {
+	var ctx = context.Background()
+	var users = NewUserStore()
+
+	_, err := users.SaveUser(ctx, &model.User{ID: "u1", Watchlists: map[string]string{"tools": "a"}})
+	assert.NoError(t, err)
+	users.users["u2"] = []byte("{")
+
+	_, err = users.RebuildWatchlistIndex(ctx)
+	if assert.Error(t, err, "The index is not rebuilt without a user that can not be loaded") {
+		assert.Contains(t, err.Error(), "user 'u2'")
+	}
+	ids, err := users.WatchlistUsers(ctx, "a")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"u1"}, ids, "The index is left as it was")
+}
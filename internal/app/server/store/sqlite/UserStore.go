@@ -0,0 +1,229 @@
+//Package sqlite a store of users in a SQLite database, see package sqldb. The watch lists of the server store are in the ebidlocal sqlite store, see fs.NewWatchlistStore to adapt it.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/scirelli/auction-ebidlocal-search/internal/app/server/model"
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/log"
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/sqldb"
+)
+
+//Schema the name the store's migrations are recorded under.
+const Schema = "users"
+
+//migrations the store's schema, only ever append to it, see sqldb.Migrate. user_watchlists has a row for each watch list ID of each of a user's watch lists, so the users of a watch list are found without loading every user. user_shares has a row for each share token of each user, so a share is found without loading every user; the shares users already have are copied in.
+var migrations = []string{
+	`CREATE TABLE users (
+		id TEXT PRIMARY KEY,
+		data TEXT NOT NULL
+	);
+	CREATE TABLE user_watchlists (
+		user_id TEXT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+		name TEXT NOT NULL,
+		watchlist_id TEXT NOT NULL,
+		PRIMARY KEY (user_id, name, watchlist_id)
+	);
+	CREATE INDEX user_watchlists_watchlist_id ON user_watchlists (watchlist_id);`,
+	`CREATE TABLE user_shares (
+		token TEXT PRIMARY KEY,
+		user_id TEXT NOT NULL REFERENCES users (id) ON DELETE CASCADE
+	);
+	INSERT OR REPLACE INTO user_shares (token, user_id)
+		SELECT shares.key, users.id FROM users, json_each(users.data, '$.shares') AS shares;`,
+}
+
+//NewUserStore constructor for the UserStore
+func NewUserStore(db *sql.DB, logger log.Logger) *UserStore {
+	if logger == nil {
+		logger = log.New("SQLiteUserStore", log.DEFAULT_LOG_LEVEL)
+	}
+	return &UserStore{
+		db:     db,
+		logger: logger,
+	}
+}
+
+type UserStore struct {
+	db     *sql.DB
+	logger log.Logger
+}
+
+//Migrate creates or updates the store's tables, safe to run every time the app starts. Returns the number of migrations applied.
+func (s *UserStore) Migrate(ctx context.Context) (int, error) {
+	return sqldb.Migrate(ctx, s.db, Schema, migrations)
+}
+
+//SaveUser replaces the user and the rows of their watch lists and shares in one transaction.
+func (s *UserStore) SaveUser(ctx context.Context, u *model.User) (string, error) {
+	b, err := json.Marshal(u)
+	if err != nil {
+		s.logger.Error(err)
+		return "", err
+	}
+
+	s.logger.Infof("Saving user '%s'\n", u.ID)
+	err = sqldb.InTx(ctx, s.db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, "INSERT INTO users (id, data) VALUES (?, ?) ON CONFLICT (id) DO UPDATE SET data = excluded.data", u.ID, string(b)); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, "DELETE FROM user_watchlists WHERE user_id = ?", u.ID); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, "DELETE FROM user_shares WHERE user_id = ?", u.ID); err != nil {
+			return err
+		}
+		return indexUser(ctx, tx, u)
+	})
+	if err != nil {
+		s.logger.Error(err)
+		return "", err
+	}
+	return u.ID, nil
+}
+
+//LoadUser os.ErrNotExist if there is no such user, like the fs store.
+func (s *UserStore) LoadUser(ctx context.Context, userID string) (*model.User, error) {
+	var usr model.User
+	var data string
+
+	err := s.db.QueryRowContext(ctx, "SELECT data FROM users WHERE id = ?", userID).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		s.logger.Errorf("User does not exist '%s'", userID)
+		return nil, os.ErrNotExist
+	} else if err != nil {
+		s.logger.Error(err)
+		return nil, err
+	}
+	if err = json.Unmarshal([]byte(data), &usr); err != nil {
+		s.logger.Error(err)
+		return nil, err
+	}
+	return &usr, nil
+}
+
+//DeleteUser the rows of the user's watch lists and shares are deleted with them. A missing user is a not exist error.
+func (s *UserStore) DeleteUser(ctx context.Context, userID string) error {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM users WHERE id = ?", userID)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return os.ErrNotExist
+	}
+	return nil
+}
+
+func (s *UserStore) ListUsers(ctx context.Context) ([]string, error) {
+	return s.queryIDs(ctx, "SELECT id FROM users ORDER BY id")
+}
+
+//WatchlistUsers the IDs of the users that reference the watch list, looked up by the watch list index.
+func (s *UserStore) WatchlistUsers(ctx context.Context, watchlistID string) ([]string, error) {
+	return s.queryIDs(ctx, "SELECT DISTINCT user_id FROM user_watchlists WHERE watchlist_id = ? ORDER BY user_id", watchlistID)
+}
+
+//ShareOwner the ID of the user that shares with the token, looked up by the share index.
+func (s *UserStore) ShareOwner(ctx context.Context, token string) (string, error) {
+	var userID string
+
+	err := s.db.QueryRowContext(ctx, "SELECT user_id FROM user_shares WHERE token = ?", token).Scan(&userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", os.ErrNotExist
+	}
+	return userID, err
+}
+
+//RebuildWatchlistIndex replaces the rows of every user's watch lists and shares with ones made from the users, in one transaction. A user that does not parse is an error and the rows are left as they were.
+func (s *UserStore) RebuildWatchlistIndex(ctx context.Context) (int, error) {
+	var indexed int
+
+	err := sqldb.InTx(ctx, s.db, func(tx *sql.Tx) error {
+		var users []*model.User
+
+		rows, err := tx.QueryContext(ctx, "SELECT id, data FROM users")
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			var id, data string
+			var usr model.User
+			if err := rows.Scan(&id, &data); err != nil {
+				rows.Close()
+				return err
+			}
+			if err := json.Unmarshal([]byte(data), &usr); err != nil {
+				rows.Close()
+				return fmt.Errorf("user '%s'; %w", id, err)
+			}
+			users = append(users, &usr)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for _, table := range []string{"user_watchlists", "user_shares"} {
+			if _, err := tx.ExecContext(ctx, "DELETE FROM "+table); err != nil {
+				return err
+			}
+		}
+		for _, usr := range users {
+			if err := indexUser(ctx, tx, usr); err != nil {
+				return err
+			}
+		}
+		return tx.QueryRowContext(ctx, "SELECT COUNT(DISTINCT watchlist_id) FROM user_watchlists").Scan(&indexed)
+	})
+	if err != nil {
+		s.logger.Error(err)
+		return 0, err
+	}
+	return indexed, nil
+}
+
+func (s *UserStore) queryIDs(ctx context.Context, query string, args ...interface{}) ([]string, error) {
+	var ids = []string{}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+//indexUser inserts a row for each watch list ID of each of the user's watch lists, and for each of their shares.
+func indexUser(ctx context.Context, tx *sql.Tx, u *model.User) error {
+	for token := range u.Shares {
+		if _, err := tx.ExecContext(ctx, "INSERT OR REPLACE INTO user_shares (token, user_id) VALUES (?, ?)", token, u.ID); err != nil {
+			return err
+		}
+	}
+	for name, listIDs := range u.Watchlists {
+		for _, listID := range strings.Split(listIDs, ",") {
+			if listID = strings.TrimSpace(listID); listID == "" {
+				continue
+			}
+			if _, err := tx.ExecContext(ctx, "INSERT OR IGNORE INTO user_watchlists (user_id, name, watchlist_id) VALUES (?, ?, ?)", u.ID, name, listID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
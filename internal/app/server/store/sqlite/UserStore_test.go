@@ -0,0 +1,120 @@
+package sqlite
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/scirelli/auction-ebidlocal-search/internal/app/server/model"
+	"github.com/scirelli/auction-ebidlocal-search/internal/app/server/store"
+	"github.com/scirelli/auction-ebidlocal-search/internal/app/server/store/storetest"
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/sqldb"
+)
+
+func TestUserStore(t *testing.T) {
+	var ctx = context.Background()
+
+	db, err := sqldb.Open(*sqldb.Defaults(&sqldb.Config{}, t.TempDir()))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer db.Close()
+	store := NewUserStore(db, nil)
+	_, err = store.Migrate(ctx)
+	assert.NoError(t, err)
+
+	_, err = store.LoadUser(ctx, "nobody")
+	assert.True(t, os.IsNotExist(err))
+
+	var user = model.User{ID: "u1", Name: "one", Email: "one@example.com", Watchlists: map[string]string{"tools": "a,b", "toys": "c"}}
+	_, err = store.SaveUser(ctx, &user)
+	assert.NoError(t, err)
+	_, err = store.SaveUser(ctx, &model.User{ID: "u2", Watchlists: map[string]string{"tools": "a"}})
+	assert.NoError(t, err)
+
+	loaded, err := store.LoadUser(ctx, "u1")
+	assert.NoError(t, err)
+	assert.Equal(t, user, *loaded)
+
+	ids, err := store.ListUsers(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"u1", "u2"}, ids)
+
+	users, err := store.WatchlistUsers(ctx, "a")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"u1", "u2"}, users)
+
+	//Saving replaces the user's watch lists.
+	user.Watchlists = map[string]string{"toys": "c"}
+	_, err = store.SaveUser(ctx, &user)
+	assert.NoError(t, err)
+	users, _ = store.WatchlistUsers(ctx, "a")
+	assert.Equal(t, []string{"u2"}, users)
+
+	assert.NoError(t, store.DeleteUser(ctx, "u1"))
+	users, _ = store.WatchlistUsers(ctx, "c")
+	assert.Empty(t, users, "A deleted user's watch lists are deleted with them")
+	_, err = store.LoadUser(ctx, "u1")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func newTestUserStore(t *testing.T) store.UserStorer {
+	db, err := sqldb.Open(*sqldb.Defaults(&sqldb.Config{}, t.TempDir()))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	t.Cleanup(func() { db.Close() })
+	users := NewUserStore(db, nil)
+	_, err = users.Migrate(context.Background())
+	assert.NoError(t, err)
+	return users
+}
+
+func TestUserStoreConformance(t *testing.T) {
+	storetest.UserStorer(t, newTestUserStore)
+	storetest.WatchlistUserIndexer(t, newTestUserStore)
+	storetest.ShareIndexer(t, newTestUserStore)
+}
+
+func TestUserStoreMigratesShares(t *testing.T) {
+	var ctx = context.Background()
+
+	db, err := sqldb.Open(*sqldb.Defaults(&sqldb.Config{}, t.TempDir()))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer db.Close()
+	//A database from before there was a share index.
+	_, err = sqldb.Migrate(ctx, db, Schema, migrations[:1])
+	assert.NoError(t, err)
+	_, err = db.ExecContext(ctx, "INSERT INTO users (id, data) VALUES (?, ?)", "u1", `{"id": "u1", "watchlists": {"tools": "a"}, "shares": {"t1": "tools"}}`)
+	assert.NoError(t, err)
+
+	store := NewUserStore(db, nil)
+	applied, err := store.Migrate(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, applied)
+	owner, err := store.ShareOwner(ctx, "t1")
+	assert.NoError(t, err)
+	assert.Equal(t, "u1", owner, "The shares users had are indexed")
+}
+
+func TestUserStoreRebuildCorruptUser(t *testing.T) {
+	var ctx = context.Background()
+	var users = newTestUserStore(t).(*UserStore)
+
+	_, err := users.SaveUser(ctx, &model.User{ID: "u1", Watchlists: map[string]string{"tools": "a"}})
+	assert.NoError(t, err)
+	_, err = users.db.ExecContext(ctx, "INSERT INTO users (id, data) VALUES (?, ?)", "u2", "{")
+	assert.NoError(t, err)
+
+	_, err = users.RebuildWatchlistIndex(ctx)
+	if assert.Error(t, err, "The index is not rebuilt without a user that can not be loaded") {
+		assert.Contains(t, err.Error(), "user 'u2'")
+	}
+	ids, err := users.WatchlistUsers(ctx, "a")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"u1"}, ids, "The index is left as it was")
+}
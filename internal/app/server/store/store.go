@@ -2,8 +2,14 @@ package store
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
 
 	"github.com/scirelli/auction-ebidlocal-search/internal/app/server/model"
+	ebidmodel "github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/model"
 )
 
 //Storer interface to represent a data store.
@@ -16,12 +22,113 @@ type Storer interface {
 type UserStorer interface {
 	SaveUser(ctx context.Context, u *model.User) (string, error)
 	LoadUser(ctx context.Context, userID string) (*model.User, error)
+	//DeleteUser os.ErrNotExist if there is no such user, like DeleteWatchlist.
 	DeleteUser(ctx context.Context, userID string) error
+	//ListUsers the IDs of every stored user.
+	ListUsers(ctx context.Context) ([]string, error)
+}
+
+//WatchlistUserIndexer a user store that keeps an index of the users of each watch list, updated on every SaveUser and DeleteUser, so the users of a watch list are found without loading every user.
+type WatchlistUserIndexer interface {
+	//WatchlistUsers the IDs of the users that reference the watch list, none if no one does.
+	WatchlistUsers(ctx context.Context, watchlistID string) ([]string, error)
+	//RebuildWatchlistIndex replaces the index with one built from the stored users, to repair it. Returns the number of watch lists indexed. A user that can not be loaded is an error naming them and the index is left as it was, it would be missing their watch lists.
+	RebuildWatchlistIndex(ctx context.Context) (int, error)
+}
+
+//ShareIndexer a user store that keeps an index of the user that shares a watch list with each share token, updated on every SaveUser and DeleteUser, so a share is found without loading every user. RebuildWatchlistIndex rebuilds it too.
+type ShareIndexer interface {
+	//ShareOwner the ID of the user that shares a watch list with the token, os.ErrNotExist if no one does.
+	ShareOwner(ctx context.Context, token string) (string, error)
 }
 
 //WatchlistStorer store able to perform watchlist store operations.
 type WatchlistStorer interface {
 	SaveWatchlist(ctx context.Context, watchlist *model.Watchlist) (string, error)
 	LoadWatchlist(ctx context.Context, watchlistID string) (*model.Watchlist, error)
+	//DeleteWatchlist os.ErrNotExist if there is no such watch list.
 	DeleteWatchlist(ctx context.Context, watchlistID string) error
+	//CopyWatchlistContent carries a watch list's content over to another watch list, see ebidlocal store.WatchlistContentCopier.
+	CopyWatchlistContent(ctx context.Context, fromWatchlistID string, toWatchlistID string) error
+	//SaveWatchlistSchedules the schedules of every user of the watch list, see ebidlocal store.WatchlistScheduler.
+	SaveWatchlistSchedules(ctx context.Context, watchlistID string, schedules ebidmodel.WatchlistSchedules) error
+	//ListWatchlistSnapshots the saved versions of the watch list's content oldest first, see ebidlocal store.WatchlistHistoryStorer.
+	ListWatchlistSnapshots(ctx context.Context, watchlistID string) ([]ebidmodel.WatchlistSnapshot, error)
+	LoadWatchlistSnapshot(ctx context.Context, watchlistID string, snapshotID string) (*ebidmodel.WatchlistContent, error)
+	//LoadWatchlistDefinition the watch list as the Ebidlocal store keeps it, the fs store writes it to the watch list's data file.
+	LoadWatchlistDefinition(ctx context.Context, watchlistID string) (ebidmodel.WatchlistDefinition, error)
+	//LoadWatchlistContent the watch list's latest content, see ebidlocal store.WatchlistContentStorer.
+	LoadWatchlistContent(ctx context.Context, watchlistID string) (*ebidmodel.WatchlistContent, error)
+}
+
+//WatchlistReferences the IDs of the users that reference each watch list. A user's entry may hold more than one comma separated watch list ID. A user that can not be loaded is an error, the references would be missing theirs; a user deleted since the users were listed is skipped.
+func WatchlistReferences(ctx context.Context, users UserStorer) (map[string][]string, error) {
+	var references = make(map[string][]string)
+
+	userIDs, err := users.ListUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, userID := range userIDs {
+		user, err := users.LoadUser(ctx, userID)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("user '%s'; %w", userID, err)
+		}
+		for _, listID := range UserWatchlistIDs(user) {
+			references[listID] = append(references[listID], user.ID)
+		}
+	}
+
+	return references, nil
+}
+
+//ShareOwner the ID of the user that shares a watch list with the token, os.ErrNotExist if no one does. Every user is loaded to find them, use a ShareIndexer if the store is one. A user that can not be loaded is an error, like WatchlistReferences.
+func ShareOwner(ctx context.Context, users UserStorer, token string) (string, error) {
+	userIDs, err := users.ListUsers(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, userID := range userIDs {
+		user, err := users.LoadUser(ctx, userID)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return "", fmt.Errorf("user '%s'; %w", userID, err)
+		}
+		if _, exists := user.Shares[token]; exists {
+			return user.ID, nil
+		}
+	}
+	return "", os.ErrNotExist
+}
+
+//UserWatchlistIDs the IDs of the user's watch lists sorted, each once. A user's entry may hold more than one comma separated watch list ID.
+func UserWatchlistIDs(user *model.User) []string {
+	var listIDs = []string{}
+	var seen = make(map[string]struct{})
+
+	for _, ids := range user.Watchlists {
+		for _, listID := range WatchlistIDs(ids) {
+			if _, exists := seen[listID]; !exists {
+				seen[listID] = struct{}{}
+				listIDs = append(listIDs, listID)
+			}
+		}
+	}
+	sort.Strings(listIDs)
+	return listIDs
+}
+
+//WatchlistIDs the watch list IDs of one of a user's entries in order, the entry may hold more than one comma separated watch list ID.
+func WatchlistIDs(entry string) []string {
+	var listIDs = []string{}
+
+	for _, listID := range strings.Split(entry, ",") {
+		if listID = strings.TrimSpace(listID); listID != "" {
+			listIDs = append(listIDs, listID)
+		}
+	}
+	return listIDs
 }
@@ -0,0 +1,242 @@
+//Package storetest the behavior every user store must have, run from the tests of each store so they stay interchangeable. The watch list stores are tested by the ebidlocal storetest package.
+package storetest
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/scirelli/auction-ebidlocal-search/internal/app/server/model"
+	"github.com/scirelli/auction-ebidlocal-search/internal/app/server/store"
+)
+
+//concurrency the goroutines the concurrent tests run at once.
+const concurrency = 8
+
+//UserStorer runs the user store tests, newStore returns an empty store for each test.
+func UserStorer(t *testing.T, newStore func(t *testing.T) store.UserStorer) {
+	var ctx = context.Background()
+	newUser := func(id string) *model.User {
+		return &model.User{
+			ID:         id,
+			Name:       "name " + id,
+			Email:      id + "@example.com",
+			Watchlists: map[string]string{"tools": "abc,def"},
+		}
+	}
+
+	t.Run("not found", func(t *testing.T) {
+		s := newStore(t)
+		_, err := s.LoadUser(ctx, "nobody")
+		assert.True(t, os.IsNotExist(err), "Loading a missing user is a not exist error, got %v", err)
+		ids, err := s.ListUsers(ctx)
+		assert.NoError(t, err)
+		assert.Empty(t, ids)
+	})
+
+	t.Run("save and load", func(t *testing.T) {
+		s := newStore(t)
+		user := newUser("u1")
+		id, err := s.SaveUser(ctx, user)
+		assert.NoError(t, err)
+		assert.Equal(t, "u1", id)
+
+		loaded, err := s.LoadUser(ctx, id)
+		assert.NoError(t, err)
+		assert.Equal(t, user, loaded)
+		ids, err := s.ListUsers(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"u1"}, ids)
+
+		//What is done to a loaded user does not change the stored user.
+		loaded.Watchlists["toys"] = "ghi"
+		loaded, _ = s.LoadUser(ctx, id)
+		assert.Equal(t, user.Watchlists, loaded.Watchlists)
+	})
+
+	t.Run("overwrite", func(t *testing.T) {
+		s := newStore(t)
+		user := newUser("u1")
+		_, err := s.SaveUser(ctx, user)
+		assert.NoError(t, err)
+		user.Email = "changed@example.com"
+		delete(user.Watchlists, "tools")
+		_, err = s.SaveUser(ctx, user)
+		assert.NoError(t, err)
+
+		loaded, err := s.LoadUser(ctx, "u1")
+		assert.NoError(t, err)
+		assert.Equal(t, "changed@example.com", loaded.Email, "Saving replaces the user")
+		assert.Empty(t, loaded.Watchlists)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		s := newStore(t)
+		for _, id := range []string{"u1", "u2"} {
+			_, err := s.SaveUser(ctx, newUser(id))
+			assert.NoError(t, err)
+		}
+		assert.NoError(t, s.DeleteUser(ctx, "u1"))
+		_, err := s.LoadUser(ctx, "u1")
+		assert.True(t, os.IsNotExist(err), "A deleted user is not found, got %v", err)
+		ids, _ := s.ListUsers(ctx)
+		assert.Equal(t, []string{"u2"}, ids)
+		err = s.DeleteUser(ctx, "u1")
+		assert.True(t, os.IsNotExist(err), "Deleting a missing user is a not exist error, like deleting a missing watch list, got %v", err)
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		s := newStore(t)
+		var wg sync.WaitGroup
+		for i := 0; i < concurrency; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				//Everyone saves the shared user and one of their own.
+				for _, user := range []*model.User{newUser("shared"), newUser(fmt.Sprintf("u%d", i))} {
+					_, err := s.SaveUser(ctx, user)
+					assert.NoError(t, err)
+					//A reader sees one whole save or another, never part of one.
+					loaded, err := s.LoadUser(ctx, user.ID)
+					if assert.NoError(t, err) {
+						assert.Equal(t, user, loaded)
+					}
+				}
+			}(i)
+		}
+		wg.Wait()
+
+		ids, err := s.ListUsers(ctx)
+		assert.NoError(t, err)
+		assert.Len(t, ids, concurrency+1)
+	})
+}
+
+//WatchlistUserIndexer runs the tests of the index of the users of each watch list, newStore returns an empty store for each test that must be a store.WatchlistUserIndexer.
+func WatchlistUserIndexer(t *testing.T, newStore func(t *testing.T) store.UserStorer) {
+	var ctx = context.Background()
+	newIndexed := func(t *testing.T) (store.UserStorer, store.WatchlistUserIndexer) {
+		s := newStore(t)
+		index, ok := s.(store.WatchlistUserIndexer)
+		if !ok {
+			t.Fatalf("%T does not index the users of watch lists", s)
+		}
+		return s, index
+	}
+	assertUsers := func(t *testing.T, index store.WatchlistUserIndexer, expected map[string][]string) {
+		for listID, userIDs := range expected {
+			actual, err := index.WatchlistUsers(ctx, listID)
+			assert.NoError(t, err)
+			assert.Equal(t, userIDs, actual, "The users of watch list '%s'", listID)
+		}
+	}
+
+	t.Run("save and delete", func(t *testing.T) {
+		s, index := newIndexed(t)
+		assertUsers(t, index, map[string][]string{"a": {}})
+
+		_, err := s.SaveUser(ctx, &model.User{ID: "u2", Watchlists: map[string]string{"tools": "a"}})
+		assert.NoError(t, err)
+		_, err = s.SaveUser(ctx, &model.User{ID: "u1", Watchlists: map[string]string{"tools": "a, b", "toys": "b"}})
+		assert.NoError(t, err)
+		assertUsers(t, index, map[string][]string{"a": {"u1", "u2"}, "b": {"u1"}})
+
+		_, err = s.SaveUser(ctx, &model.User{ID: "u1", Watchlists: map[string]string{"toys": "c"}})
+		assert.NoError(t, err)
+		assertUsers(t, index, map[string][]string{"a": {"u2"}, "b": {}, "c": {"u1"}})
+
+		assert.NoError(t, s.DeleteUser(ctx, "u2"))
+		assertUsers(t, index, map[string][]string{"a": {}, "c": {"u1"}})
+	})
+
+	t.Run("rebuild", func(t *testing.T) {
+		s, index := newIndexed(t)
+		for _, user := range []*model.User{
+			{ID: "u1", Watchlists: map[string]string{"tools": "a,b"}},
+			{ID: "u2", Watchlists: map[string]string{"tools": "b"}},
+			{ID: "u3"},
+		} {
+			_, err := s.SaveUser(ctx, user)
+			assert.NoError(t, err)
+		}
+
+		indexed, err := index.RebuildWatchlistIndex(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, indexed)
+		assertUsers(t, index, map[string][]string{"a": {"u1"}, "b": {"u1", "u2"}})
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		s, index := newIndexed(t)
+		var wg sync.WaitGroup
+		var expected []string
+		for i := 0; i < concurrency; i++ {
+			expected = append(expected, fmt.Sprintf("u%d", i))
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				_, err := s.SaveUser(ctx, &model.User{ID: fmt.Sprintf("u%d", i), Watchlists: map[string]string{"tools": "shared"}})
+				assert.NoError(t, err)
+			}(i)
+		}
+		wg.Wait()
+
+		assertUsers(t, index, map[string][]string{"shared": expected})
+	})
+}
+
+//ShareIndexer runs the tests of the index of the owner of each share token, newStore returns an empty store for each test that must be a store.ShareIndexer and a store.WatchlistUserIndexer.
+func ShareIndexer(t *testing.T, newStore func(t *testing.T) store.UserStorer) {
+	var ctx = context.Background()
+	newIndexed := func(t *testing.T) (store.UserStorer, store.ShareIndexer) {
+		s := newStore(t)
+		index, ok := s.(store.ShareIndexer)
+		if !ok {
+			t.Fatalf("%T does not index shares", s)
+		}
+		return s, index
+	}
+	assertOwners := func(t *testing.T, index store.ShareIndexer, expected map[string]string) {
+		for token, userID := range expected {
+			actual, err := index.ShareOwner(ctx, token)
+			if userID == "" {
+				assert.True(t, os.IsNotExist(err), "No one shares with '%s', got %v", token, err)
+				continue
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, userID, actual, "The owner of share '%s'", token)
+		}
+	}
+
+	t.Run("save and delete", func(t *testing.T) {
+		s, index := newIndexed(t)
+		assertOwners(t, index, map[string]string{"t1": ""})
+
+		_, err := s.SaveUser(ctx, &model.User{ID: "u1", Watchlists: map[string]string{"tools": "a", "toys": "b"}, Shares: map[string]string{"t1": "tools", "t2": "toys"}})
+		assert.NoError(t, err)
+		_, err = s.SaveUser(ctx, &model.User{ID: "u2", Watchlists: map[string]string{"tools": "a"}, Shares: map[string]string{"t3": "tools"}})
+		assert.NoError(t, err)
+		assertOwners(t, index, map[string]string{"t1": "u1", "t2": "u1", "t3": "u2"})
+
+		_, err = s.SaveUser(ctx, &model.User{ID: "u1", Watchlists: map[string]string{"toys": "b"}, Shares: map[string]string{"t2": "toys"}})
+		assert.NoError(t, err)
+		assertOwners(t, index, map[string]string{"t1": "", "t2": "u1", "t3": "u2"})
+
+		assert.NoError(t, s.DeleteUser(ctx, "u2"))
+		assertOwners(t, index, map[string]string{"t2": "u1", "t3": ""})
+	})
+
+	t.Run("rebuild", func(t *testing.T) {
+		s, index := newIndexed(t)
+		_, err := s.SaveUser(ctx, &model.User{ID: "u1", Watchlists: map[string]string{"tools": "a"}, Shares: map[string]string{"t1": "tools"}})
+		assert.NoError(t, err)
+
+		_, err = s.(store.WatchlistUserIndexer).RebuildWatchlistIndex(ctx)
+		assert.NoError(t, err)
+		assertOwners(t, index, map[string]string{"t1": "u1", "t2": ""})
+	})
+}
@@ -0,0 +1,296 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	"github.com/scirelli/auction-ebidlocal-search/internal/app/server/model"
+	"github.com/scirelli/auction-ebidlocal-search/internal/app/server/store"
+)
+
+//subscriber a user subscribed to a shared watch list and their name for it.
+type subscriber struct {
+	user *model.User
+	name string
+}
+
+//shareUserWatchlistHandlerFunc creates a share link for the user's watch list, a watch list is shared with a single link.
+func (s *Server) shareUserWatchlistHandlerFunc(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	userID, name := params["userID"], params["name"]
+
+	user, err := s.store.LoadUser(r.Context(), userID)
+	if err != nil {
+		s.logger.Error(err)
+		respondError(w, http.StatusNotFound, "User Not Found")
+		return
+	}
+	if _, exists := user.Watchlists[name]; !exists {
+		respondError(w, http.StatusNotFound, "Watch list Not Found")
+		return
+	}
+	if _, subscribed := user.Subscriptions[name]; subscribed {
+		respondError(w, http.StatusForbidden, "Only the owner can share a subscribed watch list")
+		return
+	}
+
+	token := shareToken(user, name)
+	if token == "" {
+		token = uuid.New().String()
+		if user.Shares == nil {
+			user.Shares = make(map[string]string)
+		}
+		user.Shares[token] = name
+		if _, err = s.store.SaveUser(r.Context(), user); err != nil {
+			s.logger.Error(err)
+			respondError(w, http.StatusInternalServerError, "Failed to share watch list")
+			return
+		}
+	}
+
+	link := fmt.Sprintf("/watchlist/shared/%s", url.PathEscape(token))
+	w.Header().Set("Location", link)
+	respondJSON(w, http.StatusCreated, struct {
+		Token string `json:"token"`
+		Link  string `json:"link"`
+	}{Token: token, Link: link})
+}
+
+//sharedWatchlistHandlerFunc the keywords and options of a shared watch list, so a user can see what they are subscribing to.
+func (s *Server) sharedWatchlistHandlerFunc(w http.ResponseWriter, r *http.Request) {
+	owner, name, err := s.findShare(r.Context(), mux.Vars(r)["token"])
+	if err != nil {
+		respondError(w, http.StatusNotFound, "Shared watch list Not Found")
+		return
+	}
+	listID := owner.Watchlists[name]
+	list, err := s.store.LoadWatchlist(r.Context(), listID)
+	if err != nil {
+		s.logger.Error(err)
+		respondError(w, http.StatusInternalServerError, "Failed to load watch list")
+		return
+	}
+	//A user's ID is all it takes to act as the user, never send it to other users.
+	list.Name, list.Owner = name, ""
+
+	respondJSON(w, http.StatusOK, struct {
+		WatchlistID string `json:"watchlistID"`
+		*model.Watchlist
+	}{WatchlistID: listID, Watchlist: list})
+}
+
+//subscribeUserHandlerFunc adds a shared watch list to the user's watch lists, under the owner's name for it unless a name is given.
+func (s *Server) subscribeUserHandlerFunc(w http.ResponseWriter, r *http.Request) {
+	var subscription struct {
+		Token string `json:"token"`
+		Name  string `json:"name"`
+	}
+
+	userID := mux.Vars(r)["userID"]
+
+	defer r.Body.Close()
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&subscription); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	user, err := s.store.LoadUser(r.Context(), userID)
+	if err != nil {
+		s.logger.Error(err)
+		respondError(w, http.StatusNotFound, "User Not Found")
+		return
+	}
+	owner, name, err := s.findShare(r.Context(), subscription.Token)
+	if err != nil {
+		respondError(w, http.StatusNotFound, "Shared watch list Not Found")
+		return
+	}
+	if owner.ID == user.ID {
+		respondError(w, http.StatusBadRequest, "Can not subscribe to your own watch list")
+		return
+	}
+	if subscription.Name == "" {
+		subscription.Name = name
+	}
+	if _, exists := user.Watchlists[subscription.Name]; exists {
+		respondError(w, http.StatusConflict, fmt.Sprintf("Watch list '%s' already exists", subscription.Name))
+		return
+	}
+
+	listID := owner.Watchlists[name]
+	user.Watchlists[subscription.Name] = listID
+	if user.Subscriptions == nil {
+		user.Subscriptions = make(map[string]model.Subscription)
+	}
+	user.Subscriptions[subscription.Name] = model.Subscription{Owner: owner.ID, Token: subscription.Token}
+	if _, err = s.store.SaveUser(r.Context(), user); err != nil {
+		s.logger.Error(err)
+		respondError(w, http.StatusInternalServerError, "Failed to subscribe")
+		return
+	}
+
+	s.syncWatchlistSchedules(r.Context(), listID)
+
+	s.logger.Infof("User '%s' subscribed to watch list '%s'", user.ID, listID)
+	w.Header().Set("Location", fmt.Sprintf("/user/%s/watchlist/%s", url.PathEscape(user.ID), url.PathEscape(listID)))
+	respondJSON(w, http.StatusCreated, struct {
+		WatchlistID string `json:"watchlistID"`
+		Name        string `json:"name"`
+	}{WatchlistID: listID, Name: subscription.Name})
+}
+
+//watchlistSubscribersHandlerFunc the users subscribed to the owner's watch list. Only their names are sent.
+func (s *Server) watchlistSubscribersHandlerFunc(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	userID, name := params["userID"], params["name"]
+
+	user, err := s.store.LoadUser(r.Context(), userID)
+	if err != nil {
+		s.logger.Error(err)
+		respondError(w, http.StatusNotFound, "User Not Found")
+		return
+	}
+	if _, exists := user.Watchlists[name]; !exists {
+		respondError(w, http.StatusNotFound, "Watch list Not Found")
+		return
+	}
+	if _, subscribed := user.Subscriptions[name]; subscribed {
+		respondError(w, http.StatusForbidden, "Only the owner can list the subscribers of a watch list")
+		return
+	}
+
+	subscribers, err := s.subscribers(r.Context(), user, name, user.Watchlists[name])
+	if err != nil {
+		s.logger.Error(err)
+		respondError(w, http.StatusInternalServerError, "Failed to list subscribers")
+		return
+	}
+	var names = make([]string, 0, len(subscribers))
+	for _, subscriber := range subscribers {
+		names = append(names, subscriber.user.Name)
+	}
+	sort.Strings(names)
+
+	respondJSON(w, http.StatusOK, struct {
+		Subscribers []string `json:"subscribers"`
+	}{Subscribers: names})
+}
+
+//findShare the user that shared a watch list with the token and their name for it. The owner is found by the store's share index if it keeps one.
+func (s *Server) findShare(ctx context.Context, token string) (*model.User, string, error) {
+	if token == "" {
+		return nil, "", os.ErrNotExist
+	}
+	var ownerID string
+	var err error
+	if index, ok := s.store.(store.ShareIndexer); ok {
+		ownerID, err = index.ShareOwner(ctx, token)
+	} else {
+		ownerID, err = store.ShareOwner(ctx, s.store, token)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	user, err := s.store.LoadUser(ctx, ownerID)
+	if err != nil {
+		return nil, "", err
+	}
+	if name, exists := user.Shares[token]; exists {
+		if _, exists := user.Watchlists[name]; exists {
+			return user, name, nil
+		}
+	}
+	return nil, "", os.ErrNotExist
+}
+
+//subscribers the users subscribed to the owner's watch list. Subscribers reference the watch list by listID, its ID before the owner's latest edit while they are being made to follow it.
+func (s *Server) subscribers(ctx context.Context, owner *model.User, name string, listID string) ([]subscriber, error) {
+	var subscribers []subscriber
+
+	token := shareToken(owner, name)
+	if token == "" || listID == "" {
+		return subscribers, nil
+	}
+	userIDs, err := s.watchlistUsers(ctx, listID)
+	if err != nil {
+		return nil, err
+	}
+	for _, userID := range userIDs {
+		if userID == owner.ID {
+			continue
+		}
+		user, err := s.store.LoadUser(ctx, userID)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("user '%s'; %w", userID, err)
+		}
+		for subscribedName, subscription := range user.Subscriptions {
+			if subscription.Owner == owner.ID && subscription.Token == token {
+				subscribers = append(subscribers, subscriber{user: user, name: subscribedName})
+			}
+		}
+	}
+	return subscribers, nil
+}
+
+//followOwner points the subscribers of the owner's watch list at the owner's watch list ID, after the owner edited it from previousID.
+func (s *Server) followOwner(ctx context.Context, owner *model.User, name string, previousID string) {
+	subscribers, err := s.subscribers(ctx, owner, name, previousID)
+	if err != nil {
+		s.logger.Errorf("Failed to find the subscribers of '%s'; %s", name, err)
+		return
+	}
+	for _, subscriber := range subscribers {
+		subscriber.user.Watchlists[subscriber.name] = owner.Watchlists[name]
+		if _, err := s.store.SaveUser(ctx, subscriber.user); err != nil {
+			s.logger.Errorf("Failed to update subscriber '%s'; %s", subscriber.user.ID, err)
+		}
+	}
+}
+
+//unshare removes the share of the owner's watch list. Subscribers keep the watch list as their own.
+func (s *Server) unshare(ctx context.Context, owner *model.User, name string) {
+	subscribers, err := s.subscribers(ctx, owner, name, owner.Watchlists[name])
+	if err != nil {
+		s.logger.Errorf("Failed to find the subscribers of '%s'; %s", name, err)
+	}
+	for _, subscriber := range subscribers {
+		delete(subscriber.user.Subscriptions, subscriber.name)
+		if _, err := s.store.SaveUser(ctx, subscriber.user); err != nil {
+			s.logger.Errorf("Failed to update subscriber '%s'; %s", subscriber.user.ID, err)
+		}
+	}
+	delete(owner.Shares, shareToken(owner, name))
+}
+
+//shareToken the token the user shares a watch list with, empty if it is not shared.
+func shareToken(user *model.User, name string) string {
+	for token, shared := range user.Shares {
+		if shared == name {
+			return token
+		}
+	}
+	return ""
+}
+
+//renameShare the share follows the watch list when the owner renames it, subscribers keep their own name for it.
+func renameShare(user *model.User, from string, to string) {
+	if token := shareToken(user, from); token != "" {
+		user.Shares[token] = to
+	}
+	if subscription, subscribed := user.Subscriptions[from]; subscribed {
+		delete(user.Subscriptions, from)
+		user.Subscriptions[to] = subscription
+	}
+}
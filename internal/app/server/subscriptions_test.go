@@ -0,0 +1,60 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/scirelli/auction-ebidlocal-search/internal/app/server/model"
+)
+
+func TestSubscriptionsUseTheIndexes(t *testing.T) {
+	var ctx = context.Background()
+	var store = newTestStore()
+	var s = newTestServer(t, store)
+
+	listID, err := store.SaveWatchlist(ctx, &model.Watchlist{List: []string{"kayak"}})
+	assert.NoError(t, err)
+	owner := &model.User{ID: "u1", Watchlists: map[string]string{"Boats": listID}}
+	_, err = store.SaveUser(ctx, owner)
+	assert.NoError(t, err)
+	_, err = store.SaveUser(ctx, &model.User{ID: "u2", Watchlists: map[string]string{}})
+	assert.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	s.shareUserWatchlistHandlerFunc(w, mux.SetURLVars(httptest.NewRequest(http.MethodPost, "/", nil), map[string]string{"userID": "u1", "name": "Boats"}))
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var share struct {
+		Token string `json:"token"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &share))
+
+	w = httptest.NewRecorder()
+	s.subscribeUserHandlerFunc(w, mux.SetURLVars(httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"token": "`+share.Token+`"}`)), map[string]string{"userID": "u2"}))
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	//The owner edits the watch list, the subscriber follows.
+	owner, _ = store.LoadUser(ctx, "u1")
+	newID, err := store.SaveWatchlist(ctx, &model.Watchlist{List: []string{"kayak", "canoe"}})
+	assert.NoError(t, err)
+	owner.Watchlists["Boats"] = newID
+	_, err = store.SaveUser(ctx, owner)
+	assert.NoError(t, err)
+	s.followOwner(ctx, owner, "Boats", listID)
+	subscriber, err := store.LoadUser(ctx, "u2")
+	assert.NoError(t, err)
+	assert.Equal(t, newID, subscriber.Watchlists["Boats"])
+
+	subscribers, err := s.subscribers(ctx, owner, "Boats", newID)
+	assert.NoError(t, err)
+	if assert.Len(t, subscribers, 1) {
+		assert.Equal(t, "u2", subscribers[0].user.ID)
+	}
+	assert.Zero(t, *store.listed, "Shares and subscribers are found without listing every user")
+}
@@ -0,0 +1,45 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+//watchlistFileServer serves the files of WatchlistDir, the request's path is a watch list's ID followed by the file in its directory. The watch list's definition and content are loaded from the store when they are not on disk, the SQLite store keeps them in its database; the web files, such as email.html, are always on disk.
+func (s *Server) watchlistFileServer() http.Handler {
+	var files = http.FileServer(http.Dir(s.config.WatchlistDir))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		dir, name := path.Split(strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/"))
+		listID := strings.TrimSuffix(dir, "/")
+		if listID == "" || strings.Contains(listID, "/") || (name != s.config.DataFileName && name != s.config.ContentFileName) {
+			files.ServeHTTP(w, r)
+			return
+		}
+		if _, err := os.Stat(filepath.Join(s.config.WatchlistDir, listID, name)); err == nil {
+			files.ServeHTTP(w, r)
+			return
+		}
+
+		var document interface{}
+		var err error
+		if name == s.config.DataFileName {
+			document, err = s.store.LoadWatchlistDefinition(r.Context(), listID)
+		} else {
+			document, err = s.store.LoadWatchlistContent(r.Context(), listID)
+		}
+		if errors.Is(err, os.ErrNotExist) {
+			http.NotFound(w, r)
+			return
+		} else if err != nil {
+			s.logger.Error(err)
+			respondError(w, http.StatusInternalServerError, "Failed to load watch list")
+			return
+		}
+		respondJSON(w, http.StatusOK, document)
+	})
+}
@@ -0,0 +1,79 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/scirelli/auction-ebidlocal-search/internal/app/server/model"
+	"github.com/scirelli/auction-ebidlocal-search/internal/app/server/store/memory"
+	ebidmodel "github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/model"
+	ebidsqlite "github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/store/sqlite"
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/sqldb"
+)
+
+func TestWatchlistFileServerSQLiteStore(t *testing.T) {
+	var ctx = context.Background()
+	var contentPath = t.TempDir()
+
+	db, err := sqldb.Open(*sqldb.Defaults(&sqldb.Config{}, contentPath))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	t.Cleanup(func() { db.Close() })
+	watchlists := ebidsqlite.New(db, ebidsqlite.Config{}, nil)
+	_, err = watchlists.Migrate(ctx)
+	assert.NoError(t, err)
+
+	var store = newTestStore()
+	store.Store = memory.New(watchlists)
+	var s = newTestServer(t, store)
+	s.config = *Defaults(&Config{ContentPath: contentPath})
+
+	listID, err := store.SaveWatchlist(ctx, &model.Watchlist{List: []string{"kayak"}})
+	assert.NoError(t, err)
+	_, err = watchlists.SaveWatchlistContent(ctx, &ebidmodel.WatchlistContent{WatchlistID: listID, AuctionItems: []ebidmodel.AuctionItem{{Id: "1", ItemName: "Kayak"}}})
+	assert.NoError(t, err)
+	//The notifier writes the email to disk with either store.
+	assert.NoError(t, os.MkdirAll(filepath.Join(s.config.WatchlistDir, listID), 0775))
+	assert.NoError(t, os.WriteFile(filepath.Join(s.config.WatchlistDir, listID, "email.html"), []byte("<p>Kayak</p>"), 0644))
+
+	get := func(target string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		s.watchlistFileServer().ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+		return w
+	}
+
+	w := get("/" + listID + "/models.json")
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var content ebidmodel.WatchlistContent
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &content))
+	if assert.Len(t, content.AuctionItems, 1, "The content is loaded from the store") {
+		assert.Equal(t, "Kayak", content.AuctionItems[0].ItemName)
+	}
+
+	w = get("/" + listID + "/data.json")
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var definition ebidmodel.WatchlistDefinition
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &definition))
+	assert.Equal(t, ebidmodel.Watchlist{"kayak"}, definition.Keywords, "The definition is loaded from the store")
+
+	w = get("/" + listID + "/email.html")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "<p>Kayak</p>", w.Body.String(), "The web files are served from disk")
+
+	w = get("/missing/models.json")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	//The fs store's files are served as they are on disk.
+	assert.NoError(t, os.WriteFile(filepath.Join(s.config.WatchlistDir, listID, "models.json"), []byte(`{"id":"on disk"}`), 0644))
+	w = get("/" + listID + "/models.json")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"id":"on disk"}`, w.Body.String())
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/score"
 	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/log"
 )
 
@@ -41,6 +42,26 @@ func Defaults(config *Config) *Config {
 		config.WatchlistDir = filepath.Join(config.ContentPath, "web", "watchlists")
 		logger.Infof("Defaulting watchlist dir to '%s'\n", config.WatchlistDir)
 	}
+	if config.ArchiveDir == "" {
+		config.ArchiveDir = filepath.Join(config.ContentPath, "archive", "items")
+		logger.Infof("Defaulting archive dir to '%s'\n", config.ArchiveDir)
+	}
+	if config.SynonymsFile == "" {
+		config.SynonymsFile = filepath.Join(config.ContentPath, "assets", "synonyms.json")
+		logger.Infof("Defaulting synonyms file to '%s'\n", config.SynonymsFile)
+	}
+	if config.HistoryMaxSnapshots == 0 {
+		config.HistoryMaxSnapshots = 100
+		logger.Infof("Defaulting history max snapshots to '%d'\n", config.HistoryMaxSnapshots)
+	}
+	if config.HistoryMaxAge == 0 {
+		config.HistoryMaxAge = 90 * 24 * 60 * 60
+		logger.Infof("Defaulting history max age to '%d' seconds\n", config.HistoryMaxAge)
+	}
+	if config.ScoreWeights == (score.Weights{}) {
+		config.ScoreWeights = score.DefaultWeights
+		logger.Infof("Defaulting score weights to '%+v'\n", config.ScoreWeights)
+	}
 
 	return config
 }
@@ -50,7 +71,30 @@ type Config struct {
 	//ContentPath all config paths should be relative to the content path.
 	ContentPath  string `json:"contentPath"`
 	WatchlistDir string `json:"watchlistDir"`
+	//ArchiveDir where items whose auction closed are kept, see model.ArchivedItem.
+	ArchiveDir string `json:"archiveDir"`
+	//ScoreWeights how items are ranked in a watch list's content.
+	ScoreWeights score.Weights `json:"scoreWeights"`
+	//SynonymsFile the shared synonym dictionary watch list keywords are expanded with, see package synonym.
+	SynonymsFile string `json:"synonymsFile"`
+	//HistoryMaxSnapshots the most snapshots of a watch list's content kept, negative keeps them all.
+	HistoryMaxSnapshots int `json:"historyMaxSnapshots"`
+	//HistoryMaxAge seconds a snapshot of a watch list's content is kept, negative keeps them forever.
+	HistoryMaxAge int64 `json:"historyMaxAgeSeconds"`
+	//MinBidChange the least a current bid has to change by to be a change the users are emailed about, zero is every change.
+	MinBidChange float64 `json:"minBidChange"`
 
 	Debug    bool         `json:"debug"`
 	LogLevel log.LogLevel `json:"logLevel"`
 }
+
+//HistoryRetention the retention policy of the content store, where zero keeps everything.
+func (c *Config) HistoryRetention() (maxSnapshots int, maxAge int64) {
+	if maxSnapshots = c.HistoryMaxSnapshots; maxSnapshots < 0 {
+		maxSnapshots = 0
+	}
+	if maxAge = c.HistoryMaxAge; maxAge < 0 {
+		maxAge = 0
+	}
+	return maxSnapshots, maxAge
+}
@@ -2,14 +2,18 @@ package update
 
 import (
 	"context"
-	"io"
-	"os"
+	"fmt"
 	"path/filepath"
+	"sort"
 	"time"
 
 	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/filter"
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/match"
 	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/model"
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/score"
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/search"
 	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/store"
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/synonym"
 	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/iter/stringiter"
 	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/log"
 	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/publish"
@@ -19,10 +23,15 @@ type Updater interface {
 	Update(watchlistPath <-chan string) error
 }
 
-//New constructor for updater app. The updater subscribes to watch list file channel. When it receives a watch list it then updates the data.
-func New(ctx context.Context, watchlistStore store.Storer, searchExtractor SearchExtractor, config Config) *Update {
+//New constructor for updater app. The updater subscribes to watch list file channel. When it receives a watch list it then updates the data. Items whose auction closed are saved to the archive, nil keeps no archive.
+func New(ctx context.Context, watchlistStore store.Storer, searchExtractor SearchExtractor, config Config, archive store.ItemArchiver) *Update {
 	var logger = log.New("Update", log.DEFAULT_LOG_LEVEL)
 
+	synonyms, err := synonym.Load(config.SynonymsFile)
+	if err != nil {
+		logger.Warnf("Update.New: No shared synonyms; %s", err)
+	}
+
 	return &Update{
 		config:          config,
 		logger:          logger,
@@ -30,6 +39,8 @@ func New(ctx context.Context, watchlistStore store.Storer, searchExtractor Searc
 		ctx:             ctx,
 		store:           watchlistStore,
 		changePublsr:    publish.NewStringChange(),
+		synonyms:        synonyms,
+		archive:         archive,
 	}
 }
 
@@ -41,6 +52,8 @@ type Update struct {
 	store           store.Storer
 	ctx             context.Context
 	changePublsr    publish.StringPublisher
+	synonyms        *synonym.Dictionary
+	archive         store.ItemArchiver
 }
 
 //SubscribeForChange returns a channel that can be monitored for changes, it also returns a function to call unsubscribe the channel.
@@ -57,10 +70,17 @@ func (u *Update) Update(watchlistFilePaths <-chan string) error {
 			break
 		default:
 		}
-		if err := u.updateWatchlistContent(watchlistIDFromPath(filepath.Dir(path))); err != nil {
-			u.logger.Error(err)
+		id := watchlistIDFromPath(filepath.Dir(path))
+		if !u.active(id) {
+			u.logger.Debugf("Update.Update: Skipping inactive watch list '%s'", id)
 			continue
 		}
+		startedAt := time.Now()
+		err := u.updateWatchlistContent(id)
+		if err != nil {
+			u.logger.Error(err)
+		}
+		u.saveScanState(id, startedAt, err)
 	}
 	return nil
 }
@@ -68,7 +88,7 @@ func (u *Update) Update(watchlistFilePaths <-chan string) error {
 //updateWatchlistContent determines if a watch list's content has changed, updates that content then publishes that there was a change.
 func (u *Update) updateWatchlistContent(id string) error {
 	var err error
-	var watchlist model.Watchlist
+	var watchlist model.WatchlistDefinition
 	var watchlistContent = model.WatchlistContent{
 		WatchlistID: id,
 		Timestamp:   time.Now(),
@@ -80,24 +100,35 @@ func (u *Update) updateWatchlistContent(id string) error {
 	}
 
 	u.logger.Debugf("Updater.updateWatchlistContent: Checking watch list id: '%s'", id)
-	for item := range u.searchAuctionForWatchlist(watchlist) {
+	items, err := u.searchAuctionForWatchlist(watchlist)
+	if err != nil {
+		u.logger.Errorf("Updater.updateWatchlistContent: Skipping watch list '%s'; %s", id, err)
+		return err
+	}
+	for item := range items {
 		watchlistContent.AuctionItems = append(watchlistContent.AuctionItems, item)
 	}
-
-	contentID := u.getSavedContentId(id)
-	if contentID == watchlistContent.ID() {
-		u.logger.Debugf("Updater.updateWatchlistContent: No changes for id('%s')", contentID)
+	sort.Sort(model.ByScore(score.New(u.config.ScoreWeights, match.New(watchlist.Options.Match), watchlist.Keywords, watchlistContent.Timestamp).Items(watchlistContent.AuctionItems)))
+
+	//The content ID only changes when items are added or removed, the previous content is diffed so price changes are seen too.
+	previous := u.previousContent(id)
+	u.archiveClosedItems(previous, &watchlistContent)
+	//Smaller bid changes do not save the content, they add up until they are big enough or something else changes.
+	changeset := model.DiffWatchlistContent(previous, &watchlistContent).IgnoreBidChanges(u.config.MinBidChange)
+	if changeset.IsEmpty() {
+		u.logger.Debugf("Updater.updateWatchlistContent: No changes for id('%s')", changeset.ContentID)
 		return nil
 	}
 
-	u.logger.Debugf("Updater.updateWatchlistContent: There was a change to watch list: '%s'", id)
+	u.logger.Debugf("Updater.updateWatchlistContent: There was a change to watch list: '%s'; %s", id, changeset.Summary())
 	if _, err = u.store.SaveWatchlistContent(u.ctx, &watchlistContent); err != nil {
 		u.logger.Debugf("Updater.saveContent: Was not able to save the content for watchlist '%s'", id)
 		return err
 	}
-	if err = u.saveContentHash(id, watchlistContent.ID()); err != nil {
-		u.logger.Debugf("Updater.saveContentHash: Was not able to save the content hash for watchlist '%s'", id)
-		return err
+	if changesets, ok := u.store.(store.WatchlistChangesetStorer); ok {
+		if err = changesets.SaveWatchlistChangeset(u.ctx, changeset); err != nil {
+			u.logger.Errorf("Updater.updateWatchlistContent: Was not able to save the changes for watchlist '%s'; %s", id, err)
+		}
 	}
 	u.logger.Debugf("Updater.updateWatchlistContent: Publishing change for: '%s'", id)
 	u.changePublsr.Publish(id)
@@ -105,48 +136,79 @@ func (u *Update) updateWatchlistContent(id string) error {
 	return nil
 }
 
-func (u *Update) searchAuctionForWatchlist(watchlist model.Watchlist) <-chan model.AuctionItem {
-	return model.FilterAuctionItemChan(u.searchExtractor.Extract(u.searchExtractor.Search(stringiter.SliceStringIterator(watchlist)))).Filter(model.FilterFunc(filter.ByKeyword))
-}
-
-func (u *Update) saveContentHash(watchlistID string, contentHash string) error {
-	var file *os.File
-	var err error
-
-	if file, err = os.OpenFile(u.watchlistHashFilePathFromID(watchlistID), os.O_RDWR|os.O_CREATE, 0644); err != nil {
-		return err
+//searchAuctionForWatchlist searches for the terms of each of the watch list's queries, expanded with the shared synonyms and the watch list's own, returning the items that make it through the watch list's filter pipeline, see filter.BuildPipeline. An error if the pipeline can not be built, nothing is searched.
+func (u *Update) searchAuctionForWatchlist(watchlist model.WatchlistDefinition) (<-chan model.AuctionItem, error) {
+	queryTerms, err := search.NewSynonymQueryTerms(watchlist.Keywords, u.synonyms.Merge(watchlist.Options.Synonyms))
+	if err != nil {
+		u.logger.Errorf("Updater.searchAuctionForWatchlist: Skipping invalid queries; %s", err)
 	}
-	defer file.Close()
-	if cnt, err := file.WriteAt([]byte(contentHash), 0); err != nil || cnt != len(contentHash) {
-		return err
+	pipeline, err := filter.BuildPipeline(watchlist.Options)
+	if err != nil {
+		//Falling back to a wider pipeline would let through items the user's filters exclude.
+		return nil, fmt.Errorf("invalid filters; %w", err)
 	}
+	return pipeline.Chan(queryTerms.Tag(u.searchExtractor.Extract(u.searchExtractor.Search(stringiter.SliceStringIterator(queryTerms.Terms))))), nil
+}
 
-	return nil
+//previousContent the watch list's saved content, nil if it has none.
+func (u *Update) previousContent(watchlistID string) *model.WatchlistContent {
+	content, err := u.store.LoadWatchlistContent(u.ctx, watchlistID)
+	if err != nil {
+		u.logger.Debugf("Updater.previousContent: No saved content for '%s'; %s", watchlistID, err)
+		return nil
+	}
+	return content
 }
 
-func (u *Update) getSavedContentId(watchlistID string) string {
-	var file *os.File
-	var err error
-	if file, err = os.OpenFile(u.watchlistHashFilePathFromID(watchlistID), os.O_RDWR|os.O_CREATE, 0644); err != nil {
-		u.logger.Error(err)
-		return ""
+//archiveClosedItems saves the items whose auction closed, with the last price they were seen at, before they drop out of the watch list for good.
+func (u *Update) archiveClosedItems(previous *model.WatchlistContent, current *model.WatchlistContent) {
+	if u.archive == nil {
+		return
 	}
-	defer file.Close()
-	shaLength := 40
-	buf := make([]byte, shaLength)
-	if cnt, err := file.Read(buf); err != nil && err != io.EOF {
-		u.logger.Errorf("Bytes read: '%d'; '%s'", cnt, err)
-		return ""
+	closed := model.ClosedAuctionItems(previous, current, current.GetTimestamp())
+	if len(closed) == 0 {
+		return
 	}
-	return string(buf)
+	var items = make([]model.ArchivedItem, 0, len(closed))
+	for _, item := range closed {
+		items = append(items, model.NewArchivedItem(item, current.GetWatchlistID(), current.GetTimestamp()))
+	}
+	if err := u.archive.ArchiveItems(u.ctx, items); err != nil {
+		u.logger.Errorf("Updater.archiveClosedItems: Was not able to archive the closed items of watchlist '%s'; %s", current.GetWatchlistID(), err)
+		return
+	}
+	u.logger.Debugf("Updater.archiveClosedItems: Archived %d closed item(s) of watchlist '%s'", len(items), current.GetWatchlistID())
 }
 
-func (u *Update) watchlistPathFromID(watchlistID string) string {
-	return filepath.Join(u.config.WatchlistDir, watchlistID)
+//active the watch list is searched unless every user paused, snoozed or let it expire, see model.WatchlistSchedules. The scanner skips inactive watch lists, they are checked again since a schedule may change while the watch list is queued.
+func (u *Update) active(watchlistID string) bool {
+	scheduler, ok := u.store.(store.WatchlistScheduler)
+	if !ok {
+		return true
+	}
+	schedules, err := scheduler.LoadWatchlistSchedules(u.ctx, watchlistID)
+	if err != nil {
+		u.logger.Errorf("Update.active: Failed to read the schedules of '%s'; %s", watchlistID, err)
+		return true
+	}
+	return schedules.Active(time.Now())
 }
 
-func (u *Update) watchlistHashFilePathFromID(watchlistID string) string {
-	return filepath.Join(u.config.WatchlistDir, watchlistID, "hash")
+//saveScanState records the outcome of searching the watch list, see model.WatchlistScanState. A state that can not be saved is logged, the search itself is done.
+func (u *Update) saveScanState(watchlistID string, at time.Time, scanErr error) {
+	state, err := u.store.LoadWatchlistScanState(u.ctx, watchlistID)
+	if err != nil {
+		u.logger.Errorf("Updater.saveScanState: Starting a new state for watchlist '%s'; %s", watchlistID, err)
+		state = &model.WatchlistScanState{WatchlistID: watchlistID}
+	}
+	if scanErr != nil {
+		state.Failed(at, scanErr)
+	} else {
+		state.Succeeded(at)
+	}
+	if err = u.store.SaveWatchlistScanState(u.ctx, state); err != nil {
+		u.logger.Errorf("Updater.saveScanState: Was not able to save the state of watchlist '%s'; %s", watchlistID, err)
+	}
 }
 
 func watchlistIDFromPath(watchlistFilePath string) string {
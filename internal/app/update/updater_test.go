@@ -0,0 +1,169 @@
+package update
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	appextract "github.com/scirelli/auction-ebidlocal-search/internal/app/extract"
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/extract"
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/match"
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/model"
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/store/fs"
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/store/memory"
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/iter/stringiter"
+)
+
+type searchFunc func(keywords stringiter.Iterable) chan model.SearchResult
+
+func (s searchFunc) Search(keywords stringiter.Iterable) chan model.SearchResult {
+	return s(keywords)
+}
+
+//noResults a searcher that finds nothing.
+var noResults = searchFunc(func(keywords stringiter.Iterable) chan model.SearchResult {
+	var results = make(chan model.SearchResult)
+	close(results)
+	return results
+})
+
+//itemRow an auction item the way the search result pages list it, see the extract package.
+const itemRow = `
+		<div class="row">
+			<div class="AuctionItem-listInfo">
+				<input name="AuctionItemId" value="%s"/>
+				<input name="ItemName" value="%s"/>
+				<input name="CurrentBidAmount" value="%d"/>
+				<input name="EndDate" value="%s"/>
+			</div>
+		</div>`
+
+//pageResults a searcher that finds the page for every keyword.
+func pageResults(auctionID string, page string) searchFunc {
+	return func(keywords stringiter.Iterable) chan model.SearchResult {
+		var results = make(chan model.SearchResult)
+		go func() {
+			defer close(results)
+			for iter, keyword, ok := keywords.Iterator(), "", true; ok; {
+				if keyword, ok = iter.Next(); ok {
+					results <- model.SearchResult{AuctionID: auctionID, Keyword: keyword, Content: page}
+				}
+			}
+		}()
+		return results
+	}
+}
+
+func TestUpdateArchivesExtractedClosedItems(t *testing.T) {
+	var ctx = context.Background()
+	var store = memory.New(memory.Config{})
+	var config = Defaults(&Config{ContentPath: t.TempDir()})
+	var archive = fs.NewItemArchiveStore(fs.ItemArchiveStoreConfig{ArchiveDir: config.ArchiveDir}, nil)
+
+	id, err := store.SaveWatchlist(ctx, model.WatchlistDefinition{Keywords: model.Watchlist{"kayak"}})
+	assert.NoError(t, err)
+	page := fmt.Sprintf(itemRow, "1", "Red kayak", 120, "2021-09-10 9:01:00 AM") +
+		fmt.Sprintf(itemRow, "2", "Blue kayak", 80, time.Now().AddDate(1, 0, 0).Format("2006-01-02 3:04:05 PM"))
+
+	u := New(ctx, store, EbidlocalExtractor{appextract.NewAuctionItem(&appextract.Config{}), pageResults("1000", page)}, *config, archive)
+	err = u.updateWatchlistContent(id)
+	assert.NoError(t, err)
+
+	archived, err := archive.QueryArchivedItems(ctx, model.ArchiveQuery{})
+	assert.NoError(t, err)
+	if assert.Len(t, archived, 1, "Only the item whose end date passed is archived") {
+		assert.Equal(t, "Red kayak", archived[0].Item.ItemName)
+		assert.Equal(t, float64(120), archived[0].FinalBid)
+		assert.Equal(t, []string{id}, archived[0].WatchlistIDs)
+		assert.True(t, archived[0].ClosedAt.Equal(time.Date(2021, 9, 10, 13, 1, 0, 0, time.UTC)), "The item closed at its end date, New York time, got '%s'", archived[0].ClosedAt)
+	}
+}
+
+func TestUpdateSkipsWatchlistWithoutPipeline(t *testing.T) {
+	var ctx = context.Background()
+	var store = memory.New(memory.Config{})
+	var config = Defaults(&Config{ContentPath: t.TempDir()})
+
+	id, err := store.SaveWatchlist(ctx, model.WatchlistDefinition{
+		Keywords: model.Watchlist{"kayak"},
+		Options: model.WatchlistOptions{
+			Match:   match.Options{Fuzzy: match.MaxFuzzy + 1},
+			Filters: []model.FilterSpec{{Name: "unknown"}},
+		},
+	})
+	assert.NoError(t, err)
+
+	u := New(ctx, store, EbidlocalExtractor{extract.NopExtractor, noResults}, *config, nil)
+	err = u.updateWatchlistContent(id)
+	assert.Error(t, err, "Neither the watch list's pipeline nor the default one can be built")
+	_, err = store.LoadWatchlistContent(ctx, id)
+	assert.Error(t, err, "No content is saved")
+}
+
+func TestUpdateSkipsWatchlistWithInvalidFilters(t *testing.T) {
+	var ctx = context.Background()
+	var store = memory.New(memory.Config{})
+	var config = Defaults(&Config{ContentPath: t.TempDir()})
+	var searched bool
+	var page = fmt.Sprintf(itemRow, "1", "Red kayak", 120, time.Now().AddDate(1, 0, 0).Format("2006-01-02 3:04:05 PM"))
+	var search = pageResults("1000", page)
+
+	id, err := store.SaveWatchlist(ctx, model.WatchlistDefinition{
+		Keywords: model.Watchlist{"kayak"},
+		Options:  model.WatchlistOptions{Filters: []model.FilterSpec{{Name: "unknown"}}},
+	})
+	assert.NoError(t, err)
+
+	u := New(ctx, store, EbidlocalExtractor{appextract.NewAuctionItem(&appextract.Config{}), searchFunc(func(keywords stringiter.Iterable) chan model.SearchResult {
+		searched = true
+		return search(keywords)
+	})}, *config, nil)
+	err = u.updateWatchlistContent(id)
+	assert.Error(t, err, "The default pipeline is not used in place of the user's filters")
+	assert.False(t, searched, "Nothing is searched for the watch list")
+	_, err = store.LoadWatchlistContent(ctx, id)
+	assert.Error(t, err, "No content is saved")
+}
+
+//failingScanStateStore a store that can not save scan states.
+type failingScanStateStore struct {
+	*memory.Store
+}
+
+func (s failingScanStateStore) SaveWatchlistScanState(ctx context.Context, state *model.WatchlistScanState) error {
+	return errors.New("disk full")
+}
+
+func TestUpdateContinuesWhenTheScanStateCanNotBeSaved(t *testing.T) {
+	var ctx = context.Background()
+	var store = failingScanStateStore{memory.New(memory.Config{})}
+	var config = Defaults(&Config{ContentPath: t.TempDir()})
+	var page = fmt.Sprintf(itemRow, "1", "Red kayak", 120, time.Now().AddDate(1, 0, 0).Format("2006-01-02 3:04:05 PM"))
+	var paths = make(chan string, 2)
+
+	first, err := store.SaveWatchlist(ctx, model.WatchlistDefinition{Keywords: model.Watchlist{"kayak"}})
+	assert.NoError(t, err)
+	second, err := store.SaveWatchlist(ctx, model.WatchlistDefinition{Keywords: model.Watchlist{"red kayak"}})
+	assert.NoError(t, err)
+	paths <- filepath.Join(config.ContentPath, first, "data.json")
+	paths <- filepath.Join(config.ContentPath, second, "data.json")
+	close(paths)
+
+	u := New(ctx, store, EbidlocalExtractor{appextract.NewAuctionItem(&appextract.Config{}), pageResults("1000", page)}, *config, nil)
+	assert.NoError(t, u.Update(paths))
+
+	for _, id := range []string{first, second} {
+		content, err := store.LoadWatchlistContent(ctx, id)
+		if assert.NoError(t, err, "The content of '%s' is saved even though its scan state is not", id) {
+			assert.Len(t, content.AuctionItems, 1)
+		}
+		state, err := store.LoadWatchlistScanState(ctx, id)
+		assert.NoError(t, err)
+		assert.True(t, state.LastScan.IsZero(), "No state is saved for '%s'", id)
+	}
+}
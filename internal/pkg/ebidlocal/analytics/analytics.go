@@ -0,0 +1,245 @@
+//Package analytics aggregates the final prices of archived items, what things usually go for. Items are grouped by the watch list entry they were found for or by a cluster of their name, see NameCluster.
+package analytics
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/match"
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/model"
+)
+
+//MinSamples the fewest items a group needs before its prices are called typical.
+const MinSamples = 3
+
+//nameClusterWords the most words of an item's name used for its cluster, the rest are usually details that keep similar items apart.
+const nameClusterWords = 4
+
+//names stems and folds the words of item names so "DeWalt Drills" and "dewalt drill" are the same cluster.
+var names = match.New(match.Options{Stem: true, Fold: true})
+
+//GroupBy how items are grouped.
+type GroupBy string
+
+const (
+	//ByKeyword groups items by the watch list entry they were found for, or the queries they matched.
+	ByKeyword GroupBy = "keyword"
+	//ByName groups items by NameCluster.
+	ByName GroupBy = "name"
+)
+
+func (g GroupBy) Validate() error {
+	switch g {
+	case ByKeyword, ByName:
+		return nil
+	}
+	return fmt.Errorf("unknown group '%s', must be '%s' or '%s'", g, ByKeyword, ByName)
+}
+
+//keys the groups the item belongs to.
+func (g GroupBy) keys(item model.ArchivedItem) []string {
+	if g == ByName {
+		if key := NameCluster(item.Item.ItemName); key != "" {
+			return []string{key}
+		}
+		return nil
+	}
+
+	var keywords = item.Item.Keywords
+	if item.Item.Keyword != "" {
+		keywords = []string{item.Item.Keyword}
+	}
+	var keys []string
+	for _, keyword := range keywords {
+		if key := strings.ToLower(strings.TrimSpace(keyword)); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+//Period the length of time the trend of a group is split into.
+type Period string
+
+const (
+	Day   Period = "day"
+	Week  Period = "week"
+	Month Period = "month"
+)
+
+func (p Period) Validate() error {
+	switch p {
+	case Day, Week, Month:
+		return nil
+	}
+	return fmt.Errorf("unknown period '%s', must be '%s', '%s' or '%s'", p, Day, Week, Month)
+}
+
+//Start the start of the period t is in, in UTC. Weeks start on Monday.
+func (p Period) Start(t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch p {
+	case Week:
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	case Month:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+	return day
+}
+
+//PriceStats what the items of a group sold for.
+type PriceStats struct {
+	Key   string `json:"key"`
+	Count int    `json:"count"`
+	//Min, Max and the percentiles of the final bids.
+	Min         float64 `json:"min"`
+	Max         float64 `json:"max"`
+	Median      float64 `json:"median"`
+	P25         float64 `json:"p25"`
+	P75         float64 `json:"p75"`
+	P90         float64 `json:"p90"`
+	AverageBids float64 `json:"averageBids"`
+	//Trend the count and median of each period items closed in, oldest first.
+	Trend []TrendPoint `json:"trend,omitempty"`
+}
+
+//TrendPoint the items of a group that closed in a period.
+type TrendPoint struct {
+	Period time.Time `json:"period"`
+	Count  int       `json:"count"`
+	Median float64   `json:"median"`
+}
+
+//Typical true if the group has enough items for its prices to mean something, see MinSamples.
+func (s *PriceStats) Typical() bool {
+	return s != nil && s.Count >= MinSamples
+}
+
+//Aggregate the price stats of each group, most items first. An empty period leaves out the trend.
+func Aggregate(items []model.ArchivedItem, groupBy GroupBy, period Period) []PriceStats {
+	var groups = make(map[string][]model.ArchivedItem)
+	for _, item := range items {
+		for _, key := range groupBy.keys(item) {
+			groups[key] = append(groups[key], item)
+		}
+	}
+
+	var stats = make([]PriceStats, 0, len(groups))
+	for key, items := range groups {
+		stats = append(stats, newPriceStats(key, items, period))
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Count != stats[j].Count {
+			return stats[i].Count > stats[j].Count
+		}
+		return stats[i].Key < stats[j].Key
+	})
+	return stats
+}
+
+func newPriceStats(key string, items []model.ArchivedItem, period Period) PriceStats {
+	var prices = make([]float64, 0, len(items))
+	var bids int
+	var periods = make(map[time.Time][]float64)
+
+	for _, item := range items {
+		prices = append(prices, item.FinalBid)
+		bids += item.TotalBids
+		if period != "" {
+			start := period.Start(item.ClosedAt)
+			periods[start] = append(periods[start], item.FinalBid)
+		}
+	}
+	sort.Float64s(prices)
+
+	var stats = PriceStats{
+		Key:         key,
+		Count:       len(prices),
+		Min:         prices[0],
+		Max:         prices[len(prices)-1],
+		Median:      Percentile(prices, 50),
+		P25:         Percentile(prices, 25),
+		P75:         Percentile(prices, 75),
+		P90:         Percentile(prices, 90),
+		AverageBids: float64(bids) / float64(len(items)),
+	}
+	for start, prices := range periods {
+		sort.Float64s(prices)
+		stats.Trend = append(stats.Trend, TrendPoint{Period: start, Count: len(prices), Median: Percentile(prices, 50)})
+	}
+	sort.Slice(stats.Trend, func(i, j int) bool {
+		return stats.Trend[i].Period.Before(stats.Trend[j].Period)
+	})
+	return stats
+}
+
+//Percentile the p-th percentile, 0 to 100, of sorted values interpolating between the closest ranks. Zero if there are no values.
+func Percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := math.Max(0, math.Min(100, p)) / 100 * float64(len(sorted)-1)
+	lower, upper := int(math.Floor(rank)), int(math.Ceil(rank))
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+}
+
+//NameCluster the first words of an item's name, stemmed, folded and sorted, without numbers or single letters. "DeWalt 20V Drill" and "Drill, Dewalt" are the cluster "dewalt drill".
+func NameCluster(name string) string {
+	var cluster []string
+	var seen = make(map[string]struct{})
+	for _, word := range names.Keywords(name) {
+		if len([]rune(word)) < 2 || strings.IndexFunc(word, unicode.IsDigit) >= 0 {
+			continue
+		}
+		if _, exists := seen[word]; exists {
+			continue
+		}
+		seen[word] = struct{}{}
+		if cluster = append(cluster, word); len(cluster) == nameClusterWords {
+			break
+		}
+	}
+	sort.Strings(cluster)
+	return strings.Join(cluster, " ")
+}
+
+//Index the price stats of every keyword and name cluster, to look up what an item usually goes for.
+type Index struct {
+	keywords map[string]*PriceStats
+	names    map[string]*PriceStats
+}
+
+func NewIndex(items []model.ArchivedItem) *Index {
+	var index = Index{keywords: make(map[string]*PriceStats), names: make(map[string]*PriceStats)}
+	for _, group := range []struct {
+		by    GroupBy
+		stats map[string]*PriceStats
+	}{{ByKeyword, index.keywords}, {ByName, index.names}} {
+		for _, stats := range Aggregate(items, group.by, "") {
+			stats := stats
+			group.stats[stats.Key] = &stats
+		}
+	}
+	return &index
+}
+
+//Typical the price stats of items like the item, its name cluster when there are enough of them otherwise its keyword. Nil if neither has enough items, see MinSamples.
+func (i *Index) Typical(item model.AuctionItem) *PriceStats {
+	if i == nil {
+		return nil
+	}
+	if stats := i.names[NameCluster(item.ItemName)]; stats.Typical() {
+		return stats
+	}
+	for _, key := range ByKeyword.keys(model.ArchivedItem{Item: item}) {
+		if stats := i.keywords[key]; stats.Typical() {
+			return stats
+		}
+	}
+	return nil
+}
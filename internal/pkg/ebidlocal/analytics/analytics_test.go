@@ -0,0 +1,89 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/model"
+)
+
+func archived(id string, name string, keyword string, price float64, bids int, closedAt time.Time) model.ArchivedItem {
+	return model.NewArchivedItem(model.AuctionItem{Id: id, ItemName: name, Keyword: keyword, CurrentBidAmount: price, TotalBids: bids}, "wl", closedAt)
+}
+
+func TestAggregate(t *testing.T) {
+	var june = time.Date(2022, 6, 10, 12, 0, 0, 0, time.UTC)
+	var july = time.Date(2022, 7, 2, 12, 0, 0, 0, time.UTC)
+	var items = []model.ArchivedItem{
+		archived("1", "DeWalt Drill 20V", "drill", 10, 2, june),
+		archived("2", "Drills, Dewalt", "Drill", 20, 4, june),
+		archived("3", "Makita drill", "drill", 30, 6, july),
+		archived("4", "Makita drill", "drill", 40, 0, july),
+		archived("5", "Table saw", "saw", 100, 1, july),
+	}
+
+	stats := Aggregate(items, ByKeyword, Month)
+	if assert.Len(t, stats, 2) {
+		drill := stats[0]
+		assert.Equal(t, "drill", drill.Key)
+		assert.Equal(t, 4, drill.Count)
+		assert.Equal(t, float64(10), drill.Min)
+		assert.Equal(t, float64(40), drill.Max)
+		assert.Equal(t, float64(25), drill.Median)
+		assert.Equal(t, float64(17.5), drill.P25)
+		assert.Equal(t, float64(3), drill.AverageBids)
+		assert.Equal(t, []TrendPoint{
+			{Period: time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC), Count: 2, Median: 15},
+			{Period: time.Date(2022, 7, 1, 0, 0, 0, 0, time.UTC), Count: 2, Median: 35},
+		}, drill.Trend)
+		assert.Equal(t, "saw", stats[1].Key)
+	}
+
+	stats = Aggregate(items, ByName, "")
+	var keys []string
+	for _, s := range stats {
+		keys = append(keys, s.Key)
+		assert.Empty(t, s.Trend)
+	}
+	assert.Equal(t, []string{"dewalt drill", "drill makita", "saw tabl"}, keys)
+}
+
+func TestPercentile(t *testing.T) {
+	assert.Equal(t, float64(0), Percentile(nil, 50))
+	assert.Equal(t, float64(7), Percentile([]float64{7}, 90))
+	assert.Equal(t, float64(2), Percentile([]float64{1, 2, 3}, 50))
+	assert.Equal(t, float64(3), Percentile([]float64{1, 2, 3}, 150))
+}
+
+func TestPeriodStart(t *testing.T) {
+	var wednesday = time.Date(2022, 6, 8, 15, 4, 5, 0, time.UTC)
+	assert.Equal(t, time.Date(2022, 6, 8, 0, 0, 0, 0, time.UTC), Day.Start(wednesday))
+	assert.Equal(t, time.Date(2022, 6, 6, 0, 0, 0, 0, time.UTC), Week.Start(wednesday))
+	assert.Equal(t, time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC), Month.Start(wednesday))
+	assert.Error(t, Period("year").Validate())
+	assert.Error(t, GroupBy("price").Validate())
+}
+
+func TestIndexTypical(t *testing.T) {
+	var now = time.Now()
+	index := NewIndex([]model.ArchivedItem{
+		archived("1", "DeWalt Drill", "drill", 10, 1, now),
+		archived("2", "Dewalt drills", "drill", 20, 1, now),
+		archived("3", "DEWALT DRILL", "drill", 30, 1, now),
+		archived("4", "Makita drill", "drill", 100, 1, now),
+	})
+
+	stats := index.Typical(model.AuctionItem{ItemName: "Dewalt drill", Keyword: "drill"})
+	if assert.NotNil(t, stats) {
+		assert.Equal(t, "dewalt drill", stats.Key, "The name cluster is preferred")
+		assert.Equal(t, float64(20), stats.Median)
+	}
+	stats = index.Typical(model.AuctionItem{ItemName: "Makita drill", Keyword: "drill"})
+	if assert.NotNil(t, stats) {
+		assert.Equal(t, "drill", stats.Key, "Too few items in the name cluster, the keyword is used")
+	}
+	assert.Nil(t, index.Typical(model.AuctionItem{ItemName: "Table saw", Keyword: "saw"}))
+	assert.Nil(t, (*Index)(nil).Typical(model.AuctionItem{ItemName: "Table saw"}))
+}
@@ -3,7 +3,9 @@ package filter
 import (
 	"strings"
 
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/match"
 	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/model"
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/query"
 	stringutils "github.com/scirelli/auction-ebidlocal-search/internal/pkg/stringUtils"
 )
 
@@ -16,3 +18,26 @@ func ByKeyword(item model.AuctionItem) bool {
 	}
 	return false
 }
+
+//ByQuery passes an item if its searchable description matches any of its keywords parsed as a query, see package query. Keywords that do not parse never match.
+func ByQuery(item model.AuctionItem) bool {
+	return matchQueries(match.New(match.Options{}), item)
+}
+
+//ByQueryMatch same as ByQuery, comparing words the way options selects.
+func ByQueryMatch(options match.Options) func(model.AuctionItem) bool {
+	var matcher = match.New(options)
+	return func(item model.AuctionItem) bool {
+		return matchQueries(matcher, item)
+	}
+}
+
+func matchQueries(matcher *match.Matcher, item model.AuctionItem) bool {
+	doc := matcher.Document(item.SearchableDescription())
+	for _, raw := range item.Keywords {
+		if q, err := query.Parse(raw); err == nil && q.MatchDocument(doc) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package filter
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/model"
+	"github.com/scirelli/auction-ebidlocal-search/test/fixtures"
+)
+
+// FuzzByKeyword seeded with the item descriptions and names of the golden captures.
+func FuzzByKeyword(f *testing.F) {
+	for _, golden := range fixtures.Corpus(f, "../../../../test/fixtures/golden/extract/*/*"+fixtures.GoldenExt) {
+		var items []struct {
+			ItemName            string `json:"itemName"`
+			Description         string `json:"description"`
+			ExtendedDescription string `json:"extendedDescription"`
+			SKUNumber           string `json:"skuNumber"`
+		}
+		if err := json.Unmarshal([]byte(golden), &items); err != nil {
+			f.Fatal(err)
+		}
+		for _, item := range items {
+			f.Add(item.ExtendedDescription, strings.Fields(item.ItemName)[0])
+			f.Add(item.Description, item.SKUNumber)
+		}
+	}
+	f.Add("", "")
+	f.Add("DeWalt drill, 20V", "drill,")
+
+	f.Fuzz(func(t *testing.T, description string, keyword string) {
+		var item = model.AuctionItem{
+			Description: description,
+			Keywords:    []string{keyword},
+		}
+		defer fixtures.CheckGoroutineLeak(t, fixtures.FuzzTimeout)()
+		var in = make(chan model.AuctionItem, 1)
+		in <- item
+		close(in)
+		passed := fixtures.ReceiveAll(t, model.FilterAuctionItemChan(in).Filter(model.FilterFunc(ByKeyword)), fixtures.FuzzTimeout)
+		if len(passed) != 0 && !ByKeyword(item) {
+			t.Errorf("Item passed the channel filter but not ByKeyword '%v'", item)
+		}
+
+		if ByKeyword(model.AuctionItem{Description: description}) {
+			t.Errorf("An item without keywords should never pass '%s'", description)
+		}
+
+		//A keyword that is a single word with no punctuation must always match itself.
+		if keyword != "" && strings.IndexFunc(keyword, func(r rune) bool {
+			return unicode.IsSpace(r) || (r < unicode.MaxASCII && unicode.IsPunct(r)) || (r < unicode.MaxASCII && unicode.IsSymbol(r))
+		}) < 0 {
+			item.ItemName = keyword
+			if !ByKeyword(item) {
+				t.Errorf("Expected keyword '%s' to match itself", keyword)
+			}
+		}
+	})
+}
@@ -0,0 +1,52 @@
+package filter
+
+import (
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/model"
+)
+
+//All passes an item if every filter passes it. All of no filters passes every item.
+func All(filters ...model.Filterer) model.Filterer {
+	return model.FilterFunc(func(item model.AuctionItem) bool {
+		for _, f := range filters {
+			if !f.Filter(item) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+//Any passes an item if one of the filters passes it. Any of no filters passes nothing.
+func Any(filters ...model.Filterer) model.Filterer {
+	return model.FilterFunc(func(item model.AuctionItem) bool {
+		for _, f := range filters {
+			if f.Filter(item) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+//Not passes the items filter does not.
+func Not(filter model.Filterer) model.Filterer {
+	return model.FilterFunc(func(item model.AuctionItem) bool {
+		return !filter.Filter(item)
+	})
+}
+
+//Pipeline filters applied one after another, an item is kept if it makes it through every stage.
+type Pipeline []model.Filterer
+
+//Filter implements Filterer, same as All.
+func (p Pipeline) Filter(item model.AuctionItem) bool {
+	return All(p...).Filter(item)
+}
+
+//Chan chains a FilterAuctionItemChan per stage, each stage runs in its own go routine.
+func (p Pipeline) Chan(in <-chan model.AuctionItem) <-chan model.AuctionItem {
+	for _, f := range p {
+		in = model.FilterAuctionItemChan(in).Filter(f)
+	}
+	return in
+}
@@ -0,0 +1,154 @@
+package filter
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/match"
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/model"
+)
+
+//Factory builds a filter from its parameters. Options are the options of the watch list the filter is built for.
+type Factory func(options model.WatchlistOptions, params json.RawMessage) (model.Filterer, error)
+
+//DefaultPipeline the filters of a watch list that does not declare any, items matching their query that are within the watch list's constraints.
+var DefaultPipeline = []model.FilterSpec{{Name: "query"}, {Name: "constraints"}}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Factory)
+)
+
+func init() {
+	Register("keyword", func(options model.WatchlistOptions, params json.RawMessage) (model.Filterer, error) {
+		return model.FilterFunc(ByKeyword), nil
+	})
+	//query params, if any, are match options used in place of the watch list's.
+	Register("query", func(options model.WatchlistOptions, params json.RawMessage) (model.Filterer, error) {
+		var matchOptions = options.Match
+		if hasParams(params) {
+			matchOptions = match.Options{}
+			if err := json.Unmarshal(params, &matchOptions); err != nil {
+				return nil, fmt.Errorf("invalid params; %w", err)
+			}
+		}
+		if err := matchOptions.Validate(); err != nil {
+			return nil, err
+		}
+		return model.FilterFunc(ByQueryMatch(matchOptions)), nil
+	})
+	//constraints params, if any, are constraints used in place of the watch list's.
+	Register("constraints", func(options model.WatchlistOptions, params json.RawMessage) (model.Filterer, error) {
+		var constraints = options.Constraints
+		if hasParams(params) {
+			constraints = model.WatchlistConstraints{}
+			if err := json.Unmarshal(params, &constraints); err != nil {
+				return nil, fmt.Errorf("invalid params; %w", err)
+			}
+		}
+		if err := constraints.Validate(); err != nil {
+			return nil, err
+		}
+		return *constraints.Normalize(), nil
+	})
+	Register("all", combinator(All))
+	Register("any", combinator(Any))
+	Register("not", func(options model.WatchlistOptions, params json.RawMessage) (model.Filterer, error) {
+		var spec model.FilterSpec
+		if err := json.Unmarshal(params, &spec); err != nil {
+			return nil, fmt.Errorf("params must be a filter; %w", err)
+		}
+		f, err := Build(options, spec)
+		if err != nil {
+			return nil, err
+		}
+		return Not(f), nil
+	})
+}
+
+//Register makes a filter available by name to watch list pipelines. Registering the same name twice, or a nil factory, panics.
+func Register(name string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if factory == nil {
+		panic("filter: Register factory is nil")
+	}
+	if _, exists := registry[name]; exists {
+		panic("filter: Register called twice for filter " + name)
+	}
+	registry[name] = factory
+}
+
+//Registered the sorted names of the registered filters.
+func Registered() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	var names = make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+//Build the filter spec names for a watch list with options.
+func Build(options model.WatchlistOptions, spec model.FilterSpec) (model.Filterer, error) {
+	registryMu.RLock()
+	factory, exists := registry[spec.Name]
+	registryMu.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("unknown filter '%s'", spec.Name)
+	}
+	f, err := factory(options, spec.Params)
+	if err != nil {
+		return nil, fmt.Errorf("filter '%s': %w", spec.Name, err)
+	}
+	return f, nil
+}
+
+//BuildPipeline the pipeline declared by a watch list's options, or the DefaultPipeline when it declares none.
+func BuildPipeline(options model.WatchlistOptions) (Pipeline, error) {
+	var specs = options.Filters
+	if len(specs) == 0 {
+		specs = DefaultPipeline
+	}
+
+	var pipeline = make(Pipeline, 0, len(specs))
+	for _, spec := range specs {
+		f, err := Build(options, spec)
+		if err != nil {
+			return nil, err
+		}
+		pipeline = append(pipeline, f)
+	}
+	return pipeline, nil
+}
+
+//combinator a factory whose params are a list of filters that are combined.
+func combinator(combine func(...model.Filterer) model.Filterer) Factory {
+	return func(options model.WatchlistOptions, params json.RawMessage) (model.Filterer, error) {
+		var specs []model.FilterSpec
+		if err := json.Unmarshal(params, &specs); err != nil {
+			return nil, fmt.Errorf("params must be a list of filters; %w", err)
+		}
+
+		var filters = make([]model.Filterer, 0, len(specs))
+		for _, spec := range specs {
+			f, err := Build(options, spec)
+			if err != nil {
+				return nil, err
+			}
+			filters = append(filters, f)
+		}
+		return combine(filters...), nil
+	}
+}
+
+func hasParams(params json.RawMessage) bool {
+	return len(params) > 0 && string(params) != "null"
+}
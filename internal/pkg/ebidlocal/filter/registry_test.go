@@ -0,0 +1,121 @@
+package filter
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/model"
+)
+
+func float(f float64) *float64 {
+	return &f
+}
+
+func TestCombinators(t *testing.T) {
+	var yes = model.FilterFunc(func(model.AuctionItem) bool { return true })
+	var no = model.FilterFunc(func(model.AuctionItem) bool { return false })
+	var item model.AuctionItem
+
+	assert.True(t, All().Filter(item))
+	assert.True(t, All(yes, yes).Filter(item))
+	assert.False(t, All(yes, no).Filter(item))
+	assert.False(t, Any().Filter(item))
+	assert.True(t, Any(no, yes).Filter(item))
+	assert.False(t, Any(no, no).Filter(item))
+	assert.True(t, Not(no).Filter(item))
+	assert.False(t, Not(yes).Filter(item))
+}
+
+func TestPipelineChan(t *testing.T) {
+	var in = make(chan model.AuctionItem, 3)
+	in <- model.AuctionItem{Id: "1", Description: "Kenmore dryer", Keywords: []string{"dryer"}, CurrentBidAmount: 10}
+	in <- model.AuctionItem{Id: "2", Description: "Kenmore dryer", Keywords: []string{"dryer"}, CurrentBidAmount: 100}
+	in <- model.AuctionItem{Id: "3", Description: "Kenmore washer", Keywords: []string{"dryer"}, CurrentBidAmount: 10}
+	close(in)
+
+	pipeline, err := BuildPipeline(model.WatchlistOptions{Constraints: model.WatchlistConstraints{CurrentBid: model.Range{Max: float(50)}}})
+	assert.NoError(t, err)
+
+	var ids []string
+	for item := range pipeline.Chan(in) {
+		ids = append(ids, item.Id)
+	}
+	assert.Equal(t, []string{"1"}, ids, "The default pipeline matches the query then the constraints")
+}
+
+func TestBuildPipeline(t *testing.T) {
+	var dryer = model.AuctionItem{Description: "Kenmore dryer, like new", Keywords: []string{"dryer"}, StatusCode: "open"}
+	var broken = model.AuctionItem{Description: "Kenmore dryer, broken", Keywords: []string{"dryer"}, StatusCode: "open"}
+	var closed = model.AuctionItem{Description: "Kenmore dryer", Keywords: []string{"dryer"}, StatusCode: "closed"}
+
+	var tests = []struct {
+		Name    string
+		Filters string
+		Passed  []model.AuctionItem
+		Failed  []model.AuctionItem
+	}{
+		{
+			Name:    "Keyword",
+			Filters: `[{"name":"keyword"}]`,
+			Passed:  []model.AuctionItem{dryer, broken, closed},
+		},
+		{
+			Name:    "Not",
+			Filters: `[{"name":"query"},{"name":"not","params":{"name":"query","params":{"stem":true}}}]`,
+			Failed:  []model.AuctionItem{dryer},
+		},
+		{
+			Name:    "Constraints params",
+			Filters: `[{"name":"query"},{"name":"constraints","params":{"statusCodes":["Open"]}}]`,
+			Passed:  []model.AuctionItem{dryer, broken},
+			Failed:  []model.AuctionItem{closed},
+		},
+		{
+			Name:    "Any and all",
+			Filters: `[{"name":"any","params":[{"name":"constraints","params":{"statusCodes":["closed"]}},{"name":"all","params":[{"name":"keyword"},{"name":"not","params":{"name":"keyword"}}]}]}]`,
+			Passed:  []model.AuctionItem{closed},
+			Failed:  []model.AuctionItem{dryer, broken},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			var options model.WatchlistOptions
+			assert.NoError(t, json.Unmarshal([]byte(test.Filters), &options.Filters))
+			pipeline, err := BuildPipeline(options)
+			assert.NoError(t, err)
+			for _, item := range test.Passed {
+				assert.True(t, pipeline.Filter(item), item.Description)
+			}
+			for _, item := range test.Failed {
+				assert.False(t, pipeline.Filter(item), item.Description)
+			}
+		})
+	}
+}
+
+func TestBuildPipelineErrors(t *testing.T) {
+	for _, filters := range []string{
+		`[{"name":"nope"}]`,
+		`[{"name":"all","params":{"name":"keyword"}}]`,
+		`[{"name":"not","params":[{"name":"keyword"}]}]`,
+		`[{"name":"any","params":[{"name":"nope"}]}]`,
+		`[{"name":"query","params":{"fuzzy":5}}]`,
+		`[{"name":"constraints","params":{"currentBid":{"min":10,"max":1}}}]`,
+	} {
+		var options model.WatchlistOptions
+		assert.NoError(t, json.Unmarshal([]byte(filters), &options.Filters))
+		_, err := BuildPipeline(options)
+		assert.Error(t, err, filters)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	assert.Panics(t, func() {
+		Register("keyword", func(model.WatchlistOptions, json.RawMessage) (model.Filterer, error) { return nil, nil })
+	})
+	assert.Panics(t, func() { Register("nil", nil) })
+	assert.Contains(t, Registered(), "query")
+}
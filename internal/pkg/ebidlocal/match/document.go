@@ -0,0 +1,83 @@
+package match
+
+//Form a normalized word found at a position of a Document. Words joined by splitting span more than one position.
+type Form struct {
+	Word string
+	Span int
+}
+
+type forms []Form
+
+func (f forms) has(word string) bool {
+	for _, form := range f {
+		if form.Word == word {
+			return true
+		}
+	}
+	return false
+}
+
+//Document the normalized words of a text, see Matcher.Document.
+type Document struct {
+	matcher   *Matcher
+	positions []forms
+	raw       []string
+	text      string
+}
+
+//Contains true if the normalized keywords appear in order, next to each other, in the document. fuzzy overrides the matcher's Options.Fuzzy when it is not negative.
+func (d *Document) Contains(keywords []string, fuzzy int) bool {
+	if len(keywords) == 0 {
+		return false
+	}
+	if fuzzy < 0 {
+		fuzzy = d.matcher.Options.Fuzzy
+	}
+	for i := range d.positions {
+		if d.containsAt(i, keywords, fuzzy) {
+			return true
+		}
+	}
+	return false
+}
+
+//Positions the positions in the document where the keywords start, see Contains.
+func (d *Document) Positions(keywords []string, fuzzy int) (positions []int) {
+	if len(keywords) == 0 {
+		return nil
+	}
+	if fuzzy < 0 {
+		fuzzy = d.matcher.Options.Fuzzy
+	}
+	for i := range d.positions {
+		if d.containsAt(i, keywords, fuzzy) {
+			positions = append(positions, i)
+		}
+	}
+	return positions
+}
+
+func (d *Document) containsAt(pos int, keywords []string, fuzzy int) bool {
+	if len(keywords) == 0 {
+		return true
+	}
+	if pos >= len(d.positions) {
+		return false
+	}
+	for _, form := range d.positions[pos] {
+		if d.matcher.Equal(keywords[0], form.Word, fuzzy) && d.containsAt(pos+form.Span, keywords[1:], fuzzy) {
+			return true
+		}
+	}
+	return false
+}
+
+//Text the text the document was made from, before it was normalized.
+func (d *Document) Text() string {
+	return d.text
+}
+
+//Matcher the matcher that normalized the document, keywords must be normalized by it too.
+func (d *Document) Matcher() *Matcher {
+	return d.matcher
+}
@@ -0,0 +1,108 @@
+//Package match compares keywords to the words of an item's description. How words are compared, stemming, folding, splitting and fuzzy matching, is selected with Options.
+package match
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/kljensen/snowball/english"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+
+	stringutils "github.com/scirelli/auction-ebidlocal-search/internal/pkg/stringUtils"
+)
+
+//Matcher normalizes keywords and text the same way so they can be compared.
+type Matcher struct {
+	Options Options
+}
+
+func New(options Options) *Matcher {
+	return &Matcher{
+		Options: options,
+	}
+}
+
+//Keyword normalizes a single keyword. Punctuation is removed so "de-walt" is the keyword "dewalt", which matches "De-Walt", "De Walt" and "DeWalt" when splitting is on.
+func (m *Matcher) Keyword(keyword string) string {
+	return m.normalize(strings.Join(stringutils.StripPunctuation([]string{m.fold(keyword)}), ""))
+}
+
+//Keywords normalizes each word of a phrase.
+func (m *Matcher) Keywords(phrase string) []string {
+	var words []string
+	for _, w := range strings.Fields(phrase) {
+		if k := m.Keyword(w); k != "" {
+			words = append(words, k)
+		}
+	}
+	return words
+}
+
+//Document splits text into normalized words to be searched for keywords.
+func (m *Matcher) Document(text string) *Document {
+	var doc = Document{matcher: m, text: text}
+
+	for _, field := range strings.Fields(m.fold(text)) {
+		var parts []string
+		if m.Options.Split {
+			parts = strings.FieldsFunc(field, isSeparator)
+		} else if w := strings.Join(stringutils.StripPunctuation([]string{field}), ""); w != "" {
+			parts = []string{w}
+		}
+		if len(parts) == 0 {
+			continue
+		}
+
+		start := len(doc.positions)
+		for _, p := range parts {
+			doc.positions = append(doc.positions, []Form{{Word: m.normalize(p), Span: 1}})
+		}
+		if len(parts) > 1 {
+			doc.positions[start] = append(doc.positions[start], Form{Word: m.normalize(strings.Join(parts, "")), Span: len(parts)})
+		}
+		doc.raw = append(doc.raw, parts...)
+	}
+
+	if m.Options.Split {
+		//Neighbouring words joined, "WESTING HOUSE" is also "westinghouse".
+		for i := 0; i+1 < len(doc.raw); i++ {
+			joined := m.normalize(doc.raw[i] + doc.raw[i+1])
+			if !doc.positions[i].has(joined) {
+				doc.positions[i] = append(doc.positions[i], Form{Word: joined, Span: 2})
+			}
+		}
+	}
+
+	return &doc
+}
+
+//Equal true if the normalized keyword and word are the same, or within the allowed number of edits of each other.
+func (m *Matcher) Equal(keyword string, word string, fuzzy int) bool {
+	if keyword == word {
+		return true
+	}
+	edits := Edits(keyword, fuzzy)
+	return edits > 0 && Distance(keyword, word, edits) <= edits
+}
+
+func (m *Matcher) fold(s string) string {
+	if !m.Options.Fold {
+		return strings.ToLower(s)
+	}
+	s, _, _ = transform.String(transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC), s)
+	return cases.Fold().String(s)
+}
+
+func (m *Matcher) normalize(word string) string {
+	if m.Options.Stem {
+		return english.Stem(word, false)
+	}
+	return word
+}
+
+func isSeparator(r rune) bool {
+	return unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r)
+}
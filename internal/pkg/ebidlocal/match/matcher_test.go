@@ -0,0 +1,171 @@
+package match
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//titles real item titles scraped from the auction site fixtures.
+const titles = "../../../../test/fixtures/match/titles.txt"
+
+func loadTitles(t *testing.T) []string {
+	t.Helper()
+	b, err := ioutil.ReadFile(titles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Split(strings.TrimSpace(string(b)), "\n")
+}
+
+//matches the titles containing the keywords.
+func matches(m *Matcher, titles []string, keywords string) (found []string) {
+	for _, title := range titles {
+		if m.Document(title).Contains(m.Keywords(keywords), -1) {
+			found = append(found, title)
+		}
+	}
+	return found
+}
+
+func TestMatcherCorpus(t *testing.T) {
+	var corpus = loadTitles(t)
+	var tests = []struct {
+		Name     string
+		Options  Options
+		Keywords string
+		Expected []string
+	}{
+		{
+			Name:     "Exact match is unchanged",
+			Keywords: "binoculars",
+			Expected: []string{
+				"2 PAIRS OF BINOCULARS, TASCO EXTRA WIDE MODEL 118 80859 AND FOCAL 7X50 WIDE VIEW",
+				"2 PAIRS OF BINOCULARS, BUSHNELL 10X25, TASCO 223CRZ 10X50, OVO VINTAGE 6X15",
+			},
+		},
+		{
+			Name:     "Exact match misses plurals",
+			Keywords: "binocular",
+			Expected: nil,
+		},
+		{
+			Name:     "Stemming matches plurals",
+			Options:  Options{Stem: true},
+			Keywords: "binocular",
+			Expected: []string{
+				"2 PAIRS OF BINOCULARS, TASCO EXTRA WIDE MODEL 118 80859 AND FOCAL 7X50 WIDE VIEW",
+				"2 PAIRS OF BINOCULARS, BUSHNELL 10X25, TASCO 223CRZ 10X50, OVO VINTAGE 6X15",
+			},
+		},
+		{
+			Name:     "Stemming matches other forms of a word",
+			Options:  Options{Stem: true},
+			Keywords: "crimp",
+			Expected: []string{"HYDRAULIC CRIMPING HEAD"},
+		},
+		{
+			Name:     "Exact match misses accents",
+			Keywords: "pokemon",
+			Expected: nil,
+		},
+		{
+			Name:     "Folding ignores accents",
+			Options:  Options{Fold: true},
+			Keywords: "pokemon",
+			Expected: []string{"UNOPENED POKÉMON TRADING CARDS"},
+		},
+		{
+			Name:     "Exact match joins hyphenated words",
+			Keywords: "crousehinds",
+			Expected: []string{"CROUSE-HINDS NEW IN BOX EXIT SIGNS"},
+		},
+		{
+			Name:     "Splitting matches part of a hyphenated word",
+			Options:  Options{Split: true},
+			Keywords: "hinds",
+			Expected: []string{"CROUSE-HINDS NEW IN BOX EXIT SIGNS"},
+		},
+		{
+			Name:     "Splitting matches a hyphenated keyword",
+			Options:  Options{Split: true},
+			Keywords: "crouse-hinds",
+			Expected: []string{"CROUSE-HINDS NEW IN BOX EXIT SIGNS"},
+		},
+		{
+			Name:     "Splitting joins neighbouring words",
+			Options:  Options{Split: true},
+			Keywords: "westinghouse",
+			Expected: []string{"WESTING HOUSE MOTOR CONTROL UNITS AND ASSORTED BREAKERS"},
+		},
+		{
+			Name:     "Splitting joined words keep their place in a phrase",
+			Options:  Options{Split: true},
+			Keywords: "true temper wheelbarrow",
+			Expected: []string{"TRUE-TEMPER WHEEL BARROW"},
+		},
+		{
+			Name:     "Fuzzy matches typos",
+			Options:  Options{Fuzzy: 1},
+			Keywords: "labonte",
+			Expected: []string{"BOX OF NASCAR COLLECTOR CARDS MOST IN SLEEVE, BOBBY LABONGE, MARTIN TRUEX AND MORE"},
+		},
+		{
+			Name:     "Fuzzy does not apply to short keywords",
+			Options:  Options{Fuzzy: 2},
+			Keywords: "tin",
+			Expected: []string{
+				"US NAVY RADIO HANDSETS GLASS CHIMNEY BATTERY CHARGERS AND FLOWER TIN",
+				"HARRY POTTER WATCH IN TIN",
+			},
+		},
+		{
+			Name:     "All options",
+			Options:  Options{Stem: true, Fold: true, Split: true, Fuzzy: 1},
+			Keywords: "pokemon trading card",
+			Expected: []string{"UNOPENED POKÉMON TRADING CARDS"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			assert.Equal(t, test.Expected, matches(New(test.Options), corpus, test.Keywords))
+		})
+	}
+}
+
+func TestDistance(t *testing.T) {
+	var tests = []struct {
+		A, B     string
+		Max      int
+		Expected int
+	}{
+		{A: "kayak", B: "kayak", Max: 2, Expected: 0},
+		{A: "kayak", B: "kayk", Max: 2, Expected: 1},
+		{A: "kayak", B: "kayka", Max: 2, Expected: 2},
+		{A: "kayak", B: "canoe", Max: 2, Expected: 3},
+		{A: "kayak", B: "k", Max: 2, Expected: 3},
+		{A: "pokémon", B: "pokemon", Max: 1, Expected: 1},
+		{A: "", B: "ab", Max: 2, Expected: 2},
+	}
+
+	for _, test := range tests {
+		assert.Equalf(t, test.Expected, Distance(test.A, test.B, test.Max), "Distance('%s', '%s', %d)", test.A, test.B, test.Max)
+	}
+}
+
+func TestEdits(t *testing.T) {
+	assert.Equal(t, 0, Edits("kayak", 0))
+	assert.Equal(t, 0, Edits("tin", 2))
+	assert.Equal(t, 1, Edits("kayak", 2))
+	assert.Equal(t, 2, Edits("refrigerator", 2))
+	assert.Equal(t, 1, Edits("refrigerator", 1))
+}
+
+func TestOptionsValidate(t *testing.T) {
+	assert.NoError(t, Options{Fuzzy: MaxFuzzy}.Validate())
+	assert.Error(t, Options{Fuzzy: MaxFuzzy + 1}.Validate())
+	assert.Error(t, Options{Fuzzy: -1}.Validate())
+}
@@ -0,0 +1,46 @@
+package match
+
+import (
+	"fmt"
+)
+
+//MaxFuzzy the most edits a keyword may be from a word and still match it.
+const MaxFuzzy = 2
+
+//Options selects how keywords are compared to the words of an item. The zero value compares lower cased words with punctuation removed, the way watch lists always have been matched.
+type Options struct {
+	//Stem compares English word stems so "drill" matches "drills" and "drilling".
+	Stem bool `json:"stem,omitempty"`
+	//Fold ignores case and accents using Unicode folding so "pokemon" matches "POKÉMON".
+	Fold bool `json:"fold,omitempty"`
+	//Split breaks words on hyphens and other punctuation and joins neighbouring words, so "hinds" matches "CROUSE-HINDS" and "westinghouse" matches "WESTING HOUSE".
+	Split bool `json:"split,omitempty"`
+	//Fuzzy the number of typos, edits, allowed between a keyword and a word. Short keywords allow fewer edits, see Edits.
+	Fuzzy int `json:"fuzzy,omitempty"`
+}
+
+func (o Options) IsZero() bool {
+	return o == Options{}
+}
+
+func (o Options) Validate() error {
+	if o.Fuzzy < 0 || o.Fuzzy > MaxFuzzy {
+		return fmt.Errorf("fuzzy must be between 0 and %d", MaxFuzzy)
+	}
+	return nil
+}
+
+//Edits the number of edits allowed for a keyword. Keywords under 4 characters must match exactly and keywords under 8 characters may have at most one edit, otherwise short words match almost anything.
+func Edits(keyword string, fuzzy int) int {
+	var l = len([]rune(keyword))
+
+	switch {
+	case fuzzy <= 0 || l < 4:
+		return 0
+	case l < 8:
+		return 1
+	case fuzzy > MaxFuzzy:
+		return MaxFuzzy
+	}
+	return fuzzy
+}
@@ -0,0 +1,189 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/query"
+)
+
+//ArchivedItem an item whose auction closed and the last price it was seen at, so what things actually sell for is not lost when the item drops out of a watch list.
+type ArchivedItem struct {
+	//Item the item as it was last seen.
+	Item AuctionItem `json:"item"`
+	//FinalBid the last bid seen on the item, its sale price if nobody bid after it was last searched.
+	FinalBid  float64 `json:"finalBid"`
+	TotalBids int     `json:"totalBids"`
+	//ClosedAt when the auction ended, the time it was found closed if the item has no end date.
+	ClosedAt time.Time `json:"closedAt"`
+	//WatchlistIDs the watch lists the item was found by.
+	WatchlistIDs []string `json:"watchlistIDs,omitempty"`
+}
+
+//NewArchivedItem archives an item last seen by the watch list, closedAt is used when the item has no end date.
+func NewArchivedItem(item AuctionItem, watchlistID string, closedAt time.Time) ArchivedItem {
+	if !item.EndDate.IsZero() {
+		closedAt = item.EndDate
+	}
+	return ArchivedItem{
+		Item:         item,
+		FinalBid:     item.CurrentBidAmount,
+		TotalBids:    item.TotalBids,
+		ClosedAt:     closedAt,
+		WatchlistIDs: []string{watchlistID},
+	}
+}
+
+func (a *ArchivedItem) ID() string {
+	return a.Item.ID()
+}
+
+//Merge the same item archived again, by another watch list or a later search. The item with the most bids wins, an item's bids never go down, and the watch lists of both are kept.
+func (a *ArchivedItem) Merge(other ArchivedItem) {
+	var watchlistIDs = a.WatchlistIDs
+	if other.TotalBids > a.TotalBids || (other.TotalBids == a.TotalBids && other.FinalBid >= a.FinalBid) {
+		a.Item, a.FinalBid, a.TotalBids, a.ClosedAt = other.Item, other.FinalBid, other.TotalBids, other.ClosedAt
+	}
+	for _, id := range other.WatchlistIDs {
+		if !containsString(watchlistIDs, id) {
+			watchlistIDs = append(watchlistIDs, id)
+		}
+	}
+	sort.Strings(watchlistIDs)
+	a.WatchlistIDs = watchlistIDs
+}
+
+//ClosedAuctionItems the items whose auction closed by now. An item is closed when its end date has passed, or when it dropped out of the content and another item of its auction has ended. Items that dropped out of an auction still running only stopped matching. The items are as they were last seen.
+func ClosedAuctionItems(previous *WatchlistContent, current *WatchlistContent, now time.Time) []AuctionItem {
+	var closed []AuctionItem
+	var endedAuctions = make(map[string]struct{})
+	var found = make(map[string]struct{}, len(current.AuctionItems))
+
+	ended := func(item AuctionItem) bool {
+		return !item.EndDate.IsZero() && !item.EndDate.After(now)
+	}
+	var previousItems []AuctionItem
+	if previous != nil {
+		previousItems = previous.AuctionItems
+	}
+	for _, items := range [][]AuctionItem{previousItems, current.AuctionItems} {
+		for _, item := range items {
+			if ended(item) && item.ParentAuctionID != "" {
+				endedAuctions[item.ParentAuctionID] = struct{}{}
+			}
+		}
+	}
+
+	for _, item := range current.AuctionItems {
+		found[item.ID()] = struct{}{}
+		if ended(item) {
+			closed = append(closed, item)
+		}
+	}
+	for _, item := range previousItems {
+		if _, exists := found[item.ID()]; exists {
+			continue
+		}
+		_, auctionEnded := endedAuctions[item.ParentAuctionID]
+		if ended(item) || (item.EndDate.IsZero() && auctionEnded) {
+			closed = append(closed, item)
+		}
+	}
+
+	return closed
+}
+
+//ArchiveQuery selects archived items. Zero fields select everything.
+type ArchiveQuery struct {
+	//Keyword a query in the watch list query language matched against the item's name, descriptions and SKU, see package query.
+	Keyword string `json:"keyword,omitempty"`
+	//From and To the range ClosedAt falls in, To is exclusive.
+	From time.Time `json:"from,omitempty"`
+	To   time.Time `json:"to,omitempty"`
+	//MinPrice and MaxPrice the range FinalBid falls in, inclusive.
+	MinPrice float64 `json:"minPrice,omitempty"`
+	MaxPrice float64 `json:"maxPrice,omitempty"`
+	//Limit the most items returned, zero returns them all.
+	Limit int `json:"limit,omitempty"`
+}
+
+//Validate the keyword must parse and the ranges must not be backwards.
+func (q ArchiveQuery) Validate() error {
+	if q.Keyword != "" {
+		if err := query.Validate(q.Keyword); err != nil {
+			return err
+		}
+	}
+	if !q.From.IsZero() && !q.To.IsZero() && !q.From.Before(q.To) {
+		return fmt.Errorf("from must be before to")
+	}
+	if q.MinPrice < 0 || q.MaxPrice < 0 {
+		return fmt.Errorf("prices can not be negative")
+	}
+	if q.MaxPrice > 0 && q.MinPrice > q.MaxPrice {
+		return fmt.Errorf("minimum price is more than the maximum price")
+	}
+	if q.Limit < 0 {
+		return fmt.Errorf("limit can not be negative")
+	}
+	return nil
+}
+
+//Matcher a function that is true for the archived items the query selects. The query must be valid.
+func (q ArchiveQuery) Matcher() (func(ArchivedItem) bool, error) {
+	var keyword *query.Query
+	if q.Keyword != "" {
+		var err error
+		if keyword, err = query.Parse(q.Keyword); err != nil {
+			return nil, err
+		}
+	}
+
+	return func(item ArchivedItem) bool {
+		if !q.From.IsZero() && item.ClosedAt.Before(q.From) {
+			return false
+		}
+		if !q.To.IsZero() && !item.ClosedAt.Before(q.To) {
+			return false
+		}
+		if item.FinalBid < q.MinPrice || (q.MaxPrice > 0 && item.FinalBid > q.MaxPrice) {
+			return false
+		}
+		return keyword == nil || keyword.Match(item.Item.SearchableDescription())
+	}, nil
+}
+
+//SelectArchivedItems the items the query selects, most recently closed first, at most q.Limit of them.
+func SelectArchivedItems(items []ArchivedItem, q ArchiveQuery) ([]ArchivedItem, error) {
+	var selected = []ArchivedItem{}
+
+	matches, err := q.Matcher()
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		if matches(item) {
+			selected = append(selected, item)
+		}
+	}
+	sort.SliceStable(selected, func(i, j int) bool {
+		if !selected[i].ClosedAt.Equal(selected[j].ClosedAt) {
+			return selected[i].ClosedAt.After(selected[j].ClosedAt)
+		}
+		return selected[i].ID() < selected[j].ID()
+	})
+	if q.Limit > 0 && len(selected) > q.Limit {
+		selected = selected[:q.Limit]
+	}
+	return selected, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClosedAuctionItems(t *testing.T) {
+	var now = time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	var previous = &WatchlistContent{WatchlistID: "wl", AuctionItems: []AuctionItem{
+		{Id: "ended", ParentAuctionID: "a", EndDate: now.Add(-time.Hour), CurrentBidAmount: 40},
+		{Id: "no-end-date", ParentAuctionID: "a", CurrentBidAmount: 15},
+		{Id: "still-running", ParentAuctionID: "b", EndDate: now.Add(time.Hour)},
+		{Id: "stopped-matching", ParentAuctionID: "b"},
+		{Id: "listed", ParentAuctionID: "c", EndDate: now.Add(-time.Minute), CurrentBidAmount: 3},
+	}}
+	var current = &WatchlistContent{WatchlistID: "wl", Timestamp: now, AuctionItems: []AuctionItem{
+		{Id: "still-running", ParentAuctionID: "b", EndDate: now.Add(time.Hour)},
+		{Id: "listed", ParentAuctionID: "c", EndDate: now.Add(-time.Minute), CurrentBidAmount: 5},
+	}}
+
+	var ids []string
+	for _, item := range ClosedAuctionItems(previous, current, now) {
+		ids = append(ids, item.ID())
+		if item.ID() == "listed" {
+			assert.Equal(t, float64(5), item.CurrentBidAmount, "Items still listed are as they were last seen")
+		}
+	}
+	assert.ElementsMatch(t, []string{"ended", "no-end-date", "listed"}, ids)
+	assert.Empty(t, ClosedAuctionItems(nil, &WatchlistContent{AuctionItems: []AuctionItem{{Id: "new", EndDate: now.Add(time.Hour)}}}, now))
+}
+
+func TestArchivedItemMerge(t *testing.T) {
+	var closedAt = time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	var item = NewArchivedItem(AuctionItem{Id: "1", CurrentBidAmount: 10, TotalBids: 2}, "wl2", closedAt)
+	assert.Equal(t, closedAt, item.ClosedAt)
+
+	item.Merge(NewArchivedItem(AuctionItem{Id: "1", CurrentBidAmount: 8, TotalBids: 1}, "wl1", closedAt))
+	assert.Equal(t, float64(10), item.FinalBid, "An older sighting does not replace the final bid")
+	assert.Equal(t, []string{"wl1", "wl2"}, item.WatchlistIDs)
+
+	item.Merge(NewArchivedItem(AuctionItem{Id: "1", CurrentBidAmount: 12, TotalBids: 3, EndDate: closedAt.Add(time.Minute)}, "wl2", closedAt))
+	assert.Equal(t, float64(12), item.FinalBid)
+	assert.Equal(t, 3, item.TotalBids)
+	assert.Equal(t, closedAt.Add(time.Minute), item.ClosedAt)
+	assert.Equal(t, []string{"wl1", "wl2"}, item.WatchlistIDs)
+}
+
+func TestSelectArchivedItems(t *testing.T) {
+	var day = time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	var items = []ArchivedItem{
+		{Item: AuctionItem{Id: "1", ItemName: "Dewalt drill"}, FinalBid: 45, ClosedAt: day},
+		{Item: AuctionItem{Id: "2", ItemName: "Makita drill"}, FinalBid: 30, ClosedAt: day.Add(24 * time.Hour)},
+		{Item: AuctionItem{Id: "3", ItemName: "Dewalt saw"}, FinalBid: 60, ClosedAt: day.Add(48 * time.Hour)},
+	}
+
+	ids := func(q ArchiveQuery) []string {
+		var ids = []string{}
+		selected, err := SelectArchivedItems(items, q)
+		assert.NoError(t, err)
+		for _, item := range selected {
+			ids = append(ids, item.ID())
+		}
+		return ids
+	}
+	assert.Equal(t, []string{"3", "2", "1"}, ids(ArchiveQuery{}), "Most recently closed first")
+	assert.Equal(t, []string{"2", "1"}, ids(ArchiveQuery{Keyword: "drill"}))
+	assert.Equal(t, []string{"1"}, ids(ArchiveQuery{Keyword: "dewalt -saw"}))
+	assert.Equal(t, []string{"2"}, ids(ArchiveQuery{From: day.Add(time.Hour), To: day.Add(48 * time.Hour)}))
+	assert.Equal(t, []string{"1"}, ids(ArchiveQuery{MinPrice: 40, MaxPrice: 50}))
+	assert.Equal(t, []string{"3"}, ids(ArchiveQuery{Limit: 1}))
+
+	assert.Error(t, ArchiveQuery{Keyword: "-drill"}.Validate())
+	assert.Error(t, ArchiveQuery{From: day, To: day}.Validate())
+	assert.Error(t, ArchiveQuery{MinPrice: 10, MaxPrice: 5}.Validate())
+	assert.NoError(t, ArchiveQuery{MinPrice: 10}.Validate())
+}
@@ -3,6 +3,7 @@ package model
 import (
 	"fmt"
 	"net/url"
+	"sort"
 	"time"
 
 	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/id"
@@ -44,6 +45,9 @@ type AuctionItem struct {
 	ReservePrice         int        `json:"reservePrice,omitempty"`
 	BidAmount            float64    `json:"bidAmount,omitempty"`
 	OriginalName         string     `json:"originalName,omitempty"`
+	Score                float64    `json:"score,omitempty"`
+	//Keyword the watch list entry, as it was typed, the item was found for. Keywords are the queries it matched, after synonyms were expanded.
+	Keyword string `json:"keyword,omitempty"`
 }
 
 func (a *AuctionItem) String() string {
@@ -54,8 +58,9 @@ func (a *AuctionItem) ID() string {
 	return a.Id
 }
 
+//SearchableDescription the text queries are matched against, the item's name, descriptions and SKU.
 func (a *AuctionItem) SearchableDescription() string {
-	return a.String()
+	return a.String() + "\n" + a.SKUNumber
 }
 
 func (a *AuctionItem) GetName() string {
@@ -100,14 +105,34 @@ func (s ByID) Less(i, j int) bool {
 	return s[i].ID() < s[j].ID()
 }
 
+//ByScore implements the sort.Sort interface to sort AuctionItems highest score first, ties are sorted by ID so the order is stable.
+type ByScore []AuctionItem
+
+func (s ByScore) Len() int {
+	return len(s)
+}
+func (s ByScore) Swap(i, j int) {
+	s[i], s[j] = s[j], s[i]
+}
+func (s ByScore) Less(i, j int) bool {
+	if s[i].Score != s[j].Score {
+		return s[i].Score > s[j].Score
+	}
+	return s[i].ID() < s[j].ID()
+}
+
 //------------------ Grouping -------------------------
-//AuctionItemGroupByKeyword groups the AuctionItems by keyword, returns a map of kwyword to AuctionItems
+//AuctionItemGroupByKeyword groups the AuctionItems by keyword, returns a map of kwyword to AuctionItems. Items are grouped by the watch list entry they were found for, Keyword, when it is set.
 type AuctionItemGroupByKeyword []AuctionItem
 
 func (g AuctionItemGroupByKeyword) Group() map[string][]AuctionItem {
 	var set = make(map[string][]AuctionItem)
 	for _, item := range g {
-		for _, keyword := range item.Keywords {
+		var keywords = item.Keywords
+		if item.Keyword != "" {
+			keywords = []string{item.Keyword}
+		}
+		for _, keyword := range keywords {
 			if _, exists := set[keyword]; exists {
 				set[keyword] = append(set[keyword], item)
 			} else {
@@ -118,6 +143,30 @@ func (g AuctionItemGroupByKeyword) Group() map[string][]AuctionItem {
 	return set
 }
 
+//AuctionItemGroup the items of one keyword.
+type AuctionItemGroup struct {
+	Keyword string
+	Items   []AuctionItem
+}
+
+//Ranked groups the AuctionItems by keyword, items are sorted by score and groups by their highest scoring item.
+func (g AuctionItemGroupByKeyword) Ranked() []AuctionItemGroup {
+	var groups = make([]AuctionItemGroup, 0)
+
+	for keyword, items := range g.Group() {
+		sort.Sort(ByScore(items))
+		groups = append(groups, AuctionItemGroup{Keyword: keyword, Items: items})
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if a, b := groups[i].Items[0].Score, groups[j].Items[0].Score; a != b {
+			return a > b
+		}
+		return groups[i].Keyword < groups[j].Keyword
+	})
+
+	return groups
+}
+
 //----------------- Filters ---------------------------
 //Filter a function with accepts and AuctionItem and returns true if it should be allowed to pass the filter.
 type Filterer interface {
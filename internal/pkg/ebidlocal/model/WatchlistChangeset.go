@@ -0,0 +1,121 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+//PriceChange an item whose price changed between two searches of a watch list.
+type PriceChange struct {
+	Item     AuctionItem `json:"item"`
+	Previous float64     `json:"previous"`
+	Current  float64     `json:"current"`
+}
+
+//WatchlistChangeset what changed in a watch list's content since it was last saved.
+type WatchlistChangeset struct {
+	WatchlistID string    `json:"watchlistID"`
+	Timestamp   time.Time `json:"timestamp"`
+	//PreviousContentID the ID of the content the changes are from, empty the first time a watch list is searched.
+	PreviousContentID string `json:"previousContentID,omitempty"`
+	ContentID         string `json:"contentID"`
+	//Added items that were not found before.
+	Added []AuctionItem `json:"added,omitempty"`
+	//Removed items that are no longer found, their auction closed or they no longer match.
+	Removed []AuctionItem `json:"removed,omitempty"`
+	//BidChanges items whose current bid changed.
+	BidChanges []PriceChange `json:"bidChanges,omitempty"`
+	//PriceDrops items whose buy now price went down.
+	PriceDrops []PriceChange `json:"priceDrops,omitempty"`
+}
+
+//DiffWatchlistContent the changes from previous to current content. Previous is nil when the watch list has no saved content, every item is added.
+func DiffWatchlistContent(previous *WatchlistContent, current *WatchlistContent) *WatchlistChangeset {
+	var changes = WatchlistChangeset{
+		WatchlistID: current.GetWatchlistID(),
+		Timestamp:   current.GetTimestamp(),
+		ContentID:   current.ID(),
+	}
+
+	var before = make(map[string]AuctionItem)
+	if previous != nil {
+		changes.PreviousContentID = previous.ID()
+		for _, item := range previous.AuctionItems {
+			before[item.ID()] = item
+		}
+	}
+
+	var after = make(map[string]struct{}, len(current.AuctionItems))
+	for _, item := range current.AuctionItems {
+		after[item.ID()] = struct{}{}
+		old, found := before[item.ID()]
+		if !found {
+			changes.Added = append(changes.Added, item)
+			continue
+		}
+		if old.CurrentBidAmount != item.CurrentBidAmount {
+			changes.BidChanges = append(changes.BidChanges, PriceChange{Item: item, Previous: old.CurrentBidAmount, Current: item.CurrentBidAmount})
+		}
+		if old.BuyNowPrice > 0 && item.BuyNowPrice > 0 && item.BuyNowPrice < old.BuyNowPrice {
+			changes.PriceDrops = append(changes.PriceDrops, PriceChange{Item: item, Previous: float64(old.BuyNowPrice), Current: float64(item.BuyNowPrice)})
+		}
+	}
+	if previous != nil {
+		for _, item := range previous.AuctionItems {
+			if _, found := after[item.ID()]; !found {
+				changes.Removed = append(changes.Removed, item)
+			}
+		}
+	}
+	sort.SliceStable(changes.Removed, func(i, j int) bool {
+		return changes.Removed[i].ID() < changes.Removed[j].ID()
+	})
+
+	return &changes
+}
+
+//IgnoreBidChanges drops the bid changes smaller than min, zero keeps them all.
+func (c *WatchlistChangeset) IgnoreBidChanges(min float64) *WatchlistChangeset {
+	if min <= 0 {
+		return c
+	}
+	var kept = c.BidChanges[:0]
+	for _, change := range c.BidChanges {
+		if math.Abs(change.Current-change.Previous) >= min {
+			kept = append(kept, change)
+		}
+	}
+	if c.BidChanges = kept; len(c.BidChanges) == 0 {
+		c.BidChanges = nil
+	}
+	return c
+}
+
+//IsEmpty true if nothing changed, the user is not notified.
+func (c *WatchlistChangeset) IsEmpty() bool {
+	return len(c.Added) == 0 && len(c.Removed) == 0 && len(c.BidChanges) == 0 && len(c.PriceDrops) == 0
+}
+
+//Summary a short description of the changes, "2 new, 1 price drop".
+func (c *WatchlistChangeset) Summary() string {
+	var parts []string
+	for _, part := range []struct {
+		count            int
+		singular, plural string
+	}{
+		{len(c.Added), "new", "new"},
+		{len(c.Removed), "removed", "removed"},
+		{len(c.BidChanges), "bid change", "bid changes"},
+		{len(c.PriceDrops), "price drop", "price drops"},
+	} {
+		if part.count == 1 {
+			parts = append(parts, fmt.Sprintf("%d %s", part.count, part.singular))
+		} else if part.count > 1 {
+			parts = append(parts, fmt.Sprintf("%d %s", part.count, part.plural))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
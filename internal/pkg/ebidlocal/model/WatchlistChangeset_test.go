@@ -0,0 +1,64 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffWatchlistContent(t *testing.T) {
+	var now = time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	var previous = &WatchlistContent{WatchlistID: "wl", AuctionItems: []AuctionItem{
+		{Id: "1", CurrentBidAmount: 10, BuyNowPrice: 100},
+		{Id: "2", CurrentBidAmount: 5},
+		{Id: "3", CurrentBidAmount: 1},
+	}}
+	var current = &WatchlistContent{WatchlistID: "wl", Timestamp: now, AuctionItems: []AuctionItem{
+		{Id: "1", CurrentBidAmount: 12, BuyNowPrice: 80},
+		{Id: "2", CurrentBidAmount: 5},
+		{Id: "4", CurrentBidAmount: 2},
+	}}
+
+	changes := DiffWatchlistContent(previous, current)
+	assert.Equal(t, "wl", changes.WatchlistID)
+	assert.Equal(t, now, changes.Timestamp)
+	assert.Equal(t, previous.ID(), changes.PreviousContentID)
+	assert.Equal(t, current.ID(), changes.ContentID)
+	assert.Equal(t, []AuctionItem{{Id: "4", CurrentBidAmount: 2}}, changes.Added)
+	assert.Equal(t, []AuctionItem{{Id: "3", CurrentBidAmount: 1}}, changes.Removed)
+	assert.Equal(t, []PriceChange{{Item: current.AuctionItems[0], Previous: 10, Current: 12}}, changes.BidChanges)
+	assert.Equal(t, []PriceChange{{Item: current.AuctionItems[0], Previous: 100, Current: 80}}, changes.PriceDrops)
+	assert.False(t, changes.IsEmpty())
+	assert.Equal(t, "1 new, 1 removed, 1 bid change, 1 price drop", changes.Summary())
+
+	t.Run("Only prices changed", func(t *testing.T) {
+		var repriced = &WatchlistContent{AuctionItems: []AuctionItem{{Id: "1", CurrentBidAmount: 15, BuyNowPrice: 100}, {Id: "2", CurrentBidAmount: 5}, {Id: "3", CurrentBidAmount: 1}}}
+		changes := DiffWatchlistContent(previous, repriced)
+		assert.Equal(t, previous.ID(), repriced.ID(), "The content ID does not see price changes")
+		assert.False(t, changes.IsEmpty())
+		assert.Len(t, changes.BidChanges, 1)
+	})
+
+	t.Run("Small bid changes", func(t *testing.T) {
+		var repriced = &WatchlistContent{AuctionItems: []AuctionItem{{Id: "1", CurrentBidAmount: 11, BuyNowPrice: 100}, {Id: "2", CurrentBidAmount: 10}, {Id: "3", CurrentBidAmount: 1}}}
+		changes := DiffWatchlistContent(previous, repriced).IgnoreBidChanges(5)
+		assert.Equal(t, []PriceChange{{Item: repriced.AuctionItems[1], Previous: 5, Current: 10}}, changes.BidChanges, "A change of the minimum is kept")
+
+		changes = DiffWatchlistContent(previous, repriced).IgnoreBidChanges(10)
+		assert.True(t, changes.IsEmpty(), "Bid changes under the minimum are not changes")
+		assert.Len(t, DiffWatchlistContent(previous, repriced).IgnoreBidChanges(0).BidChanges, 2, "Zero keeps every change")
+	})
+
+	t.Run("Nothing changed", func(t *testing.T) {
+		var same = &WatchlistContent{AuctionItems: previous.AuctionItems}
+		assert.True(t, DiffWatchlistContent(previous, same).IsEmpty())
+	})
+
+	t.Run("First search", func(t *testing.T) {
+		changes := DiffWatchlistContent(nil, current)
+		assert.Len(t, changes.Added, 3)
+		assert.Empty(t, changes.PreviousContentID)
+		assert.Equal(t, "3 new", changes.Summary())
+	})
+}
@@ -0,0 +1,122 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+//Range an inclusive range, either end may be left open.
+type Range struct {
+	Min *float64 `json:"min,omitempty"`
+	Max *float64 `json:"max,omitempty"`
+}
+
+func (r Range) IsZero() bool {
+	return r.Min == nil && r.Max == nil
+}
+
+func (r Range) Contains(v float64) bool {
+	return (r.Min == nil || v >= *r.Min) && (r.Max == nil || v <= *r.Max)
+}
+
+func (r Range) Validate() error {
+	if (r.Min != nil && *r.Min < 0) || (r.Max != nil && *r.Max < 0) {
+		return errors.New("range can not be negative")
+	}
+	if r.Min != nil && r.Max != nil && *r.Min > *r.Max {
+		return fmt.Errorf("range min %v is greater than max %v", *r.Min, *r.Max)
+	}
+	return nil
+}
+
+//WatchlistConstraints limits the items of a watch list by their fields, after their keywords match. The zero value allows every item.
+type WatchlistConstraints struct {
+	CurrentBid Range `json:"currentBid"`
+	//BuyNowPrice items without a buy now price are left out when this is set.
+	BuyNowPrice Range `json:"buyNowPrice"`
+	Quantity    Range `json:"quantity"`
+	//StatusCodes when set an item's status code must be one of these.
+	StatusCodes        []string `json:"statusCodes,omitempty"`
+	ExcludeStatusCodes []string `json:"excludeStatusCodes,omitempty"`
+	//Types when set one of an item's comma separated types must be one of these.
+	Types        []string `json:"types,omitempty"`
+	ExcludeTypes []string `json:"excludeTypes,omitempty"`
+}
+
+func (c WatchlistConstraints) IsZero() bool {
+	return c.CurrentBid.IsZero() && c.BuyNowPrice.IsZero() && c.Quantity.IsZero() &&
+		len(c.StatusCodes) == 0 && len(c.ExcludeStatusCodes) == 0 && len(c.Types) == 0 && len(c.ExcludeTypes) == 0
+}
+
+func (c WatchlistConstraints) Validate() error {
+	for name, r := range map[string]Range{"currentBid": c.CurrentBid, "buyNowPrice": c.BuyNowPrice, "quantity": c.Quantity} {
+		if err := r.Validate(); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+//Normalize lower cases, de-dupes and sorts the status codes and types so the same constraints always have the same ID.
+func (c *WatchlistConstraints) Normalize() *WatchlistConstraints {
+	for _, s := range []*[]string{&c.StatusCodes, &c.ExcludeStatusCodes, &c.Types, &c.ExcludeTypes} {
+		*s = normalizeCodes(*s)
+	}
+	return c
+}
+
+//Filter implements Filterer, true if the item is within all the constraints.
+func (c WatchlistConstraints) Filter(item AuctionItem) bool {
+	if !c.CurrentBid.Contains(item.CurrentBidAmount) || !c.Quantity.Contains(float64(item.Quantity)) {
+		return false
+	}
+	if !c.BuyNowPrice.IsZero() && (item.BuyNowPrice <= 0 || !c.BuyNowPrice.Contains(float64(item.BuyNowPrice))) {
+		return false
+	}
+
+	status := []string{item.StatusCode}
+	if (len(c.StatusCodes) > 0 && !containsAny(c.StatusCodes, status)) || containsAny(c.ExcludeStatusCodes, status) {
+		return false
+	}
+
+	types := strings.Split(item.Types, ",")
+	if (len(c.Types) > 0 && !containsAny(c.Types, types)) || containsAny(c.ExcludeTypes, types) {
+		return false
+	}
+
+	return true
+}
+
+func normalizeCodes(codes []string) []string {
+	if len(codes) == 0 {
+		return nil
+	}
+
+	set := make(map[string]struct{})
+	for _, code := range codes {
+		if code = strings.ToLower(strings.TrimSpace(code)); code != "" {
+			set[code] = struct{}{}
+		}
+	}
+	out := make([]string, 0, len(set))
+	for code := range set {
+		out = append(out, code)
+	}
+	sort.Strings(out)
+	return out
+}
+
+//containsAny true if any of values is in codes, ignoring case and surrounding space.
+func containsAny(codes []string, values []string) bool {
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		for _, code := range codes {
+			if strings.EqualFold(code, v) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func float(v float64) *float64 {
+	return &v
+}
+
+func TestWatchlistConstraintsFilter(t *testing.T) {
+	var item = AuctionItem{
+		CurrentBidAmount: 25,
+		BuyNowPrice:      60,
+		Quantity:         2,
+		StatusCode:       "Open",
+		Types:            "Tools,Hardware",
+	}
+	var tests = []struct {
+		Name        string
+		Constraints WatchlistConstraints
+		Expected    bool
+	}{
+		{Name: "No constraints", Expected: true},
+		{Name: "Under max current bid", Constraints: WatchlistConstraints{CurrentBid: Range{Max: float(50)}}, Expected: true},
+		{Name: "Over max current bid", Constraints: WatchlistConstraints{CurrentBid: Range{Max: float(20)}}, Expected: false},
+		{Name: "Under min current bid", Constraints: WatchlistConstraints{CurrentBid: Range{Min: float(30)}}, Expected: false},
+		{Name: "Buy now in range", Constraints: WatchlistConstraints{BuyNowPrice: Range{Max: float(60)}}, Expected: true},
+		{Name: "Buy now out of range", Constraints: WatchlistConstraints{BuyNowPrice: Range{Max: float(50)}}, Expected: false},
+		{Name: "Quantity in range", Constraints: WatchlistConstraints{Quantity: Range{Min: float(1), Max: float(2)}}, Expected: true},
+		{Name: "Quantity out of range", Constraints: WatchlistConstraints{Quantity: Range{Min: float(3)}}, Expected: false},
+		{Name: "Allowed status", Constraints: WatchlistConstraints{StatusCodes: []string{"open"}}, Expected: true},
+		{Name: "Not an allowed status", Constraints: WatchlistConstraints{StatusCodes: []string{"preview"}}, Expected: false},
+		{Name: "Excluded status", Constraints: WatchlistConstraints{ExcludeStatusCodes: []string{"OPEN"}}, Expected: false},
+		{Name: "Allowed type", Constraints: WatchlistConstraints{Types: []string{"hardware"}}, Expected: true},
+		{Name: "Not an allowed type", Constraints: WatchlistConstraints{Types: []string{"furniture"}}, Expected: false},
+		{Name: "Excluded type", Constraints: WatchlistConstraints{ExcludeTypes: []string{"tools"}}, Expected: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			assert.Equal(t, test.Expected, test.Constraints.Filter(item))
+		})
+	}
+
+	t.Run("Items without a buy now price fail a buy now constraint", func(t *testing.T) {
+		assert.False(t, WatchlistConstraints{BuyNowPrice: Range{Max: float(100)}}.Filter(AuctionItem{}))
+	})
+}
+
+func TestWatchlistConstraintsValidate(t *testing.T) {
+	assert.NoError(t, WatchlistConstraints{CurrentBid: Range{Min: float(1), Max: float(1)}}.Validate())
+	assert.Error(t, WatchlistConstraints{CurrentBid: Range{Min: float(2), Max: float(1)}}.Validate())
+	assert.Error(t, WatchlistConstraints{Quantity: Range{Min: float(-1)}}.Validate())
+}
@@ -49,3 +49,9 @@ func (wc *WatchlistContent) GetWatchlistID() string {
 func (wc *WatchlistContent) GetAuctionItems() []AuctionItem {
 	return wc.AuctionItems
 }
+
+//WatchlistSnapshot a saved version of a watch list's content, see store.WatchlistHistoryStorer.
+type WatchlistSnapshot struct {
+	ID        string    `json:"id"`
+	Timestamp time.Time `json:"timestamp"`
+}
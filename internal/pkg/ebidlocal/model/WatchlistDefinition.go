@@ -0,0 +1,196 @@
+package model
+
+import (
+	"bytes"
+	"crypto/sha1"
+	b64 "encoding/base64"
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/match"
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/synonym"
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/iter/stringiter"
+)
+
+//FilterSpec names a filter registered with package filter and its parameters, see filter.Register.
+type FilterSpec struct {
+	Name   string          `json:"name"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+//WatchlistOptions per watch list settings of how its keywords are matched and which of the matched items are kept.
+type WatchlistOptions struct {
+	Match       match.Options        `json:"match,omitempty"`
+	Constraints WatchlistConstraints `json:"constraints"`
+	//Filters the pipeline an item must pass, in order, to be kept. When empty items are matched by their query then by the constraints.
+	Filters []FilterSpec `json:"filters,omitempty"`
+	//Synonyms groups of interchangeable terms added to the shared dictionary for this watch list, see package synonym.
+	Synonyms [][]string `json:"synonyms,omitempty"`
+}
+
+func (o WatchlistOptions) IsZero() bool {
+	return o.Match.IsZero() && o.Constraints.IsZero() && len(o.Filters) == 0 && len(o.Synonyms) == 0
+}
+
+func (o WatchlistOptions) Validate() error {
+	if err := o.Match.Validate(); err != nil {
+		return err
+	}
+	if err := synonym.Validate(o.Synonyms); err != nil {
+		return err
+	}
+	return o.Constraints.Validate()
+}
+
+//Normalize the same options always have the same ID, synonym groups are sorted and the terms of each group are de-duped and sorted.
+func (o *WatchlistOptions) Normalize() *WatchlistOptions {
+	o.Constraints.Normalize()
+
+	var groups = make([][]string, 0, len(o.Synonyms))
+	var seen = make(map[string]struct{})
+	for _, group := range o.Synonyms {
+		var terms = make([]string, 0, len(group))
+		for _, term := range group {
+			terms = append(terms, synonym.Normalize(term))
+		}
+		if terms = normalizeCodes(terms); len(terms) < 2 {
+			continue
+		}
+		if key := strings.Join(terms, "\x00"); !hasKey(seen, key) {
+			seen[key] = struct{}{}
+			groups = append(groups, terms)
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return strings.Join(groups[i], "\x00") < strings.Join(groups[j], "\x00")
+	})
+	if o.Synonyms = groups; len(groups) == 0 {
+		o.Synonyms = nil
+	}
+	return o
+}
+
+func hasKey(set map[string]struct{}, key string) bool {
+	_, exists := set[key]
+	return exists
+}
+
+//WatchlistVersion the version of the watch list document MarshalJSON writes. Version 0 is a bare array of keywords, the format watch lists were first stored in, and version 1 an object of keywords and options.
+const WatchlistVersion = 2
+
+//WatchlistMetadata describes a watch list. It is not part of the watch list's ID, watch lists are shared by every user with the same keywords and options, so it is what the first user to create it gave it.
+type WatchlistMetadata struct {
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	//Owner the ID of the user who created the watch list.
+	Owner   string    `json:"owner,omitempty"`
+	Created time.Time `json:"created"`
+}
+
+//WatchlistDefinition a watch list's keywords, metadata and options.
+//A definition without options has the same ID as its keywords so watch lists created before there were options are unchanged.
+type WatchlistDefinition struct {
+	//Version the version of the document the definition was read from, see WatchlistVersion.
+	Version  int               `json:"version"`
+	Keywords Watchlist         `json:"keywords"`
+	Metadata WatchlistMetadata `json:"metadata"`
+	Options  WatchlistOptions  `json:"options"`
+}
+
+var _ Watchlister = WatchlistDefinition{}
+
+//ID watch lists with the same keywords but different options are different watch lists.
+func (d WatchlistDefinition) ID() string {
+	if d.Options.IsZero() {
+		return d.Keywords.ID()
+	}
+
+	options := d.Options
+	h := sha1.New()
+	h.Write([]byte(d.Keywords.ID()))
+	h.Write(canonicalJSON(options.Normalize()))
+	return b64.URLEncoding.EncodeToString(h.Sum(nil))
+}
+
+func (d WatchlistDefinition) Iterator() stringiter.Iterator {
+	return d.Keywords.Iterator()
+}
+
+func (d WatchlistDefinition) GetKeywords() []string {
+	return d.Keywords
+}
+
+func (d WatchlistDefinition) GetMetadata() WatchlistMetadata {
+	return d.Metadata
+}
+
+func (d WatchlistDefinition) GetOptions() WatchlistOptions {
+	return d.Options
+}
+
+func (d *WatchlistDefinition) Normalize() *WatchlistDefinition {
+	d.Keywords.Normalize()
+	d.Options.Normalize()
+	return d
+}
+
+//MarshalJSON always writes the current version of the document.
+func (d WatchlistDefinition) MarshalJSON() ([]byte, error) {
+	type definition WatchlistDefinition
+	d.Version = WatchlistVersion
+	return canonicalJSON(definition(d)), nil
+}
+
+//UnmarshalJSON accepts every version of the document, Version is set to the version that was read.
+func (d *WatchlistDefinition) UnmarshalJSON(b []byte) error {
+	if b = bytes.TrimSpace(b); len(b) > 0 && b[0] == '[' {
+		*d = WatchlistDefinition{}
+		return json.Unmarshal(b, &d.Keywords)
+	}
+
+	type definition WatchlistDefinition
+	var tmp definition
+	if err := json.Unmarshal(b, &tmp); err != nil {
+		return err
+	}
+	*d = WatchlistDefinition(tmp)
+	if d.Version == 0 {
+		d.Version = 1
+	}
+	return nil
+}
+
+//canonicalJSON v as JSON with its keys sorted and empty objects and arrays left out, so options added later don't change the IDs of watch lists that don't use them.
+func canonicalJSON(v interface{}) []byte {
+	var tree interface{}
+
+	b, _ := json.Marshal(v)
+	if err := json.Unmarshal(b, &tree); err != nil {
+		return b
+	}
+	b, _ = json.Marshal(prune(tree))
+	return b
+}
+
+func prune(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if child = prune(child); child == nil {
+				delete(v, key)
+			} else {
+				v[key] = child
+			}
+		}
+		if len(v) == 0 {
+			return nil
+		}
+	case []interface{}:
+		if len(v) == 0 {
+			return nil
+		}
+	}
+	return v
+}
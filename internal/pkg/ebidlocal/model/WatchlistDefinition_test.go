@@ -0,0 +1,72 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/match"
+)
+
+func TestWatchlistDefinitionID(t *testing.T) {
+	var legacy = WatchlistDefinition{Keywords: Watchlist{"D", "R", "G", "Q", "A"}}
+	assert.Equal(t, "HGKNlTs0ana3IDwNSaID7Sfve6U=", legacy.ID(), "A definition without options has the ID of its keywords")
+
+	var stemmed = WatchlistDefinition{Keywords: Watchlist{"D", "R", "G", "Q", "A"}, Options: WatchlistOptions{Match: match.Options{Stem: true}}}
+	assert.NotEqual(t, legacy.ID(), stemmed.ID(), "Options are part of the ID")
+	assert.Equal(t, "3wEnkTfNSuGq9Aczj3psXazxvz8=", stemmed.ID(), "Options added later must not change the ID of existing watch lists")
+
+	var budget = WatchlistDefinition{Keywords: Watchlist{"A"}, Options: WatchlistOptions{Constraints: WatchlistConstraints{CurrentBid: Range{Max: float(50)}}}}
+	var lowBudget = WatchlistDefinition{Keywords: Watchlist{"A"}, Options: WatchlistOptions{Constraints: WatchlistConstraints{CurrentBid: Range{Max: float(20)}}}}
+	assert.NotEqual(t, budget.ID(), lowBudget.ID(), "Different constraints are different watch lists")
+
+	var a = WatchlistDefinition{Keywords: Watchlist{"A"}, Options: WatchlistOptions{Constraints: WatchlistConstraints{StatusCodes: []string{"Open", "preview"}}}}
+	var b = WatchlistDefinition{Keywords: Watchlist{"A"}, Options: WatchlistOptions{Constraints: WatchlistConstraints{StatusCodes: []string{"preview", "open"}}}}
+	assert.Equal(t, a.ID(), b.ID(), "The order and case of status codes does not matter")
+
+	var filtered = WatchlistDefinition{Keywords: Watchlist{"A"}, Options: WatchlistOptions{Filters: []FilterSpec{{Name: "keyword"}}}}
+	assert.NotEqual(t, WatchlistDefinition{Keywords: Watchlist{"A"}}.ID(), filtered.ID(), "Filters are part of the ID")
+
+	var couch = WatchlistDefinition{Keywords: Watchlist{"A"}, Options: WatchlistOptions{Synonyms: [][]string{{"Sofa", "couch"}, {"tv", "television"}}}}
+	var sofa = WatchlistDefinition{Keywords: Watchlist{"A"}, Options: WatchlistOptions{Synonyms: [][]string{{"television", "TV"}, {"couch", "sofa", "couch"}}}}
+	assert.Equal(t, couch.ID(), sofa.ID(), "The order, case and duplicates of synonyms does not matter")
+}
+
+func TestWatchlistDefinitionJSON(t *testing.T) {
+	var created = time.Date(2022, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	t.Run("A definition is written as the current version", func(t *testing.T) {
+		var d = WatchlistDefinition{
+			Keywords: Watchlist{"drill"},
+			Metadata: WatchlistMetadata{Name: "Tools", Owner: "1", Created: created},
+			Options:  WatchlistOptions{Match: match.Options{Fuzzy: 1}},
+		}
+		b, err := json.Marshal(d)
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"version":2,"keywords":["drill"],"metadata":{"name":"Tools","owner":"1","created":"2022-01-10T12:00:00Z"},"options":{"match":{"fuzzy":1}}}`, string(b))
+
+		var actual WatchlistDefinition
+		assert.NoError(t, json.Unmarshal(b, &actual))
+		d.Version = WatchlistVersion
+		assert.Equal(t, d, actual)
+	})
+
+	t.Run("Metadata is not part of the ID", func(t *testing.T) {
+		var d = WatchlistDefinition{Keywords: Watchlist{"drill"}, Metadata: WatchlistMetadata{Name: "Tools", Created: created}}
+		assert.Equal(t, d.Keywords.ID(), d.ID())
+	})
+
+	t.Run("Version 1 object", func(t *testing.T) {
+		var actual WatchlistDefinition
+		assert.NoError(t, json.Unmarshal([]byte(`{"keywords":["drill"],"options":{"match":{"fuzzy":1}}}`), &actual))
+		assert.Equal(t, WatchlistDefinition{Version: 1, Keywords: Watchlist{"drill"}, Options: WatchlistOptions{Match: match.Options{Fuzzy: 1}}}, actual)
+	})
+
+	t.Run("Legacy bare array", func(t *testing.T) {
+		var actual WatchlistDefinition
+		assert.NoError(t, json.Unmarshal([]byte(` ["drill", "saw"]`), &actual))
+		assert.Equal(t, WatchlistDefinition{Keywords: Watchlist{"drill", "saw"}}, actual)
+	})
+}
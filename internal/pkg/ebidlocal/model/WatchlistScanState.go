@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+//WatchlistScanState what the updater knows about a watch list between searches, see store.WatchlistContentStorer.
+type WatchlistScanState struct {
+	WatchlistID string `json:"watchlistID"`
+	//LastScan when the watch list was last searched, whether or not it succeeded.
+	LastScan    time.Time `json:"lastScan,omitempty"`
+	LastSuccess time.Time `json:"lastSuccess,omitempty"`
+	LastFailure time.Time `json:"lastFailure,omitempty"`
+	//LastError why the last failed search failed.
+	LastError string `json:"lastError,omitempty"`
+	//Failures the number of searches that failed in a row, reset by a search that succeeds.
+	Failures int `json:"failures,omitempty"`
+}
+
+//Succeeded records a search that succeeded at.
+func (s *WatchlistScanState) Succeeded(at time.Time) {
+	s.LastScan, s.LastSuccess, s.Failures = at, at, 0
+}
+
+//Failed records a search that failed at.
+func (s *WatchlistScanState) Failed(at time.Time, err error) {
+	s.LastScan, s.LastFailure = at, at
+	s.LastError = err.Error()
+	s.Failures++
+}
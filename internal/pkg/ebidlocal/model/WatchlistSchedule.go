@@ -0,0 +1,49 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+//WatchlistSchedule when a user's watch list is searched. A paused, snoozed or expired watch list is not searched and its user is not notified.
+type WatchlistSchedule struct {
+	Paused bool `json:"paused,omitempty"`
+	//SnoozeUntil the watch list is not searched before then.
+	SnoozeUntil time.Time `json:"snoozeUntil,omitempty"`
+	//Expires the watch list pauses itself then, never when zero. Seasonal watch lists stop on their own.
+	Expires time.Time `json:"expires,omitempty"`
+}
+
+func (s WatchlistSchedule) IsZero() bool {
+	return !s.Paused && s.SnoozeUntil.IsZero() && s.Expires.IsZero()
+}
+
+func (s WatchlistSchedule) Validate() error {
+	if !s.SnoozeUntil.IsZero() && !s.Expires.IsZero() && !s.SnoozeUntil.Before(s.Expires) {
+		return fmt.Errorf("snoozeUntil: the watch list expires before it wakes")
+	}
+	return nil
+}
+
+//Active true if the watch list is searched at now.
+func (s WatchlistSchedule) Active(now time.Time) bool {
+	if s.Paused || now.Before(s.SnoozeUntil) {
+		return false
+	}
+	return s.Expires.IsZero() || now.Before(s.Expires)
+}
+
+//WatchlistSchedules the schedules of every user of a shared watch list. It is searched while any of them is active, a watch list without schedules always is.
+type WatchlistSchedules []WatchlistSchedule
+
+func (s WatchlistSchedules) Active(now time.Time) bool {
+	if len(s) == 0 {
+		return true
+	}
+	for _, schedule := range s {
+		if schedule.Active(now) {
+			return true
+		}
+	}
+	return false
+}
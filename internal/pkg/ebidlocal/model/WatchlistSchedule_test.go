@@ -0,0 +1,33 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatchlistScheduleActive(t *testing.T) {
+	var now = time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	var tests = []struct {
+		Name     string
+		Schedule WatchlistSchedule
+		Expected bool
+	}{
+		{Name: "Zero", Expected: true},
+		{Name: "Paused", Schedule: WatchlistSchedule{Paused: true}, Expected: false},
+		{Name: "Snoozed", Schedule: WatchlistSchedule{SnoozeUntil: now.Add(time.Hour)}, Expected: false},
+		{Name: "Woke up", Schedule: WatchlistSchedule{SnoozeUntil: now.Add(-time.Hour)}, Expected: true},
+		{Name: "Expired", Schedule: WatchlistSchedule{Expires: now.Add(-time.Hour)}, Expected: false},
+		{Name: "Expires later", Schedule: WatchlistSchedule{Expires: now.Add(time.Hour)}, Expected: true},
+	}
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			assert.Equal(t, test.Expected, test.Schedule.Active(now))
+		})
+	}
+
+	assert.True(t, WatchlistSchedules(nil).Active(now), "A watch list without schedules is always searched")
+	assert.True(t, WatchlistSchedules{{Paused: true}, {}}.Active(now), "A shared watch list is searched while one user is active")
+	assert.False(t, WatchlistSchedules{{Paused: true}, {Expires: now}}.Active(now))
+}
@@ -4,6 +4,7 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/query"
 	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/id"
 	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/iter/stringiter"
 )
@@ -27,7 +28,11 @@ func (w *Watchlist) Normalize() *Watchlist {
 	i := 0
 	tmp := Watchlist(make([]string, len(set)))
 	for key := range set {
-		tmp[i] = strings.ToLower(key)
+		//Lower casing a regular expression changes it, "\D" is not "\d"; patterns ignore case when they are matched.
+		if !query.IsPattern(key) {
+			key = strings.ToLower(key)
+		}
+		tmp[i] = key
 		i++
 	}
 
@@ -12,3 +12,12 @@ func TestWatchlistID(t *testing.T) {
 		t.Errorf("Failed ID creation got '%v'", w.ID())
 	}
 }
+
+func TestWatchlistNormalize(t *testing.T) {
+	var w = Watchlist{`re:DCD7\D\d`, "Drill"}
+	w.Normalize()
+
+	if len(w) != 2 || w[0] != "drill" || w[1] != `re:DCD7\D\d` {
+		t.Errorf("Expected keywords to be lower cased and patterns left alone got '%v'", w)
+	}
+}
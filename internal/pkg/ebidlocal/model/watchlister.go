@@ -5,7 +5,11 @@ import (
 	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/iter/stringiter"
 )
 
+//Watchlister a watch list, iterating it iterates its keywords.
 type Watchlister interface {
 	id.IDer
 	stringiter.Iterable
+	GetKeywords() []string
+	GetMetadata() WatchlistMetadata
+	GetOptions() WatchlistOptions
 }
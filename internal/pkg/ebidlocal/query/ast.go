@@ -0,0 +1,109 @@
+package query
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/match"
+)
+
+//Node a node of a parsed query.
+type Node interface {
+	Match(doc *match.Document) bool
+	String() string
+}
+
+//Word matches a single word of a document. A word written as "kayak~" or "kayak~1" sets how many typos it allows, overriding the watch list's match options; Fuzzy is -1 when it was not set.
+type Word struct {
+	Text  string
+	Fuzzy int
+}
+
+func (w Word) Match(doc *match.Document) bool {
+	return doc.Contains([]string{doc.Matcher().Keyword(w.Text)}, w.Fuzzy)
+}
+
+func (w Word) String() string {
+	switch {
+	case w.Fuzzy < 0:
+		return w.Text
+	case w.Fuzzy == match.MaxFuzzy:
+		return w.Text + "~"
+	}
+	return w.Text + "~" + strconv.Itoa(w.Fuzzy)
+}
+
+//Phrase matches consecutive words of a document.
+type Phrase struct {
+	Text string
+}
+
+func (p Phrase) Match(doc *match.Document) bool {
+	return doc.Contains(doc.Matcher().Keywords(p.Text), -1)
+}
+
+func (p Phrase) String() string {
+	return `"` + p.Text + `"`
+}
+
+//And matches if all of its nodes match.
+type And []Node
+
+func (a And) Match(doc *match.Document) bool {
+	for _, n := range a {
+		if !n.Match(doc) {
+			return false
+		}
+	}
+	return true
+}
+
+func (a And) String() string {
+	return join(a, " AND ")
+}
+
+//Or matches if any of its nodes match.
+type Or []Node
+
+func (o Or) Match(doc *match.Document) bool {
+	for _, n := range o {
+		if n.Match(doc) {
+			return true
+		}
+	}
+	return false
+}
+
+func (o Or) String() string {
+	return join(o, " OR ")
+}
+
+//Not matches if its node does not.
+type Not struct {
+	Node Node
+}
+
+func (n Not) Match(doc *match.Document) bool {
+	return !n.Node.Match(doc)
+}
+
+func (n Not) String() string {
+	return "NOT " + group(n.Node)
+}
+
+func join(nodes []Node, sep string) string {
+	var s = make([]string, len(nodes))
+	for i, n := range nodes {
+		s[i] = group(n)
+	}
+	return strings.Join(s, sep)
+}
+
+//group wraps compound nodes in parentheses so String() can be parsed back into the same tree.
+func group(n Node) string {
+	switch n.(type) {
+	case And, Or:
+		return "(" + n.String() + ")"
+	}
+	return n.String()
+}
@@ -0,0 +1,112 @@
+package query
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenType int
+
+const (
+	tokenEOF tokenType = iota
+	tokenWord
+	tokenPhrase
+	tokenAnd
+	tokenOr
+	tokenNot
+	tokenExclude
+	tokenLParen
+	tokenRParen
+)
+
+func (t tokenType) String() string {
+	switch t {
+	case tokenEOF:
+		return "end of query"
+	case tokenWord:
+		return "word"
+	case tokenPhrase:
+		return "phrase"
+	case tokenAnd:
+		return "AND"
+	case tokenOr:
+		return "OR"
+	case tokenNot:
+		return "NOT"
+	case tokenExclude:
+		return "'-'"
+	case tokenLParen:
+		return "'('"
+	case tokenRParen:
+		return "')'"
+	}
+	return "unknown"
+}
+
+type token struct {
+	Type  tokenType
+	Value string
+	Pos   int
+}
+
+//lex splits a query into tokens. Operators are matched case insensitively because watch lists are lower cased when they are normalized.
+func lex(input string) ([]token, error) {
+	var tokens []token
+
+	for pos := 0; pos < len(input); {
+		r, width := utf8.DecodeRuneInString(input[pos:])
+
+		switch {
+		case unicode.IsSpace(r):
+			pos += width
+		case r == '(':
+			tokens = append(tokens, token{Type: tokenLParen, Value: "(", Pos: pos})
+			pos += width
+		case r == ')':
+			tokens = append(tokens, token{Type: tokenRParen, Value: ")", Pos: pos})
+			pos += width
+		case r == '"':
+			end := strings.IndexRune(input[pos+width:], '"')
+			if end < 0 {
+				return nil, &ParseError{Pos: pos, Msg: "unterminated phrase"}
+			}
+			tokens = append(tokens, token{Type: tokenPhrase, Value: input[pos+width : pos+width+end], Pos: pos})
+			pos += width + end + 1
+		case r == '-' && excludes(input[pos+width:]):
+			tokens = append(tokens, token{Type: tokenExclude, Value: "-", Pos: pos})
+			pos += width
+		default:
+			end := strings.IndexFunc(input[pos:], isWordBoundary)
+			if end < 0 {
+				end = len(input) - pos
+			}
+			tokens = append(tokens, wordToken(input[pos:pos+end], pos))
+			pos += end
+		}
+	}
+
+	return append(tokens, token{Type: tokenEOF, Pos: len(input)}), nil
+}
+
+func wordToken(word string, pos int) token {
+	switch strings.ToUpper(word) {
+	case "AND":
+		return token{Type: tokenAnd, Value: word, Pos: pos}
+	case "OR":
+		return token{Type: tokenOr, Value: word, Pos: pos}
+	case "NOT":
+		return token{Type: tokenNot, Value: word, Pos: pos}
+	}
+	return token{Type: tokenWord, Value: word, Pos: pos}
+}
+
+//excludes true if a '-' is directly followed by something it can exclude, so "-drill" is an exclusion but "- drill" is not.
+func excludes(rest string) bool {
+	r, _ := utf8.DecodeRuneInString(rest)
+	return rest != "" && !unicode.IsSpace(r) && r != ')' && r != '-'
+}
+
+func isWordBoundary(r rune) bool {
+	return unicode.IsSpace(r) || r == '(' || r == ')' || r == '"'
+}
@@ -0,0 +1,152 @@
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/match"
+)
+
+const (
+	//RegexPrefix marks a watch list entry as a regular expression, "re:DCD7\d\d".
+	RegexPrefix = "re:"
+	//GlobPrefix marks a watch list entry as a glob, "glob:RTX 30*0". '*' matches any part of a word and '?' a single character.
+	GlobPrefix = "glob:"
+
+	//MaxPatternLength the longest pattern that is accepted.
+	MaxPatternLength = 256
+	//MaxPatternInstructions the largest compiled pattern that is accepted. Go's regular expressions are RE2, they run in linear time, but large programs are still slow to run against every item.
+	MaxPatternInstructions = 2000
+)
+
+//Pattern matches the text of a document, an item's name, descriptions and SKU, with a case insensitive regular expression. Unlike words patterns are not normalized by the watch list's match options.
+type Pattern struct {
+	//Prefix RegexPrefix or GlobPrefix.
+	Prefix string
+	Source string
+	//Literal the text every match starts with, it is what the auction site is searched for.
+	Literal string
+	re      *regexp.Regexp
+}
+
+func (p Pattern) Match(doc *match.Document) bool {
+	return p.re.MatchString(doc.Text())
+}
+
+func (p Pattern) String() string {
+	return p.Prefix + p.Source
+}
+
+//IsPattern true if the watch list entry is a regular expression or glob rather than a query.
+func IsPattern(raw string) bool {
+	_, _, ok := cutPrefix(raw)
+	return ok
+}
+
+//parsePattern compiles a regex or glob entry, checking it against the safety limits and that it has a literal prefix to search for.
+func parsePattern(raw string) (Pattern, error) {
+	prefix, source, _ := cutPrefix(raw)
+	var p = Pattern{Prefix: prefix, Source: source}
+
+	if strings.TrimSpace(source) == "" {
+		return p, &ParseError{Pos: len(prefix), Msg: "empty pattern"}
+	}
+	if len(source) > MaxPatternLength {
+		return p, &ParseError{Pos: len(prefix), Msg: fmt.Sprintf("pattern is longer than %d characters", MaxPatternLength)}
+	}
+
+	var expr = source
+	if prefix == GlobPrefix {
+		expr = globToRegex(strings.TrimSpace(source))
+	}
+
+	re, err := syntax.Parse(expr, syntax.Perl)
+	if err != nil {
+		return p, &ParseError{Pos: len(prefix), Msg: err.Error()}
+	}
+	prog, err := syntax.Compile(re.Simplify())
+	if err != nil {
+		return p, &ParseError{Pos: len(prefix), Msg: err.Error()}
+	}
+	if len(prog.Inst) > MaxPatternInstructions {
+		return p, &ParseError{Pos: len(prefix), Msg: "pattern is too complex"}
+	}
+
+	if p.re, err = regexp.Compile("(?i)" + expr); err != nil {
+		return p, &ParseError{Pos: len(prefix), Msg: err.Error()}
+	}
+
+	p.Literal = strings.TrimSpace(literalPrefix(prefix, source))
+	if len(exact.Keywords(p.Literal)) == 0 {
+		return p, &ParseError{Pos: len(prefix), Msg: "pattern has no literal prefix to search for"}
+	}
+
+	return p, nil
+}
+
+//literalPrefix the text before a glob's first wildcard, or a regular expression's literal prefix. The prefix of a regular expression is taken before case is ignored, otherwise it has none.
+func literalPrefix(prefix string, source string) string {
+	if prefix == GlobPrefix {
+		if i := strings.IndexAny(source, "*?"); i >= 0 {
+			return source[:i]
+		}
+		return source
+	}
+	re, err := regexp.Compile(source)
+	if err != nil {
+		return ""
+	}
+	literal, _ := re.LiteralPrefix()
+	return literal
+}
+
+//globToRegex '*' matches any part of a word, '?' a single character and spaces any space. A glob that starts or ends with a letter or digit starts or ends on a word boundary.
+func globToRegex(glob string) string {
+	var b strings.Builder
+
+	fields := strings.Fields(glob)
+	for i, field := range fields {
+		if i > 0 {
+			b.WriteString(`\s+`)
+		}
+		for _, r := range field {
+			switch r {
+			case '*':
+				b.WriteString(`\S*`)
+			case '?':
+				b.WriteString(`\S`)
+			default:
+				b.WriteString(regexp.QuoteMeta(string(r)))
+			}
+		}
+	}
+
+	expr := b.String()
+	if isWordChar(glob, 0) {
+		expr = `\b` + expr
+	}
+	if isWordChar(glob, len(glob)-1) {
+		expr += `\b`
+	}
+	return expr
+}
+
+func isWordChar(s string, i int) bool {
+	if i < 0 || i >= len(s) {
+		return false
+	}
+	c := s[i]
+	return c == '_' || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+//cutPrefix splits the pattern prefix, matched case insensitively, from the pattern.
+func cutPrefix(raw string) (prefix string, source string, ok bool) {
+	for _, prefix := range []string{RegexPrefix, GlobPrefix} {
+		if len(raw) >= len(prefix) && strings.EqualFold(raw[:len(prefix)], prefix) {
+			return prefix, raw[len(prefix):], true
+		}
+	}
+	return "", raw, false
+}
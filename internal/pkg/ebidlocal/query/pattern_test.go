@@ -0,0 +1,83 @@
+package query
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePattern(t *testing.T) {
+	var tests = []struct {
+		Query   string
+		Terms   []string
+		Match   []string
+		NoMatch []string
+	}{
+		{
+			Query:   `re:DCD7\d\d`,
+			Terms:   []string{"DCD7"},
+			Match:   []string{"DeWalt DCD771 drill", "dewalt dcd791"},
+			NoMatch: []string{"DeWalt DCD7 drill", "DCF887"},
+		},
+		{
+			Query:   `RE:\bDCD7\d\d\b`,
+			Terms:   nil,
+			Match:   nil,
+			NoMatch: nil,
+		},
+		{
+			Query:   "glob:RTX 30*0",
+			Terms:   []string{"RTX 30"},
+			Match:   []string{"EVGA GeForce RTX 3080 10GB", "rtx  3090", "RTX 30-0"},
+			NoMatch: []string{"RTX 2080", "RTX 3080Ti", "GTX 3080"},
+		},
+		{
+			Query:   "glob:ps? console",
+			Terms:   []string{"ps"},
+			Match:   []string{"Sony PS5 console"},
+			NoMatch: []string{"Sony PS console", "PS55 console"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Query, func(t *testing.T) {
+			q, err := Parse(test.Query)
+			if test.Terms == nil {
+				assert.Error(t, err, "A pattern must have a literal prefix to search for")
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, test.Terms, q.Terms())
+			for _, text := range test.Match {
+				assert.True(t, q.Match(text), text)
+			}
+			for _, text := range test.NoMatch {
+				assert.False(t, q.Match(text), text)
+			}
+		})
+	}
+}
+
+func TestParsePatternLimits(t *testing.T) {
+	for _, raw := range []string{
+		"re:",
+		"glob:  ",
+		"re:drill(",
+		"re:drill{1001}",
+		"re:" + strings.Repeat("a", MaxPatternLength+1),
+		"re:drill" + strings.Repeat("[a-z]{1000}", 3),
+		"glob:*drill",
+	} {
+		assert.Error(t, Validate(raw), raw)
+	}
+}
+
+func TestIsPattern(t *testing.T) {
+	assert.True(t, IsPattern(`re:DCD7\d\d`))
+	assert.True(t, IsPattern("Glob:RTX 30*0"))
+	assert.False(t, IsPattern("drill"))
+	assert.False(t, IsPattern("regex drill"))
+}
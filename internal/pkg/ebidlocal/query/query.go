@@ -0,0 +1,310 @@
+//Package query parses watch list entries into a small boolean query language.
+//
+//	nintendo switch          both words, AND is implied
+//	"nintendo switch"        the words next to each other
+//	drill OR driver          either word
+//	switch NOT light         NOT, or a leading '-', excludes a term: switch -light
+//	(drill OR driver) -bits  parentheses group terms
+//	kayak~ kayak~1           allow typos in a word, see match.Options.Fuzzy
+//	re:DCD7\d\d              the whole entry is a regular expression, see Pattern
+//	glob:RTX 30*0            the whole entry is a glob
+//
+//Operators are case insensitive. NOT binds tighter than AND, which binds tighter than OR. How words are compared is selected with match.Options; with the zero options a single word matches the same way filter.ByKeyword always has, so existing watch lists keep working.
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/match"
+)
+
+var fuzzySuffix = regexp.MustCompile(`^(.+)~([0-9]?)$`)
+
+//exact used to check that terms have something to search for.
+var exact = match.New(match.Options{})
+
+//ParseError describes why and where a query could not be parsed.
+type ParseError struct {
+	Pos int
+	Msg string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s at position %d", e.Msg, e.Pos)
+}
+
+//Query a parsed watch list entry.
+type Query struct {
+	Raw  string
+	Root Node
+}
+
+//Parse parses a query, or a pattern when the entry starts with RegexPrefix or GlobPrefix. A query must contain at least one term that is not excluded, and a pattern a literal prefix, since those are the terms the auction site is searched for.
+func Parse(raw string) (*Query, error) {
+	if IsPattern(raw) {
+		p, err := parsePattern(raw)
+		if err != nil {
+			return nil, err
+		}
+		return &Query{Raw: raw, Root: p}, nil
+	}
+
+	tokens, err := lex(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	p := parser{tokens: tokens}
+	if p.peek().Type == tokenEOF {
+		return nil, &ParseError{Pos: 0, Msg: "empty query"}
+	}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.Type != tokenEOF {
+		return nil, &ParseError{Pos: t.Pos, Msg: fmt.Sprintf("unexpected %s", t.Type)}
+	}
+
+	q := &Query{Raw: raw, Root: root}
+	if len(q.Terms()) == 0 {
+		return nil, &ParseError{Pos: 0, Msg: "query only excludes terms"}
+	}
+	return q, nil
+}
+
+//Validate returns the parse error of a query, if any.
+func Validate(raw string) error {
+	_, err := Parse(raw)
+	return err
+}
+
+//Match true if the text matches the query using the zero match.Options.
+func (q *Query) Match(text string) bool {
+	return q.Root.Match(exact.Document(text))
+}
+
+//MatchDocument true if the document matches the query, the document's matcher decides how words are compared. Useful when matching many queries against one item.
+func (q *Query) MatchDocument(doc *match.Document) bool {
+	return q.Root.Match(doc)
+}
+
+//Terms the words and phrases of the query that are not excluded, in the order they appear. These are what the auction site is searched for; an item is found only if it contains one of them.
+func (q *Query) Terms() []string {
+	var terms []string
+	var seen = make(map[string]struct{})
+
+	var walk func(n Node, excluded bool)
+	walk = func(n Node, excluded bool) {
+		var term string
+		switch n := n.(type) {
+		case Word:
+			term = n.Text
+		case Phrase:
+			term = n.Text
+		case Pattern:
+			term = n.Literal
+		case Not:
+			walk(n.Node, !excluded)
+			return
+		case And:
+			for _, c := range n {
+				walk(c, excluded)
+			}
+			return
+		case Or:
+			for _, c := range n {
+				walk(c, excluded)
+			}
+			return
+		}
+		if _, exists := seen[term]; !excluded && !exists {
+			seen[term] = struct{}{}
+			terms = append(terms, term)
+		}
+	}
+	walk(q.Root, false)
+
+	return terms
+}
+
+//Expand a copy of the query with each word and phrase that is not excluded replaced by it OR its synonyms. Excluded terms are left alone, "sofa -leather" should not exclude every synonym of leather. Raw is still the query as it was typed.
+func (q *Query) Expand(synonyms func(term string) []string) *Query {
+	var expand func(n Node, excluded bool) Node
+	expand = func(n Node, excluded bool) Node {
+		var term string
+		switch n := n.(type) {
+		case Word:
+			term = n.Text
+		case Phrase:
+			term = n.Text
+		case Not:
+			return Not{Node: expand(n.Node, !excluded)}
+		case And:
+			var and = make(And, len(n))
+			for i, c := range n {
+				and[i] = expand(c, excluded)
+			}
+			return and
+		case Or:
+			var or = make(Or, len(n))
+			for i, c := range n {
+				or[i] = expand(c, excluded)
+			}
+			return or
+		default:
+			return n
+		}
+
+		var alternatives = synonyms(term)
+		if excluded || len(alternatives) == 0 {
+			return n
+		}
+		var or = Or{n}
+		for _, synonym := range alternatives {
+			if len(exact.Keywords(synonym)) > 1 {
+				or = append(or, Phrase{Text: synonym})
+			} else if exact.Keyword(synonym) != "" {
+				or = append(or, Word{Text: synonym, Fuzzy: -1})
+			}
+		}
+		if len(or) == 1 {
+			return n
+		}
+		return or
+	}
+
+	return &Query{Raw: q.Raw, Root: expand(q.Root, false)}
+}
+
+func (q *Query) String() string {
+	return q.Root.String()
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.Type != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+//parseOr or := and (OR and)*
+func (p *parser) parseOr() (Node, error) {
+	var nodes Or
+
+	for {
+		n, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, n)
+		if p.peek().Type != tokenOr {
+			break
+		}
+		p.next()
+	}
+
+	if len(nodes) == 1 {
+		return nodes[0], nil
+	}
+	return nodes, nil
+}
+
+//parseAnd and := unary (AND? unary)*
+func (p *parser) parseAnd() (Node, error) {
+	var nodes And
+
+	for {
+		n, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, n)
+
+		if p.peek().Type == tokenAnd {
+			p.next()
+			continue
+		}
+		if !startsOperand(p.peek()) {
+			break
+		}
+	}
+
+	if len(nodes) == 1 {
+		return nodes[0], nil
+	}
+	return nodes, nil
+}
+
+//parseUnary unary := (NOT | '-') unary | primary
+func (p *parser) parseUnary() (Node, error) {
+	switch p.peek().Type {
+	case tokenNot, tokenExclude:
+		p.next()
+		n, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return Not{Node: n}, nil
+	}
+	return p.parsePrimary()
+}
+
+//parsePrimary primary := word | phrase | '(' or ')'
+func (p *parser) parsePrimary() (Node, error) {
+	t := p.next()
+
+	switch t.Type {
+	case tokenWord:
+		var word = Word{Text: t.Value, Fuzzy: -1}
+		if m := fuzzySuffix.FindStringSubmatch(t.Value); m != nil {
+			word.Text, word.Fuzzy = m[1], match.MaxFuzzy
+			if m[2] != "" {
+				word.Fuzzy, _ = strconv.Atoi(m[2])
+			}
+			if word.Fuzzy > match.MaxFuzzy {
+				return nil, &ParseError{Pos: t.Pos, Msg: fmt.Sprintf("'%s' allows more than %d typos", t.Value, match.MaxFuzzy)}
+			}
+		}
+		if exact.Keyword(word.Text) == "" {
+			return nil, &ParseError{Pos: t.Pos, Msg: fmt.Sprintf("'%s' has nothing to search for", t.Value)}
+		}
+		return word, nil
+	case tokenPhrase:
+		if len(exact.Keywords(t.Value)) == 0 {
+			return nil, &ParseError{Pos: t.Pos, Msg: "empty phrase"}
+		}
+		return Phrase{Text: t.Value}, nil
+	case tokenLParen:
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if c := p.next(); c.Type != tokenRParen {
+			return nil, &ParseError{Pos: c.Pos, Msg: fmt.Sprintf("expected ')' found %s", c.Type)}
+		}
+		return n, nil
+	}
+
+	return nil, &ParseError{Pos: t.Pos, Msg: fmt.Sprintf("unexpected %s", t.Type)}
+}
+
+func startsOperand(t token) bool {
+	switch t.Type {
+	case tokenWord, tokenPhrase, tokenLParen, tokenNot, tokenExclude:
+		return true
+	}
+	return false
+}
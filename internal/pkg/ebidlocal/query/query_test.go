@@ -0,0 +1,118 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/match"
+)
+
+func TestParse(t *testing.T) {
+	var tests = []struct {
+		Query    string
+		Expected string
+		Terms    []string
+	}{
+		{Query: "drill", Expected: "drill", Terms: []string{"drill"}},
+		{Query: "nintendo switch", Expected: "nintendo AND switch", Terms: []string{"nintendo", "switch"}},
+		{Query: `"nintendo switch"`, Expected: `"nintendo switch"`, Terms: []string{"nintendo switch"}},
+		{Query: "drill or driver", Expected: "drill OR driver", Terms: []string{"drill", "driver"}},
+		{Query: "switch -light", Expected: "switch AND NOT light", Terms: []string{"switch"}},
+		{Query: "switch not light", Expected: "switch AND NOT light", Terms: []string{"switch"}},
+		{Query: "a b OR c", Expected: "(a AND b) OR c", Terms: []string{"a", "b", "c"}},
+		{Query: "a (b OR c)", Expected: "a AND (b OR c)", Terms: []string{"a", "b", "c"}},
+		{Query: `(drill OR "impact driver") -bits -(bit set)`, Expected: `(drill OR "impact driver") AND NOT bits AND NOT (bit AND set)`, Terms: []string{"drill", "impact driver"}},
+		{Query: "NOT NOT drill", Expected: "NOT NOT drill", Terms: []string{"drill"}},
+		{Query: "x-ray", Expected: "x-ray", Terms: []string{"x-ray"}},
+		{Query: "kayak~ OR canoe~1", Expected: "kayak~ OR canoe~1", Terms: []string{"kayak", "canoe"}},
+		{Query: "kayak~3", Expected: "", Terms: nil},
+		{Query: "~", Expected: "", Terms: nil},
+		{Query: "drill - bits", Expected: "", Terms: nil},
+		{Query: "-drill", Expected: "", Terms: nil},
+		{Query: "", Expected: "", Terms: nil},
+		{Query: "   ", Expected: "", Terms: nil},
+		{Query: `"nintendo switch`, Expected: "", Terms: nil},
+		{Query: `""`, Expected: "", Terms: nil},
+		{Query: "(drill", Expected: "", Terms: nil},
+		{Query: "drill)", Expected: "", Terms: nil},
+		{Query: "drill OR", Expected: "", Terms: nil},
+		{Query: "AND drill", Expected: "", Terms: nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Query, func(t *testing.T) {
+			q, err := Parse(test.Query)
+			if test.Expected == "" {
+				assert.Error(t, err)
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, test.Expected, q.String())
+			assert.Equal(t, test.Terms, q.Terms())
+
+			//The canonical form parses back into the same tree.
+			again, err := Parse(q.String())
+			assert.NoError(t, err)
+			assert.Equal(t, q.String(), again.String())
+		})
+	}
+}
+
+func TestParseError(t *testing.T) {
+	_, err := Parse("drill OR (driver")
+	var parseErr *ParseError
+	if assert.ErrorAs(t, err, &parseErr) {
+		assert.Equal(t, 16, parseErr.Pos)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	var tests = []struct {
+		Query    string
+		Text     string
+		Expected bool
+	}{
+		{Query: "drill", Text: "DeWalt Drill, 20V", Expected: true},
+		{Query: "DRILL", Text: "dewalt drill", Expected: true},
+		{Query: "drill", Text: "drills", Expected: false},
+		{Query: "switch", Text: "Leviton light switch", Expected: true},
+		{Query: "nintendo switch", Text: "Switch console by Nintendo", Expected: true},
+		{Query: "nintendo switch", Text: "Leviton light switch", Expected: false},
+		{Query: `"nintendo switch"`, Text: "Switch console by Nintendo", Expected: false},
+		{Query: `"nintendo switch"`, Text: "Nintendo Switch, Lite", Expected: true},
+		{Query: `"nintendo switch"`, Text: "Nintendo: Switch", Expected: true},
+		{Query: "switch -light", Text: "Leviton light switch", Expected: false},
+		{Query: "switch -light", Text: "Nintendo Switch", Expected: true},
+		{Query: "drill OR driver", Text: "Impact driver", Expected: true},
+		{Query: "(drill OR driver) -bits", Text: "Driver bits", Expected: false},
+		{Query: "x-ray", Text: "X-Ray film viewer", Expected: true},
+		{Query: "x-ray", Text: "Xray film viewer", Expected: true},
+		{Query: "kayak", Text: "Kayk paddle", Expected: false},
+		{Query: "kayak~", Text: "Kayk paddle", Expected: true},
+		{Query: "kayak~0", Text: "Kayk paddle", Expected: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Query+"/"+test.Text, func(t *testing.T) {
+			q, err := Parse(test.Query)
+			if assert.NoError(t, err) {
+				assert.Equal(t, test.Expected, q.Match(test.Text))
+			}
+		})
+	}
+}
+
+func TestMatchDocument(t *testing.T) {
+	q, err := Parse(`"pokemon trading card" -sleeve~0`)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var m = match.New(match.Options{Stem: true, Fold: true, Fuzzy: 1})
+	assert.True(t, q.MatchDocument(m.Document("UNOPENED POKÉMON TRADING CARDS")))
+	assert.True(t, q.MatchDocument(m.Document("POKÉMON TRADING CARDS IN SLEVES")), "Per word fuzzy overrides the options")
+	assert.False(t, q.MatchDocument(m.Document("POKÉMON TRADING CARDS IN SLEEVES")))
+}
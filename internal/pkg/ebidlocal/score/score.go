@@ -0,0 +1,188 @@
+//Package score ranks the items matched by a watch list query, higher scores are more relevant.
+package score
+
+import (
+	"math"
+	"time"
+
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/match"
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/model"
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/query"
+)
+
+//Weights how much each signal adds to a score. Every signal is between 0 and 1 before it is weighted.
+type Weights struct {
+	//Name fraction of the query's terms found in the item name.
+	Name float64 `json:"name"`
+	//Description fraction of the query's terms found in the description.
+	Description float64 `json:"description"`
+	//Proximity how close together the query's terms are in the description.
+	Proximity float64 `json:"proximity"`
+	//Keywords fraction of the watch list's queries the item matches.
+	Keywords float64 `json:"keywords"`
+	//Closing how soon the auction for the item closes, an item closing in a day scores one half.
+	Closing float64 `json:"closing"`
+	//Price how low the current bid is.
+	Price float64 `json:"price"`
+}
+
+var DefaultWeights = Weights{
+	Name:        4,
+	Description: 2,
+	Proximity:   2,
+	Keywords:    1,
+	Closing:     1,
+	Price:       0.5,
+}
+
+//PriceScale the current bid at which the price signal is one half.
+const PriceScale = 100.0
+
+//Scorer scores items, the time to close is from Now so an item scores the same every time it is scored for the same scan.
+type Scorer struct {
+	Weights Weights
+	Matcher *match.Matcher
+	Now     time.Time
+	queries []*query.Query
+}
+
+//New queries are all the queries of the watch list being scored, items matching more of them score higher. Queries that do not parse are ignored. now is when the items were searched for.
+func New(weights Weights, matcher *match.Matcher, queries []string, now time.Time) *Scorer {
+	if matcher == nil {
+		matcher = match.New(match.Options{})
+	}
+
+	var scorer = Scorer{
+		Weights: weights,
+		Matcher: matcher,
+		Now:     now,
+	}
+	for _, raw := range queries {
+		if q, err := query.Parse(raw); err == nil {
+			scorer.queries = append(scorer.queries, q)
+		}
+	}
+	return &scorer
+}
+
+//Score an item by how well its keywords, the queries it was found by, match it. Keywords that do not parse as queries add nothing.
+func (s *Scorer) Score(item model.AuctionItem) float64 {
+	var name = s.Matcher.Document(item.ItemName)
+	var description = s.Matcher.Document(item.Description + "\n" + item.ExtendedDescription + "\n" + item.OriginalName)
+	var nameHits, descriptionHits, proximity, matched float64
+
+	for _, raw := range item.Keywords {
+		q, err := query.Parse(raw)
+		if err != nil {
+			continue
+		}
+
+		var keywords [][]string
+		for _, term := range q.Terms() {
+			keywords = append(keywords, s.Matcher.Keywords(term))
+		}
+		nameHits = math.Max(nameHits, hits(name, keywords))
+		descriptionHits = math.Max(descriptionHits, hits(description, keywords))
+		proximity = math.Max(proximity, closeness(description, keywords))
+	}
+
+	if len(s.queries) > 0 {
+		doc := s.Matcher.Document(item.SearchableDescription())
+		for _, q := range s.queries {
+			if q.MatchDocument(doc) {
+				matched++
+			}
+		}
+		matched /= float64(len(s.queries))
+	}
+
+	return s.Weights.Name*nameHits +
+		s.Weights.Description*descriptionHits +
+		s.Weights.Proximity*proximity +
+		s.Weights.Keywords*matched +
+		s.Weights.Closing*s.closing(item.EndDate) +
+		s.Weights.Price*price(item.CurrentBidAmount)
+}
+
+//Items sets the Score of each item.
+func (s *Scorer) Items(items []model.AuctionItem) []model.AuctionItem {
+	for i := range items {
+		items[i].Score = s.Score(items[i])
+	}
+	return items
+}
+
+//hits fraction of the terms found in the document.
+func hits(doc *match.Document, terms [][]string) float64 {
+	if len(terms) == 0 {
+		return 0
+	}
+	var found float64
+	for _, term := range terms {
+		if doc.Contains(term, -1) {
+			found++
+		}
+	}
+	return found / float64(len(terms))
+}
+
+//closeness 1 when the terms are next to each other, falling off as the words between them grow. 0 unless more than one term is found.
+func closeness(doc *match.Document, terms [][]string) float64 {
+	var positions [][]int
+	var words int
+	for _, term := range terms {
+		if p := doc.Positions(term, -1); len(p) > 0 {
+			positions = append(positions, p)
+			words += len(term)
+		}
+	}
+	if len(positions) < 2 {
+		return 0
+	}
+
+	span := smallestSpan(positions)
+	return 1 / (1 + math.Max(0, float64(span-words)))
+}
+
+//smallestSpan the fewest positions covering one position from each list.
+func smallestSpan(positions [][]int) int {
+	var best = math.MaxInt32
+	var idx = make([]int, len(positions))
+
+	for {
+		low, high, lowList := math.MaxInt32, -1, 0
+		for i, p := range positions {
+			v := p[idx[i]]
+			if v < low {
+				low, lowList = v, i
+			}
+			if v > high {
+				high = v
+			}
+		}
+		if high-low+1 < best {
+			best = high - low + 1
+		}
+		if idx[lowList]++; idx[lowList] >= len(positions[lowList]) {
+			return best
+		}
+	}
+}
+
+func (s *Scorer) closing(end time.Time) float64 {
+	if end.IsZero() {
+		return 0
+	}
+	left := end.Sub(s.Now).Hours()
+	if left < 0 {
+		return 0
+	}
+	return 1 / (1 + left/24)
+}
+
+func price(bid float64) float64 {
+	if bid < 0 {
+		bid = 0
+	}
+	return 1 / (1 + bid/PriceScale)
+}
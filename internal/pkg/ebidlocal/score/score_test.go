@@ -0,0 +1,95 @@
+package score
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/model"
+)
+
+var now = time.Date(2022, 1, 10, 12, 0, 0, 0, time.UTC)
+
+func newScorer(queries ...string) *Scorer {
+	return New(DefaultWeights, nil, queries, now)
+}
+
+func TestScore(t *testing.T) {
+	var tests = []struct {
+		Name          string
+		Queries       []string
+		Higher, Lower model.AuctionItem
+	}{
+		{
+			Name:   "Keyword in the name",
+			Higher: model.AuctionItem{ItemName: "Kenmore dryer", Keywords: []string{"dryer"}},
+			Lower:  model.AuctionItem{ItemName: "Appliances", Description: "Kenmore dryer", Keywords: []string{"dryer"}},
+		},
+		{
+			Name:   "More of the query's terms",
+			Higher: model.AuctionItem{Description: "Kenmore washing machine", Keywords: []string{"kenmore OR washing"}},
+			Lower:  model.AuctionItem{Description: "Kenmore dryer", Keywords: []string{"kenmore OR washing"}},
+		},
+		{
+			Name:   "Terms closer together",
+			Higher: model.AuctionItem{Description: "Kenmore washing machine with instructions", Keywords: []string{"washing instructions"}},
+			Lower:  model.AuctionItem{Description: "Washing machine model 110, runs, with instructions", Keywords: []string{"washing instructions"}},
+		},
+		{
+			Name:    "Matches more of the watch list's queries",
+			Queries: []string{"dryer", "kenmore"},
+			Higher:  model.AuctionItem{Description: "Kenmore dryer", Keywords: []string{"dryer"}},
+			Lower:   model.AuctionItem{Description: "Whirlpool dryer", Keywords: []string{"dryer"}},
+		},
+		{
+			Name:   "Closes sooner",
+			Higher: model.AuctionItem{Description: "dryer", Keywords: []string{"dryer"}, EndDate: now.Add(2 * time.Hour)},
+			Lower:  model.AuctionItem{Description: "dryer", Keywords: []string{"dryer"}, EndDate: now.Add(72 * time.Hour)},
+		},
+		{
+			Name:   "Already closed",
+			Higher: model.AuctionItem{Description: "dryer", Keywords: []string{"dryer"}, EndDate: now.Add(72 * time.Hour)},
+			Lower:  model.AuctionItem{Description: "dryer", Keywords: []string{"dryer"}, EndDate: now.Add(-time.Hour)},
+		},
+		{
+			Name:   "Cheaper",
+			Higher: model.AuctionItem{Description: "dryer", Keywords: []string{"dryer"}, CurrentBidAmount: 10},
+			Lower:  model.AuctionItem{Description: "dryer", Keywords: []string{"dryer"}, CurrentBidAmount: 150},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			s := newScorer(test.Queries...)
+			assert.Greater(t, s.Score(test.Higher), s.Score(test.Lower))
+		})
+	}
+}
+
+func TestScoreItemsSort(t *testing.T) {
+	var items = []model.AuctionItem{
+		{Id: "1", Description: "Appliances, Kenmore dryer", Keywords: []string{"dryer"}},
+		{Id: "2", ItemName: "Kenmore dryer", Keywords: []string{"dryer"}},
+		{Id: "3", Description: "dryer", Keywords: []string{"dryer"}},
+	}
+
+	sort.Sort(model.ByScore(newScorer("dryer").Items(items)))
+
+	assert.Equal(t, "2", items[0].Id)
+	assert.Equal(t, items[1].Score, items[2].Score)
+	assert.Equal(t, []string{"1", "3"}, []string{items[1].Id, items[2].Id}, "Ties are sorted by ID")
+}
+
+func TestSmallestSpan(t *testing.T) {
+	assert.Equal(t, 2, smallestSpan([][]int{{0, 10}, {1}}))
+	assert.Equal(t, 3, smallestSpan([][]int{{0, 8}, {4, 10}, {9}}))
+}
+
+func TestScoreIsFromTheScanTime(t *testing.T) {
+	var item = model.AuctionItem{ItemName: "Kenmore dryer", Keywords: []string{"dryer"}, EndDate: now.Add(24 * time.Hour)}
+
+	assert.Equal(t, newScorer("dryer").Score(item), newScorer("dryer").Score(item), "Scored for the same scan the score does not change")
+	assert.Greater(t, New(DefaultWeights, nil, []string{"dryer"}, now.Add(12*time.Hour)).Score(item), newScorer("dryer").Score(item), "Closer to closing scores higher")
+}
@@ -0,0 +1,99 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/model"
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/query"
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/synonym"
+)
+
+//QueryTerms maps watch list queries to the terms the auction site is searched for. The site only understands plain keywords so a query is searched by its terms, then the items found are tagged with the query again to be filtered by filter.ByQuery.
+type QueryTerms struct {
+	Terms   []string
+	queries map[string][]string
+	//typed the query as it was typed for each expanded query.
+	typed map[string]string
+}
+
+//NewQueryTerms builds the search terms for the queries. Queries that do not parse are left out and reported in the returned error, the rest can still be searched.
+func NewQueryTerms(queries []string) (QueryTerms, error) {
+	return NewSynonymQueryTerms(queries, nil)
+}
+
+//NewSynonymQueryTerms same as NewQueryTerms, each query is expanded with the synonyms of its terms before it is searched.
+func NewSynonymQueryTerms(queries []string, synonyms *synonym.Dictionary) (QueryTerms, error) {
+	var errs []error
+	var qt = QueryTerms{
+		queries: make(map[string][]string),
+		typed:   make(map[string]string),
+	}
+
+	for _, raw := range queries {
+		q, err := query.Parse(raw)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("query '%s': %w", raw, err))
+			continue
+		}
+
+		//Queries without synonyms are kept as they were typed.
+		var expanded = raw
+		if e := synonyms.Expand(q); e.String() != q.String() {
+			q, expanded = e, e.String()
+		}
+		if _, exists := qt.typed[expanded]; !exists {
+			qt.typed[expanded] = raw
+		}
+		for _, term := range q.Terms() {
+			if _, exists := qt.queries[term]; !exists {
+				qt.Terms = append(qt.Terms, term)
+			}
+			qt.queries[term] = appendUnique(qt.queries[term], expanded)
+		}
+	}
+
+	return qt, errors.Join(errs...)
+}
+
+//Tag replaces the keywords of each item, the term it was found by, with the queries that term belongs to and sets its Keyword to the query as it was typed. An item found by more than one term of the same query is only sent once for that query.
+func (qt QueryTerms) Tag(in <-chan model.AuctionItem) <-chan model.AuctionItem {
+	var out = make(chan model.AuctionItem)
+
+	go func() {
+		defer close(out)
+		var seen = make(map[string]struct{})
+
+		for item := range in {
+			var queries []string
+			for _, term := range item.Keywords {
+				for _, raw := range qt.queries[term] {
+					queries = appendUnique(queries, raw)
+				}
+			}
+			for _, raw := range queries {
+				key := raw + "\x00" + item.ParentAuctionID + "\x00" + item.ID()
+				if _, exists := seen[key]; exists {
+					continue
+				}
+				seen[key] = struct{}{}
+
+				tagged := item
+				tagged.Keywords = []string{raw}
+				tagged.Keyword = qt.typed[raw]
+				out <- tagged
+			}
+		}
+	}()
+
+	return out
+}
+
+func appendUnique(s []string, v string) []string {
+	for _, e := range s {
+		if e == v {
+			return s
+		}
+	}
+	return append(s, v)
+}
@@ -0,0 +1,57 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/model"
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/synonym"
+)
+
+func TestNewQueryTerms(t *testing.T) {
+	qt, err := NewQueryTerms([]string{"drill", `"nintendo switch" -lite`, "drill OR driver", "(broken"})
+
+	assert.Error(t, err, "Invalid queries should be reported")
+	assert.Equal(t, []string{"drill", "nintendo switch", "driver"}, qt.Terms)
+}
+
+func TestQueryTermsTag(t *testing.T) {
+	qt, _ := NewQueryTerms([]string{"drill", "drill OR driver"})
+	var in = make(chan model.AuctionItem)
+
+	go func() {
+		defer close(in)
+		in <- model.AuctionItem{Id: "1", ParentAuctionID: "a", Keywords: []string{"drill"}}
+		in <- model.AuctionItem{Id: "1", ParentAuctionID: "a", Keywords: []string{"driver"}}
+		in <- model.AuctionItem{Id: "2", ParentAuctionID: "a", Keywords: []string{"unknown"}}
+	}()
+
+	var tagged []string
+	for item := range qt.Tag(in) {
+		tagged = append(tagged, item.Id+":"+item.Keywords[0])
+	}
+	assert.Equal(t, []string{"1:drill", "1:drill OR driver"}, tagged)
+}
+
+func TestSynonymQueryTermsTag(t *testing.T) {
+	qt, err := NewSynonymQueryTerms([]string{"couch", "chair"}, synonym.New([][]string{{"couch", "sectional"}}))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"couch", "sectional", "chair"}, qt.Terms)
+
+	var in = make(chan model.AuctionItem)
+	go func() {
+		defer close(in)
+		in <- model.AuctionItem{Id: "1", Keywords: []string{"sectional"}}
+		in <- model.AuctionItem{Id: "2", Keywords: []string{"chair"}}
+	}()
+
+	var tagged []model.AuctionItem
+	for item := range qt.Tag(in) {
+		tagged = append(tagged, item)
+	}
+	assert.Equal(t, []string{"couch OR sectional"}, tagged[0].Keywords, "Items are matched by the expanded query")
+	assert.Equal(t, "couch", tagged[0].Keyword, "Items are annotated with the query as it was typed")
+	assert.Equal(t, []string{"chair"}, tagged[1].Keywords)
+	assert.Equal(t, "chair", tagged[1].Keyword)
+}
@@ -0,0 +1,29 @@
+package search
+
+import (
+	"testing"
+
+	ebidhttp "github.com/scirelli/auction-ebidlocal-search/internal/pkg/net/http"
+	"github.com/scirelli/auction-ebidlocal-search/test/fixtures"
+)
+
+//fuzzCorpus the v2 captures.
+func fuzzCorpus(f *testing.F) []string {
+	return fixtures.Corpus(f,
+		"../../../../../test/fixtures/internal/pkg/ebidlocal/search/v2/*.html",
+		"../../../../../test/fixtures/golden/extract/v2/*.html",
+		"../../../../../test/fixtures/responses/doc_*",
+	)
+}
+
+//FuzzSearchAuction fuzzes splitting a search response into rows.
+func FuzzSearchAuction(f *testing.F) {
+	fixtures.FuzzSearchAuction(f, fuzzCorpus(f), func(c ebidhttp.HTTPClient) (old ebidhttp.HTTPClient) {
+		old, Client = Client, c
+		return old
+	}, SearchAuction)
+}
+
+func FuzzFullyQualifyLinks(f *testing.F) {
+	fixtures.FuzzFullyQualifyLinks(f, fuzzCorpus(f), AuctionSite, fullyQualifyLinks)
+}
@@ -8,11 +8,11 @@ import (
 
 type NopWatchlistStore struct{}
 
-func (s *NopWatchlistStore) SaveWatchlist(ctx context.Context, watchlist model.Watchlist) (string, error) {
+func (s *NopWatchlistStore) SaveWatchlist(ctx context.Context, watchlist model.WatchlistDefinition) (string, error) {
 	return "", nil
 }
-func (s *NopWatchlistStore) LoadWatchlist(ctx context.Context, watchlistID string) (model.Watchlist, error) {
-	return model.Watchlist{}, nil
+func (s *NopWatchlistStore) LoadWatchlist(ctx context.Context, watchlistID string) (model.WatchlistDefinition, error) {
+	return model.WatchlistDefinition{}, nil
 }
 func (s *NopWatchlistStore) DeleteWatchlist(ctx context.Context, watchlistID string) error {
 	return nil
@@ -29,3 +29,9 @@ func (s *NopWatchlistContentStore) LoadWatchlistContent(ctx context.Context, wat
 func (s *NopWatchlistContentStore) DeleteWatchlistContent(ctx context.Context, watchlistContentID string) error {
 	return nil
 }
+func (s *NopWatchlistContentStore) SaveWatchlistScanState(ctx context.Context, state *model.WatchlistScanState) error {
+	return nil
+}
+func (s *NopWatchlistContentStore) LoadWatchlistScanState(ctx context.Context, watchlistID string) (*model.WatchlistScanState, error) {
+	return &model.WatchlistScanState{WatchlistID: watchlistID}, nil
+}
@@ -0,0 +1,146 @@
+package fs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/model"
+	fileutils "github.com/scirelli/auction-ebidlocal-search/internal/pkg/fileUtils"
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/log"
+)
+
+func NewItemArchiveStore(config ItemArchiveStoreConfig, logger log.Logger) *ItemArchiveStore {
+	if logger == nil {
+		logger = log.New("ItemArchiveStore", log.DEFAULT_LOG_LEVEL)
+	}
+
+	if config.ContentPath == "" {
+		config.ContentPath = "."
+	}
+	if config.ArchiveDir == "" {
+		config.ArchiveDir = filepath.Join(config.ContentPath, "archive", "items")
+		logger.Infof("Defaulting archive dir to '%s'\n", config.ArchiveDir)
+	}
+
+	return &ItemArchiveStore{
+		Config: config,
+		Logger: logger,
+	}
+}
+
+//ItemArchiveStore keeps each archived item in its own file, named for the item's ID, in the archive directory.
+type ItemArchiveStore struct {
+	Config ItemArchiveStoreConfig
+	Logger log.Logger
+}
+
+type ItemArchiveStoreConfig struct {
+	ContentPath string `json:"contentPath"`
+	ArchiveDir  string `json:"archiveDir"`
+}
+
+//ArchiveItems holds the lock on the archive directory while it merges the items with the ones already archived.
+func (ia *ItemArchiveStore) ArchiveItems(ctx context.Context, items []model.ArchivedItem) error {
+	if err := os.MkdirAll(ia.Config.ArchiveDir, 0775); err != nil {
+		return err
+	}
+	unlock, err := fileutils.Lock(ia.Config.ArchiveDir)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	for _, item := range items {
+		filePath, err := ia.itemFilePath(item.ID())
+		if err != nil {
+			return err
+		}
+		if archived, err := ia.loadArchivedItem(filePath); err == nil {
+			archived.Merge(item)
+			item = *archived
+		} else if !os.IsNotExist(err) {
+			ia.Logger.Errorf("ItemArchiveStore.ArchiveItems: Replacing unreadable item '%s'; %s", item.ID(), err)
+		}
+		b, err := json.Marshal(item)
+		if err != nil {
+			return err
+		}
+		if err = fileutils.WriteFile(filePath, b, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ia *ItemArchiveStore) LoadArchivedItem(ctx context.Context, itemID string) (*model.ArchivedItem, error) {
+	filePath, err := ia.itemFilePath(itemID)
+	if err != nil {
+		return nil, err
+	}
+	return ia.loadArchivedItem(filePath)
+}
+
+//QueryArchivedItems reads every archived item, unreadable items are skipped.
+func (ia *ItemArchiveStore) QueryArchivedItems(ctx context.Context, query model.ArchiveQuery) ([]model.ArchivedItem, error) {
+	var items []model.ArchivedItem
+
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(ia.Config.ArchiveDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, entry := range entries {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") || fileutils.IsTemp(entry.Name()) {
+			continue
+		}
+		item, err := ia.loadArchivedItem(filepath.Join(ia.Config.ArchiveDir, entry.Name()))
+		if err != nil {
+			ia.Logger.Errorf("ItemArchiveStore.QueryArchivedItems: Skipping '%s'; %s", entry.Name(), err)
+			continue
+		}
+		items = append(items, *item)
+	}
+	return model.SelectArchivedItems(items, query)
+}
+
+//Recover removes the temp files of writes that never finished from the archive directory. Returns the archived items that do not parse, they are replaced the next time the item is archived.
+func (ia *ItemArchiveStore) Recover(ctx context.Context) (corrupt []string, err error) {
+	removed, corrupt, err := fileutils.Recover(ia.Config.ArchiveDir)
+	for _, path := range removed {
+		ia.Logger.Warnf("ItemArchiveStore.Recover: Removed unfinished write '%s'", path)
+	}
+	return corrupt, err
+}
+
+func (ia *ItemArchiveStore) loadArchivedItem(filePath string) (*model.ArchivedItem, error) {
+	var item model.ArchivedItem
+
+	b, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(b, &item); err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+//itemFilePath the item's file, an ID that is not a single path element is rejected.
+func (ia *ItemArchiveStore) itemFilePath(itemID string) (string, error) {
+	if itemID == "" || itemID != filepath.Base(itemID) || itemID == "." || itemID == ".." {
+		return "", fmt.Errorf("invalid item id '%s'", itemID)
+	}
+	return filepath.Join(ia.Config.ArchiveDir, itemID+".json"), nil
+}
@@ -0,0 +1,48 @@
+package fs
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/model"
+)
+
+func TestItemArchiveStore(t *testing.T) {
+	var ctx = context.Background()
+	var store = NewItemArchiveStore(ItemArchiveStoreConfig{ArchiveDir: t.TempDir()}, nil)
+	var closedAt = time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	items, err := store.QueryArchivedItems(ctx, model.ArchiveQuery{})
+	assert.NoError(t, err)
+	assert.Empty(t, items)
+
+	assert.NoError(t, store.ArchiveItems(ctx, []model.ArchivedItem{
+		model.NewArchivedItem(model.AuctionItem{Id: "1", ItemName: "drill", CurrentBidAmount: 20, TotalBids: 2}, "wl1", closedAt),
+		model.NewArchivedItem(model.AuctionItem{Id: "2", ItemName: "saw", CurrentBidAmount: 50, TotalBids: 4}, "wl1", closedAt),
+	}))
+	assert.NoError(t, store.ArchiveItems(ctx, []model.ArchivedItem{
+		model.NewArchivedItem(model.AuctionItem{Id: "1", ItemName: "drill", CurrentBidAmount: 25, TotalBids: 3}, "wl2", closedAt),
+	}))
+
+	item, err := store.LoadArchivedItem(ctx, "1")
+	assert.NoError(t, err)
+	assert.Equal(t, float64(25), item.FinalBid)
+	assert.Equal(t, []string{"wl1", "wl2"}, item.WatchlistIDs)
+
+	items, err = store.QueryArchivedItems(ctx, model.ArchiveQuery{Keyword: "drill"})
+	assert.NoError(t, err)
+	if assert.Len(t, items, 1) {
+		assert.Equal(t, "1", items[0].ID())
+	}
+
+	_, err = store.LoadArchivedItem(ctx, "3")
+	assert.True(t, os.IsNotExist(err))
+	_, err = store.LoadArchivedItem(ctx, "../1")
+	assert.Error(t, err)
+	_, err = store.QueryArchivedItems(ctx, model.ArchiveQuery{MinPrice: -1})
+	assert.Error(t, err)
+}
@@ -0,0 +1,150 @@
+package fs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/model"
+	fileutils "github.com/scirelli/auction-ebidlocal-search/internal/pkg/fileUtils"
+)
+
+//snapshotIDFormat snapshots are named for when they were saved so their names sort oldest first.
+const snapshotIDFormat = "20060102T150405.000000000Z"
+
+//ListWatchlistSnapshots the watch list's snapshots oldest first, none if it has no history.
+func (wc *WatchlistContentStore) ListWatchlistSnapshots(ctx context.Context, watchlistID string) ([]model.WatchlistSnapshot, error) {
+	var snapshots = []model.WatchlistSnapshot{}
+
+	historyDir, err := wc.historyDir(watchlistID)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(historyDir)
+	if os.IsNotExist(err) {
+		return snapshots, nil
+	} else if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		id := strings.TrimSuffix(entry.Name(), ".json")
+		timestamp, err := time.Parse(snapshotIDFormat, id)
+		if entry.IsDir() || err != nil {
+			continue
+		}
+		snapshots = append(snapshots, model.WatchlistSnapshot{ID: id, Timestamp: timestamp})
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Timestamp.Before(snapshots[j].Timestamp)
+	})
+	return snapshots, nil
+}
+
+func (wc *WatchlistContentStore) LoadWatchlistSnapshot(ctx context.Context, watchlistID string, snapshotID string) (*model.WatchlistContent, error) {
+	var content model.WatchlistContent
+
+	if _, err := time.Parse(snapshotIDFormat, snapshotID); err != nil {
+		return nil, fmt.Errorf("invalid snapshot id '%s'", snapshotID)
+	}
+	historyDir, err := wc.historyDir(watchlistID)
+	if err != nil {
+		return nil, err
+	}
+	b, err := ioutil.ReadFile(filepath.Join(historyDir, snapshotID+".json"))
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(b, &content); err != nil {
+		return nil, err
+	}
+	return &content, nil
+}
+
+//saveSnapshot adds the content to the watch list's history, then removes the snapshots the retention policy no longer keeps. The caller holds the lock on the watch list's directory.
+func (wc *WatchlistContentStore) saveSnapshot(ctx context.Context, content *model.WatchlistContent) error {
+	historyDir, err := wc.historyDir(content.GetWatchlistID())
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(historyDir, 0775); err != nil {
+		return err
+	}
+
+	timestamp := content.GetTimestamp()
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+	b, err := json.Marshal(content)
+	if err != nil {
+		return err
+	}
+	//Snapshots are never overwritten.
+	if err = fileutils.WriteNewFile(filepath.Join(historyDir, timestamp.UTC().Format(snapshotIDFormat)+".json"), b, 0644); err != nil {
+		return err
+	}
+
+	return wc.pruneHistory(ctx, content.GetWatchlistID(), time.Now())
+}
+
+//pruneHistory removes the snapshots beyond HistoryMaxSnapshots and those older than HistoryMaxAge, the latest snapshot is always kept.
+func (wc *WatchlistContentStore) pruneHistory(ctx context.Context, watchlistID string, now time.Time) error {
+	snapshots, err := wc.ListWatchlistSnapshots(ctx, watchlistID)
+	if err != nil || len(snapshots) < 2 {
+		return err
+	}
+	historyDir, _ := wc.historyDir(watchlistID)
+	maxAge := time.Duration(wc.Config.HistoryMaxAge) * time.Second
+
+	for i, snapshot := range snapshots[:len(snapshots)-1] {
+		tooMany := wc.Config.HistoryMaxSnapshots > 0 && len(snapshots)-i > wc.Config.HistoryMaxSnapshots
+		tooOld := maxAge > 0 && now.Sub(snapshot.Timestamp) > maxAge
+		if !tooMany && !tooOld {
+			continue
+		}
+		if err := os.Remove(filepath.Join(historyDir, snapshot.ID+".json")); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+//restoreContent replaces the watch list's content with its latest snapshot.
+func (wc *WatchlistContentStore) restoreContent(ctx context.Context, watchlistID string) error {
+	snapshots, err := wc.ListWatchlistSnapshots(ctx, watchlistID)
+	if err != nil {
+		return err
+	}
+	if len(snapshots) == 0 {
+		return fmt.Errorf("'%s' has no snapshots", watchlistID)
+	}
+	content, err := wc.LoadWatchlistSnapshot(ctx, watchlistID, snapshots[len(snapshots)-1].ID)
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(content)
+	if err != nil {
+		return err
+	}
+
+	dir, _ := watchlistDir(wc.Config.WatchlistDir, watchlistID)
+	unlock, err := fileutils.Lock(dir)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return fileutils.WriteFile(wc.watchlistDataFilePathFromID(watchlistID), b, 0644)
+}
+
+func (wc *WatchlistContentStore) historyDir(watchlistID string) (string, error) {
+	dir, err := watchlistDir(wc.Config.WatchlistDir, watchlistID)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, wc.Config.HistoryDirName), nil
+}
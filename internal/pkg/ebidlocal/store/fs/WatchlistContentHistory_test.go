@@ -0,0 +1,77 @@
+package fs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/model"
+)
+
+func TestWatchlistContentStoreHistory(t *testing.T) {
+	var ctx = context.Background()
+	var store = NewWatchlistContentStore(WatchlistContentStoreConfig{WatchlistDir: t.TempDir(), HistoryMaxSnapshots: 3, HistoryMaxAge: 24 * 60 * 60})
+	var now = time.Now().UTC()
+
+	assert.NoError(t, os.MkdirAll(filepath.Join(store.Config.WatchlistDir, "abc"), 0775))
+	snapshots, err := store.ListWatchlistSnapshots(ctx, "abc")
+	assert.NoError(t, err)
+	assert.Empty(t, snapshots)
+
+	//Two days old, removed by age once there is a newer snapshot.
+	_, err = store.SaveWatchlistContent(ctx, &model.WatchlistContent{WatchlistID: "abc", Timestamp: now.Add(-48 * time.Hour)})
+	assert.NoError(t, err)
+	snapshots, _ = store.ListWatchlistSnapshots(ctx, "abc")
+	assert.Len(t, snapshots, 1, "the latest snapshot is kept however old it is")
+
+	for i := 4; i > 0; i-- {
+		_, err = store.SaveWatchlistContent(ctx, &model.WatchlistContent{WatchlistID: "abc", Timestamp: now.Add(-time.Duration(i) * time.Minute)})
+		assert.NoError(t, err)
+	}
+
+	snapshots, err = store.ListWatchlistSnapshots(ctx, "abc")
+	assert.NoError(t, err)
+	if assert.Len(t, snapshots, 3) {
+		assert.True(t, snapshots[0].Timestamp.Equal(now.Add(-3*time.Minute)))
+		assert.True(t, snapshots[2].Timestamp.Equal(now.Add(-1*time.Minute)))
+	}
+
+	content, err := store.LoadWatchlistSnapshot(ctx, "abc", snapshots[2].ID)
+	assert.NoError(t, err)
+	assert.True(t, content.Timestamp.Equal(now.Add(-1*time.Minute)))
+
+	_, err = store.LoadWatchlistSnapshot(ctx, "abc", "../models")
+	assert.Error(t, err)
+	_, err = store.LoadWatchlistSnapshot(ctx, "../abc", snapshots[2].ID)
+	assert.Error(t, err)
+	_, err = store.LoadWatchlistSnapshot(ctx, "abc", now.Format(snapshotIDFormat))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestWatchlistContentStoreRecover(t *testing.T) {
+	var ctx = context.Background()
+	var store = NewWatchlistContentStore(WatchlistContentStoreConfig{WatchlistDir: t.TempDir()})
+	var dir = filepath.Join(store.Config.WatchlistDir, "abc")
+
+	assert.NoError(t, os.MkdirAll(dir, 0775))
+	_, err := store.SaveWatchlistContent(ctx, &model.WatchlistContent{WatchlistID: "abc", Timestamp: time.Now(), AuctionItems: []model.AuctionItem{{Id: "1"}}})
+	assert.NoError(t, err)
+	//A write that was cut off before writes were atomic.
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, store.Config.DataFileName), []byte(`{"id":"`), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, store.Config.ChangesetFileName), []byte(`{`), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "data.json"), []byte(`[`), 0644))
+
+	corrupt, err := store.Recover(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "data.json")}, corrupt, "Definitions can not be restored")
+
+	content, err := store.LoadWatchlistContent(ctx, "abc")
+	assert.NoError(t, err)
+	assert.Len(t, content.AuctionItems, 1)
+	_, err = os.Stat(filepath.Join(dir, store.Config.ChangesetFileName))
+	assert.True(t, os.IsNotExist(err))
+}
@@ -8,15 +8,28 @@ import (
 	"path/filepath"
 
 	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/model"
+	fileutils "github.com/scirelli/auction-ebidlocal-search/internal/pkg/fileUtils"
 	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/log"
 )
 
+//legacyHashFileName the file an older version kept the content ID in, changes are found by diffing the saved content now.
+const legacyHashFileName = "hash"
+
 func NewWatchlistContentStore(config WatchlistContentStoreConfig) *WatchlistContentStore {
 	var logger = log.New("WatchlistContentStore", log.DEFAULT_LOG_LEVEL)
 
 	if config.DataFileName == "" {
 		config.DataFileName = "models.json"
 	}
+	if config.ChangesetFileName == "" {
+		config.ChangesetFileName = "changes.json"
+	}
+	if config.ScanStateFileName == "" {
+		config.ScanStateFileName = "scan.json"
+	}
+	if config.HistoryDirName == "" {
+		config.HistoryDirName = "history"
+	}
 	if config.ContentPath == "" {
 		config.ContentPath = "."
 		logger.Infof("Defaulting content path dir to '%s'\n", config.ContentPath)
@@ -41,21 +54,41 @@ type WatchlistContentStoreConfig struct {
 	ContentPath  string `json:"contentPath"`
 	WatchlistDir string `json:"watchlistDir"`
 	DataFileName string `json:"dataFileName"`
+	//ChangesetFileName the file in a watch list's directory the changes to its content are saved to.
+	ChangesetFileName string `json:"changesetFileName"`
+	//ScanStateFileName the file in a watch list's directory the updater's state is saved to, see model.WatchlistScanState.
+	ScanStateFileName string `json:"scanStateFileName"`
+	//HistoryDirName the directory in a watch list's directory every version of its content is saved to.
+	HistoryDirName string `json:"historyDirName"`
+	//HistoryMaxSnapshots the most snapshots kept of a watch list, zero keeps them all.
+	HistoryMaxSnapshots int `json:"historyMaxSnapshots"`
+	//HistoryMaxAge seconds a snapshot is kept, zero keeps them forever. The latest snapshot is always kept.
+	HistoryMaxAge int64 `json:"historyMaxAgeSeconds"`
 }
 
+//SaveWatchlistContent replaces the watch list's content, and adds it to its history, while holding the lock on the watch list's directory.
 func (wc *WatchlistContentStore) SaveWatchlistContent(ctx context.Context, watchlistContent *model.WatchlistContent) (string, error) {
-	var file *os.File
-	var err error
+	dir, err := watchlistDir(wc.Config.WatchlistDir, watchlistContent.GetWatchlistID())
+	if err != nil {
+		return "", err
+	}
+	b, err := json.Marshal(watchlistContent)
+	if err != nil {
+		return "", err
+	}
 
-	if file, err = os.Create(wc.watchlistDataFilePathFromID(watchlistContent.GetWatchlistID())); err != nil {
+	unlock, err := fileutils.Lock(dir)
+	if err != nil {
 		return "", err
 	}
-	defer file.Close()
+	defer unlock()
 
-	enc := json.NewEncoder(file)
-	if err = enc.Encode(watchlistContent); err != nil {
+	if err = fileutils.WriteFile(wc.watchlistDataFilePathFromID(watchlistContent.GetWatchlistID()), b, 0644); err != nil {
 		return "", err
 	}
+	if err = wc.saveSnapshot(ctx, watchlistContent); err != nil {
+		wc.logger.Errorf("WatchlistContentStore.SaveWatchlistContent: Failed to save a snapshot of '%s'; %s", watchlistContent.GetWatchlistID(), err)
+	}
 
 	return watchlistContent.ID(), nil
 }
@@ -100,6 +133,118 @@ func (wc *WatchlistContentStore) DeleteWatchlistContent(ctx context.Context, wat
 	return os.Remove(wc.watchlistDataFilePathFromID(watchlistContentID))
 }
 
+//Recover removes the temp files of writes that never finished from the watch list directory and restores content that does not parse from the watch list's latest snapshot. Changes and scan states that do not parse are removed, the next update saves new ones. Returns the files that are still corrupt, such as watch list definitions.
+func (wc *WatchlistContentStore) Recover(ctx context.Context) (corrupt []string, err error) {
+	removed, found, err := fileutils.Recover(wc.Config.WatchlistDir)
+	for _, path := range removed {
+		wc.logger.Warnf("WatchlistContentStore.Recover: Removed unfinished write '%s'", path)
+	}
+	if err != nil {
+		return found, err
+	}
+
+	for _, path := range found {
+		switch filepath.Base(path) {
+		case wc.Config.DataFileName:
+			if err := wc.restoreContent(ctx, filepath.Base(filepath.Dir(path))); err != nil {
+				wc.logger.Errorf("WatchlistContentStore.Recover: Failed to restore '%s'; %s", path, err)
+				corrupt = append(corrupt, path)
+				continue
+			}
+			wc.logger.Warnf("WatchlistContentStore.Recover: Restored '%s' from its latest snapshot", path)
+		case wc.Config.ChangesetFileName, wc.Config.ScanStateFileName:
+			if err := os.Remove(path); err != nil {
+				corrupt = append(corrupt, path)
+				continue
+			}
+			wc.logger.Warnf("WatchlistContentStore.Recover: Removed '%s'", path)
+		default:
+			corrupt = append(corrupt, path)
+		}
+	}
+	return corrupt, nil
+}
+
+func (wc *WatchlistContentStore) SaveWatchlistScanState(ctx context.Context, state *model.WatchlistScanState) error {
+	dir, err := watchlistDir(wc.Config.WatchlistDir, state.WatchlistID)
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	unlock, err := fileutils.Lock(dir)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if err = fileutils.WriteFile(filepath.Join(dir, wc.Config.ScanStateFileName), b, 0644); err != nil {
+		return err
+	}
+	//The hash file is no longer read, it is cleaned up once the watch list has a state.
+	if err = os.Remove(filepath.Join(dir, legacyHashFileName)); err != nil && !os.IsNotExist(err) {
+		wc.logger.Warnf("WatchlistContentStore.SaveWatchlistScanState: Failed to remove the hash file of '%s'; %s", state.WatchlistID, err)
+	}
+	return nil
+}
+
+func (wc *WatchlistContentStore) LoadWatchlistScanState(ctx context.Context, watchlistID string) (*model.WatchlistScanState, error) {
+	var state = model.WatchlistScanState{WatchlistID: watchlistID}
+
+	dir, err := watchlistDir(wc.Config.WatchlistDir, watchlistID)
+	if err != nil {
+		return nil, err
+	}
+	b, err := ioutil.ReadFile(filepath.Join(dir, wc.Config.ScanStateFileName))
+	if os.IsNotExist(err) {
+		return &state, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(b, &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+//SaveWatchlistChangeset replaces the watch list's changes, only the latest changes are kept.
+func (wc *WatchlistContentStore) SaveWatchlistChangeset(ctx context.Context, changeset *model.WatchlistChangeset) error {
+	dir, err := watchlistDir(wc.Config.WatchlistDir, changeset.WatchlistID)
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(changeset)
+	if err != nil {
+		return err
+	}
+
+	unlock, err := fileutils.Lock(dir)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return fileutils.WriteFile(filepath.Join(dir, wc.Config.ChangesetFileName), b, 0644)
+}
+
+func (wc *WatchlistContentStore) LoadWatchlistChangeset(ctx context.Context, watchlistID string) (*model.WatchlistChangeset, error) {
+	var changeset model.WatchlistChangeset
+
+	dir, err := watchlistDir(wc.Config.WatchlistDir, watchlistID)
+	if err != nil {
+		return nil, err
+	}
+	b, err := ioutil.ReadFile(filepath.Join(dir, wc.Config.ChangesetFileName))
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(b, &changeset); err != nil {
+		return nil, err
+	}
+	return &changeset, nil
+}
+
 func (wc *WatchlistContentStore) watchlistDataFilePathFromID(watchlistID string) string {
 	return filepath.Join(wc.Config.WatchlistDir, watchlistID, wc.Config.DataFileName)
 }
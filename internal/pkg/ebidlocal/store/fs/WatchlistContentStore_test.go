@@ -0,0 +1,72 @@
+package fs
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/model"
+)
+
+func TestWatchlistContentStoreScanState(t *testing.T) {
+	var ctx = context.Background()
+	var store = NewWatchlistContentStore(WatchlistContentStoreConfig{WatchlistDir: t.TempDir()})
+	var dir = filepath.Join(store.Config.WatchlistDir, "abc")
+	var now = time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	assert.NoError(t, os.MkdirAll(dir, 0775))
+	state, err := store.LoadWatchlistScanState(ctx, "abc")
+	assert.NoError(t, err)
+	assert.Equal(t, &model.WatchlistScanState{WatchlistID: "abc"}, state, "Never searched")
+
+	//Saved by an older version, the hash was written over without truncating.
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, legacyHashFileName), []byte("0123abcd\x00\x00"), 0644))
+	state, err = store.LoadWatchlistScanState(ctx, "abc")
+	assert.NoError(t, err)
+	assert.Equal(t, &model.WatchlistScanState{WatchlistID: "abc"}, state, "The hash file is not read")
+
+	state.Failed(now, errors.New("timeout"))
+	assert.NoError(t, store.SaveWatchlistScanState(ctx, state))
+	_, err = os.Stat(filepath.Join(dir, legacyHashFileName))
+	assert.True(t, os.IsNotExist(err), "The hash file is removed once there is a state")
+
+	state, err = store.LoadWatchlistScanState(ctx, "abc")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, state.Failures)
+	assert.Equal(t, "timeout", state.LastError)
+	assert.True(t, state.LastFailure.Equal(now))
+
+	state.Succeeded(now.Add(time.Minute))
+	assert.NoError(t, store.SaveWatchlistScanState(ctx, state))
+	state, _ = store.LoadWatchlistScanState(ctx, "abc")
+	assert.Equal(t, 0, state.Failures)
+	assert.True(t, state.LastScan.Equal(now.Add(time.Minute)))
+	assert.True(t, state.LastSuccess.Equal(state.LastScan))
+
+	_, err = store.LoadWatchlistScanState(ctx, "../abc")
+	assert.Error(t, err)
+}
+
+func TestWatchlistContentStoreChangeset(t *testing.T) {
+	var ctx = context.Background()
+	var base = t.TempDir()
+	var store = NewWatchlistContentStore(WatchlistContentStoreConfig{WatchlistDir: filepath.Join(base, "watchlists")})
+
+	assert.NoError(t, os.MkdirAll(filepath.Join(store.Config.WatchlistDir, "abc"), 0775))
+	assert.NoError(t, store.SaveWatchlistChangeset(ctx, &model.WatchlistChangeset{WatchlistID: "abc", ContentID: "ffff"}))
+	changeset, err := store.LoadWatchlistChangeset(ctx, "abc")
+	assert.NoError(t, err)
+	assert.Equal(t, "ffff", changeset.ContentID)
+
+	//A changes file outside of the watch list directory.
+	assert.NoError(t, os.MkdirAll(filepath.Join(base, "other"), 0775))
+	assert.NoError(t, os.WriteFile(filepath.Join(base, "other", store.Config.ChangesetFileName), []byte(`{"contentID":"0000"}`), 0644))
+	_, err = store.LoadWatchlistChangeset(ctx, "../other")
+	assert.Error(t, err, "The ID is not a path")
+	assert.Error(t, store.SaveWatchlistChangeset(ctx, &model.WatchlistChangeset{WatchlistID: "../other"}))
+}
@@ -3,13 +3,15 @@ package fs
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
+	"io/fs"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/model"
+	fileutils "github.com/scirelli/auction-ebidlocal-search/internal/pkg/fileUtils"
 	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/log"
 )
 
@@ -21,6 +23,9 @@ func NewWatchlistStore(config WatchlistStoreConfig, logger log.Logger) *Watchlis
 	if config.DataFileName == "" {
 		config.DataFileName = "data.json"
 	}
+	if config.ScheduleFileName == "" {
+		config.ScheduleFileName = "schedule.json"
+	}
 
 	return &WatchlistStore{
 		Config: config,
@@ -36,9 +41,11 @@ type WatchlistStore struct {
 type WatchlistStoreConfig struct {
 	WatchlistDir string `json:"watchlistDir"`
 	DataFileName string `json:"dataFileName"`
+	//ScheduleFileName the file in a watch list's directory its schedules are saved to, see store.WatchlistScheduler.
+	ScheduleFileName string `json:"scheduleFileName"`
 }
 
-func (wl *WatchlistStore) SaveWatchlist(ctx context.Context, list model.Watchlist) (ID string, err error) {
+func (wl *WatchlistStore) SaveWatchlist(ctx context.Context, list model.WatchlistDefinition) (ID string, err error) {
 	list.Normalize()
 	if err = wl.addWatchlist(list); err != nil {
 		return "", err
@@ -46,47 +53,268 @@ func (wl *WatchlistStore) SaveWatchlist(ctx context.Context, list model.Watchlis
 	return list.ID(), nil
 }
 
-func (wl *WatchlistStore) LoadWatchlist(ctx context.Context, watchlistID string) (model.Watchlist, error) {
-	list, err := wl.loadWatchlist(filepath.Join(wl.Config.WatchlistDir, watchlistID, wl.Config.DataFileName))
+func (wl *WatchlistStore) LoadWatchlist(ctx context.Context, watchlistID string) (model.WatchlistDefinition, error) {
+	watchlistDir, err := wl.watchlistDir(watchlistID)
+	if err != nil {
+		return model.WatchlistDefinition{Keywords: make([]string, 0)}, err
+	}
+	list, err := wl.loadWatchlist(filepath.Join(watchlistDir, wl.Config.DataFileName))
 	list.Normalize()
 	return list, err
 }
 
+//DeleteWatchlist removes the watch list's directory, its definition and its content.
 func (wl *WatchlistStore) DeleteWatchlist(ctx context.Context, watchlistID string) error {
-	return errors.New("Not implemented")
+	watchlistDir, err := wl.watchlistDir(watchlistID)
+	if err != nil {
+		return err
+	}
+	unlock, err := fileutils.Lock(watchlistDir)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	wl.Logger.Infof("WatchlistStore.DeleteWatchlist: Deleting '%s'", watchlistDir)
+	return os.RemoveAll(watchlistDir)
+}
+
+//ArchiveWatchlist moves the watch list's directory into archiveDir.
+func (wl *WatchlistStore) ArchiveWatchlist(ctx context.Context, watchlistID string, archiveDir string) error {
+	watchlistDir, err := wl.watchlistDir(watchlistID)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(archiveDir, 0775); err != nil {
+		return err
+	}
+	unlock, err := fileutils.Lock(watchlistDir)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	wl.Logger.Infof("WatchlistStore.ArchiveWatchlist: Archiving '%s' to '%s'", watchlistDir, archiveDir)
+	return os.Rename(watchlistDir, filepath.Join(archiveDir, watchlistID))
+}
+
+//CopyWatchlistContent copies every file of a watch list's directory, and its sub directories such as its history, except its definition and schedules, to another watch list's directory. Files the other watch list already has are kept. The other watch list's directory is locked while it is written to.
+func (wl *WatchlistStore) CopyWatchlistContent(ctx context.Context, fromWatchlistID string, toWatchlistID string) error {
+	fromDir, err := wl.watchlistDir(fromWatchlistID)
+	if err != nil {
+		return err
+	}
+	toDir, err := wl.watchlistDir(toWatchlistID)
+	if err != nil {
+		return err
+	}
+	unlock, err := fileutils.Lock(toDir)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	return filepath.WalkDir(fromDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(fromDir, path)
+		if err != nil {
+			return err
+		}
+		if rel == wl.Config.DataFileName || rel == wl.Config.ScheduleFileName {
+			return nil
+		}
+		if entry.IsDir() {
+			return os.MkdirAll(filepath.Join(toDir, rel), 0775)
+		}
+		if !entry.Type().IsRegular() || fileutils.IsTemp(path) || fileutils.IsLock(path) {
+			return nil
+		}
+		if _, err := os.Stat(filepath.Join(toDir, rel)); err == nil {
+			return nil
+		}
+		return copyFile(path, filepath.Join(toDir, rel))
+	})
+}
+
+//SaveWatchlistSchedules writes the schedules to the watch list's schedule file, no schedules removes it.
+func (wl *WatchlistStore) SaveWatchlistSchedules(ctx context.Context, watchlistID string, schedules model.WatchlistSchedules) error {
+	watchlistDir, err := wl.watchlistDir(watchlistID)
+	if err != nil {
+		return err
+	}
+	unlock, err := fileutils.Lock(watchlistDir)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	fileName := filepath.Join(watchlistDir, wl.Config.ScheduleFileName)
+	if len(schedules) == 0 {
+		if err := os.Remove(fileName); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	b, err := json.Marshal(schedules)
+	if err != nil {
+		return err
+	}
+	return fileutils.WriteFile(fileName, b, 0644)
 }
 
-//AddWatchlist saves a watchlist to disk. Skips saving if it already exists.
-func (wl *WatchlistStore) addWatchlist(list model.Watchlist) error {
+//LoadWatchlistSchedules the watch list's schedules, none if it has no schedule file.
+func (wl *WatchlistStore) LoadWatchlistSchedules(ctx context.Context, watchlistID string) (model.WatchlistSchedules, error) {
+	var schedules model.WatchlistSchedules
+
+	watchlistDir, err := wl.watchlistDir(watchlistID)
+	if err != nil {
+		return nil, err
+	}
+	b, err := ioutil.ReadFile(filepath.Join(watchlistDir, wl.Config.ScheduleFileName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(b, &schedules); err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+//ListWatchlists the IDs of the directories that have a watch list in them.
+func (wl *WatchlistStore) ListWatchlists(ctx context.Context) ([]string, error) {
+	var ids []string
+
+	entries, err := os.ReadDir(wl.Config.WatchlistDir)
+	if os.IsNotExist(err) {
+		return ids, nil
+	} else if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if _, err := os.Stat(filepath.Join(wl.Config.WatchlistDir, entry.Name(), wl.Config.DataFileName)); entry.IsDir() && err == nil {
+			ids = append(ids, entry.Name())
+		}
+	}
+	return ids, nil
+}
+
+//watchlistDir the watch list's directory, IDs are base64 URL encoded so one that isn't a single path element is an error.
+func (wl *WatchlistStore) watchlistDir(watchlistID string) (string, error) {
+	return watchlistDir(wl.Config.WatchlistDir, watchlistID)
+}
+
+//watchlistDir the watch list's directory in dir, an ID that is not a single path element is rejected.
+func watchlistDir(dir string, watchlistID string) (string, error) {
+	if watchlistID == "" || watchlistID != filepath.Base(watchlistID) || watchlistID == "." || watchlistID == ".." {
+		return "", fmt.Errorf("invalid watch list id '%s'", watchlistID)
+	}
+	return filepath.Join(dir, watchlistID), nil
+}
+
+//Migrate rewrites the watch lists stored in an older version of the document, see model.WatchlistVersion. Watch lists already in the current version are left alone so it is safe to run every time the app starts. Returns the IDs of the watch lists that were migrated.
+func (wl *WatchlistStore) Migrate(ctx context.Context) (migrated []string, err error) {
+	entries, err := os.ReadDir(wl.Config.WatchlistDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		select {
+		case <-ctx.Done():
+			return migrated, ctx.Err()
+		default:
+		}
+		if !entry.IsDir() {
+			continue
+		}
+
+		var filePath = filepath.Join(wl.Config.WatchlistDir, entry.Name(), wl.Config.DataFileName)
+		info, err := os.Stat(filePath)
+		if err != nil {
+			continue
+		}
+		list, err := wl.loadWatchlist(filePath)
+		if err != nil {
+			wl.Logger.Errorf("WatchlistStore.Migrate: Skipping '%s'; %s", entry.Name(), err)
+			continue
+		}
+		if list.Version >= model.WatchlistVersion {
+			continue
+		}
+		if list.Metadata.Created.IsZero() {
+			list.Metadata.Created = info.ModTime()
+		}
+		unlock, err := fileutils.Lock(filepath.Dir(filePath))
+		if err != nil {
+			return migrated, err
+		}
+		err = wl.writeWatchlist(filePath, list)
+		unlock()
+		if err != nil {
+			return migrated, err
+		}
+		wl.Logger.Infof("WatchlistStore.Migrate: Migrated '%s' from version %d", entry.Name(), list.Version)
+		migrated = append(migrated, entry.Name())
+	}
+
+	return migrated, nil
+}
+
+//AddWatchlist saves a watchlist to disk. Skips saving if it already exists, so the metadata is what the first user to create it gave it. The directory is locked so two processes creating the same watch list do not both write it.
+func (wl *WatchlistStore) addWatchlist(list model.WatchlistDefinition) error {
 	var watchlistDir = filepath.Join(wl.Config.WatchlistDir, list.ID())
 
 	wl.Logger.Infof("WatchlistStore.addWatchlist: Checking for '%s'\n", watchlistDir)
-	if _, err := os.Stat(watchlistDir); os.IsExist(err) {
+	if _, err := os.Stat(filepath.Join(watchlistDir, wl.Config.DataFileName)); err == nil {
 		wl.Logger.Info("WatchlistStore.addWatchlist: Watch list already exists.")
 		return nil
 	}
+	if list.Metadata.Created.IsZero() {
+		list.Metadata.Created = time.Now()
+	}
 
 	wl.Logger.Infof("WatchlistStore.addWatchlist: Creating watchlist. '%s'", watchlistDir)
 	if err := os.MkdirAll(watchlistDir, 0775); err != nil {
 		wl.Logger.Error(err)
 		return err
 	}
-
-	file, err := json.Marshal(list)
+	unlock, err := fileutils.Lock(watchlistDir)
 	if err != nil {
+		wl.Logger.Error(err)
+		return err
+	}
+	defer unlock()
+	if _, err := os.Stat(filepath.Join(watchlistDir, wl.Config.DataFileName)); err == nil {
+		wl.Logger.Info("WatchlistStore.addWatchlist: Watch list was created while waiting for the lock.")
+		return nil
+	}
+
+	if err := wl.writeWatchlist(filepath.Join(watchlistDir, wl.Config.DataFileName), list); err != nil {
 		wl.Logger.Error(err)
 		if err2 := os.RemoveAll(watchlistDir); err2 != nil {
 			err = fmt.Errorf("%v: %w", err, err2)
 		}
 		return err
 	}
+	return nil
+}
 
-	return ioutil.WriteFile(filepath.Join(watchlistDir, wl.Config.DataFileName), file, 0644)
+func (wl *WatchlistStore) writeWatchlist(filePath string, list model.WatchlistDefinition) error {
+	file, err := json.Marshal(list)
+	if err != nil {
+		return err
+	}
+	return fileutils.WriteFile(filePath, file, 0644)
 }
 
-//loadWatchlist loads a watch list from file.
-func (wl *WatchlistStore) loadWatchlist(filePath string) (model.Watchlist, error) {
-	var watchlist model.Watchlist = make([]string, 0)
+//loadWatchlist loads a watch list from file, any version of the document, see model.WatchlistDefinition.
+func (wl *WatchlistStore) loadWatchlist(filePath string) (model.WatchlistDefinition, error) {
+	var watchlist = model.WatchlistDefinition{Keywords: make([]string, 0)}
 	jsonFile, err := os.Open(filePath)
 	if err != nil {
 		wl.Logger.Error(err)
@@ -101,3 +329,11 @@ func (wl *WatchlistStore) loadWatchlist(filePath string) (model.Watchlist, error
 	wl.Logger.Infof("WatchlistStore.loadWatchlist: Watch list found '%v'", watchlist)
 	return watchlist, nil
 }
+
+func copyFile(from string, to string) error {
+	b, err := ioutil.ReadFile(from)
+	if err != nil {
+		return err
+	}
+	return fileutils.WriteNewFile(to, b, 0644)
+}
@@ -0,0 +1,148 @@
+package fs
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/model"
+)
+
+func TestWatchlistStoreMigrate(t *testing.T) {
+	var ctx = context.Background()
+	var store = NewWatchlistStore(WatchlistStoreConfig{WatchlistDir: t.TempDir()}, nil)
+
+	var legacy = model.WatchlistDefinition{Keywords: model.Watchlist{"drill", "saw"}}
+	var legacyDir = filepath.Join(store.Config.WatchlistDir, legacy.ID())
+	assert.NoError(t, os.MkdirAll(legacyDir, 0775))
+	assert.NoError(t, os.WriteFile(filepath.Join(legacyDir, "data.json"), []byte(`["drill","saw"]`), 0644))
+
+	current, err := store.SaveWatchlist(ctx, model.WatchlistDefinition{Keywords: model.Watchlist{"kayak"}, Metadata: model.WatchlistMetadata{Name: "Boats"}})
+	assert.NoError(t, err)
+	assert.NoError(t, os.MkdirAll(filepath.Join(store.Config.WatchlistDir, "empty"), 0775))
+
+	migrated, err := store.Migrate(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{legacy.ID()}, migrated)
+
+	b, err := os.ReadFile(filepath.Join(legacyDir, "data.json"))
+	assert.NoError(t, err)
+	var document map[string]interface{}
+	assert.NoError(t, json.Unmarshal(b, &document))
+	assert.Equal(t, float64(model.WatchlistVersion), document["version"])
+
+	list, err := store.LoadWatchlist(ctx, legacy.ID())
+	assert.NoError(t, err)
+	assert.Equal(t, legacy.ID(), list.ID(), "Migrating does not change the ID")
+	assert.False(t, list.Metadata.Created.IsZero())
+
+	boats, err := store.LoadWatchlist(ctx, current)
+	assert.NoError(t, err)
+	assert.Equal(t, "Boats", boats.Metadata.Name)
+
+	migrated, err = store.Migrate(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, migrated, "Migrating twice does nothing")
+}
+
+func TestWatchlistStoreSaveKeepsMetadata(t *testing.T) {
+	var ctx = context.Background()
+	var store = NewWatchlistStore(WatchlistStoreConfig{WatchlistDir: t.TempDir()}, nil)
+
+	id, err := store.SaveWatchlist(ctx, model.WatchlistDefinition{Keywords: model.Watchlist{"kayak"}, Metadata: model.WatchlistMetadata{Owner: "1"}})
+	assert.NoError(t, err)
+	again, err := store.SaveWatchlist(ctx, model.WatchlistDefinition{Keywords: model.Watchlist{"kayak"}, Metadata: model.WatchlistMetadata{Owner: "2"}})
+	assert.NoError(t, err)
+	assert.Equal(t, id, again)
+
+	list, err := store.LoadWatchlist(ctx, id)
+	assert.NoError(t, err)
+	assert.Equal(t, "1", list.Metadata.Owner, "A shared watch list keeps the metadata of the user who created it")
+}
+
+func TestWatchlistStoreLoadOutsideWatchlistDir(t *testing.T) {
+	var ctx = context.Background()
+	var dir = t.TempDir()
+	var store = NewWatchlistStore(WatchlistStoreConfig{WatchlistDir: filepath.Join(dir, "watchlists")}, nil)
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "data.json"), []byte(`{"version": 1, "keywords": ["secret"]}`), 0644))
+	_, err := store.LoadWatchlist(ctx, "..")
+	assert.Error(t, err)
+	_, err = store.LoadWatchlist(ctx, "../watchlists/..")
+	assert.Error(t, err)
+}
+
+func TestWatchlistStoreDelete(t *testing.T) {
+	var ctx = context.Background()
+	var store = NewWatchlistStore(WatchlistStoreConfig{WatchlistDir: t.TempDir()}, nil)
+
+	id, err := store.SaveWatchlist(ctx, model.WatchlistDefinition{Keywords: model.Watchlist{"kayak"}})
+	assert.NoError(t, err)
+	ids, err := store.ListWatchlists(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{id}, ids)
+
+	assert.NoError(t, store.DeleteWatchlist(ctx, id))
+	ids, err = store.ListWatchlists(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, ids)
+
+	assert.Error(t, store.DeleteWatchlist(ctx, id), "Deleting a watch list that does not exist")
+	assert.Error(t, store.DeleteWatchlist(ctx, ".."))
+	assert.Error(t, store.DeleteWatchlist(ctx, "../"+id))
+}
+
+func TestWatchlistStoreCopyContent(t *testing.T) {
+	var ctx = context.Background()
+	var store = NewWatchlistStore(WatchlistStoreConfig{WatchlistDir: t.TempDir()}, nil)
+
+	from, err := store.SaveWatchlist(ctx, model.WatchlistDefinition{Keywords: model.Watchlist{"kayak"}})
+	assert.NoError(t, err)
+	to, err := store.SaveWatchlist(ctx, model.WatchlistDefinition{Keywords: model.Watchlist{"kayak", "paddle"}})
+	assert.NoError(t, err)
+
+	var fromDir = filepath.Join(store.Config.WatchlistDir, from)
+	var toDir = filepath.Join(store.Config.WatchlistDir, to)
+	assert.NoError(t, os.WriteFile(filepath.Join(fromDir, "index.html"), []byte("old"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(fromDir, "history.json"), []byte("old"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(toDir, "index.html"), []byte("new"), 0644))
+
+	assert.NoError(t, store.CopyWatchlistContent(ctx, from, to))
+
+	b, err := os.ReadFile(filepath.Join(toDir, "history.json"))
+	assert.NoError(t, err)
+	assert.Equal(t, "old", string(b))
+	b, err = os.ReadFile(filepath.Join(toDir, "index.html"))
+	assert.NoError(t, err)
+	assert.Equal(t, "new", string(b), "Content the watch list already has is kept")
+
+	list, err := store.LoadWatchlist(ctx, to)
+	assert.NoError(t, err)
+	assert.Equal(t, model.Watchlist{"kayak", "paddle"}, list.Keywords, "The definition is not copied")
+}
+
+func TestWatchlistStoreSchedules(t *testing.T) {
+	var ctx = context.Background()
+	var store = NewWatchlistStore(WatchlistStoreConfig{WatchlistDir: t.TempDir()}, nil)
+
+	id, err := store.SaveWatchlist(ctx, model.WatchlistDefinition{Keywords: model.Watchlist{"snow blower"}})
+	assert.NoError(t, err)
+	schedules, err := store.LoadWatchlistSchedules(ctx, id)
+	assert.NoError(t, err)
+	assert.Empty(t, schedules, "A watch list without a schedule file")
+
+	var expires = time.Date(2022, 4, 1, 0, 0, 0, 0, time.UTC)
+	assert.NoError(t, store.SaveWatchlistSchedules(ctx, id, model.WatchlistSchedules{{Paused: true}, {Expires: expires}}))
+	schedules, err = store.LoadWatchlistSchedules(ctx, id)
+	assert.NoError(t, err)
+	assert.Equal(t, model.WatchlistSchedules{{Paused: true}, {Expires: expires}}, schedules)
+
+	assert.NoError(t, store.SaveWatchlistSchedules(ctx, id, nil))
+	_, err = os.Stat(filepath.Join(store.Config.WatchlistDir, id, store.Config.ScheduleFileName))
+	assert.True(t, os.IsNotExist(err), "No schedules removes the file")
+}
@@ -1,8 +1,77 @@
 package fs
 
-import "github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/store"
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/model"
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/store"
+)
 
 type FSStore struct {
 	store.WatchlistStorer
 	store.WatchlistContentStorer
 }
+
+//CopyWatchlistContent an error if the watch list store can not copy content.
+func (fs FSStore) CopyWatchlistContent(ctx context.Context, fromWatchlistID string, toWatchlistID string) error {
+	if copier, ok := fs.WatchlistStorer.(store.WatchlistContentCopier); ok {
+		return copier.CopyWatchlistContent(ctx, fromWatchlistID, toWatchlistID)
+	}
+	return fmt.Errorf("the watch list store can not copy content")
+}
+
+//ArchiveWatchlist an error if the watch list store can not archive.
+func (fs FSStore) ArchiveWatchlist(ctx context.Context, watchlistID string, archiveDir string) error {
+	if archiver, ok := fs.WatchlistStorer.(store.WatchlistArchiver); ok {
+		return archiver.ArchiveWatchlist(ctx, watchlistID, archiveDir)
+	}
+	return fmt.Errorf("the watch list store can not archive")
+}
+
+//SaveWatchlistSchedules an error if the watch list store can not schedule.
+func (fs FSStore) SaveWatchlistSchedules(ctx context.Context, watchlistID string, schedules model.WatchlistSchedules) error {
+	if scheduler, ok := fs.WatchlistStorer.(store.WatchlistScheduler); ok {
+		return scheduler.SaveWatchlistSchedules(ctx, watchlistID, schedules)
+	}
+	return fmt.Errorf("the watch list store can not schedule")
+}
+
+//LoadWatchlistSchedules no schedules, the watch list is always searched, if the watch list store can not schedule.
+func (fs FSStore) LoadWatchlistSchedules(ctx context.Context, watchlistID string) (model.WatchlistSchedules, error) {
+	if scheduler, ok := fs.WatchlistStorer.(store.WatchlistScheduler); ok {
+		return scheduler.LoadWatchlistSchedules(ctx, watchlistID)
+	}
+	return nil, nil
+}
+
+//SaveWatchlistChangeset does nothing if the content store can not keep changes.
+func (fs FSStore) SaveWatchlistChangeset(ctx context.Context, changeset *model.WatchlistChangeset) error {
+	if changesets, ok := fs.WatchlistContentStorer.(store.WatchlistChangesetStorer); ok {
+		return changesets.SaveWatchlistChangeset(ctx, changeset)
+	}
+	return nil
+}
+
+func (fs FSStore) LoadWatchlistChangeset(ctx context.Context, watchlistID string) (*model.WatchlistChangeset, error) {
+	if changesets, ok := fs.WatchlistContentStorer.(store.WatchlistChangesetStorer); ok {
+		return changesets.LoadWatchlistChangeset(ctx, watchlistID)
+	}
+	return nil, os.ErrNotExist
+}
+
+//ListWatchlistSnapshots no snapshots if the content store does not keep history.
+func (fs FSStore) ListWatchlistSnapshots(ctx context.Context, watchlistID string) ([]model.WatchlistSnapshot, error) {
+	if history, ok := fs.WatchlistContentStorer.(store.WatchlistHistoryStorer); ok {
+		return history.ListWatchlistSnapshots(ctx, watchlistID)
+	}
+	return []model.WatchlistSnapshot{}, nil
+}
+
+func (fs FSStore) LoadWatchlistSnapshot(ctx context.Context, watchlistID string, snapshotID string) (*model.WatchlistContent, error) {
+	if history, ok := fs.WatchlistContentStorer.(store.WatchlistHistoryStorer); ok {
+		return history.LoadWatchlistSnapshot(ctx, watchlistID, snapshotID)
+	}
+	return nil, os.ErrNotExist
+}
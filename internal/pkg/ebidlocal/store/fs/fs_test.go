@@ -0,0 +1,33 @@
+package fs
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/store"
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/store/storetest"
+)
+
+func newTestFSStore(t *testing.T) FSStore {
+	var contentPath = t.TempDir()
+	return FSStore{
+		WatchlistStorer:        NewWatchlistStore(WatchlistStoreConfig{WatchlistDir: filepath.Join(contentPath, "web", "watchlists")}, nil),
+		WatchlistContentStorer: NewWatchlistContentStore(WatchlistContentStoreConfig{ContentPath: contentPath}),
+	}
+}
+
+func TestFSStoreConformance(t *testing.T) {
+	storetest.WatchlistStorer(t, func(t *testing.T) store.WatchlistStorer { return newTestFSStore(t) })
+	storetest.WatchlistContentStorer(t, func(t *testing.T) store.Storer { return newTestFSStore(t) })
+}
+
+func TestFSStoreCopyContentUnsupported(t *testing.T) {
+	var s = newTestFSStore(t)
+	//Only the WatchlistStorer methods, the watch list store can not copy content.
+	s.WatchlistStorer = struct{ store.WatchlistStorer }{s.WatchlistStorer}
+
+	assert.Error(t, s.CopyWatchlistContent(context.Background(), "from", "to"))
+}
@@ -0,0 +1,324 @@
+//Package memory a store of watch lists and their content in memory, for tests and for trying the apps without a content path. Safe for concurrent use. Records are kept as JSON, the same as the fs store writes them, so what a caller does to a record after saving or loading it does not change the stored one.
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/model"
+)
+
+//snapshotIDFormat the same snapshot IDs as the fs store, they sort oldest first.
+const snapshotIDFormat = "20060102T150405.000000000Z"
+
+func New(config Config) *Store {
+	return &Store{
+		Config:     config,
+		watchlists: make(map[string][]byte),
+		schedules:  make(map[string][]byte),
+		content:    make(map[string][]byte),
+		snapshots:  make(map[string]map[string][]byte),
+		changesets: make(map[string][]byte),
+		scanStates: make(map[string][]byte),
+	}
+}
+
+//Store both the watch list and content store, with schedules, changes and history.
+type Store struct {
+	Config Config
+
+	mu         sync.RWMutex
+	watchlists map[string][]byte
+	schedules  map[string][]byte
+	content    map[string][]byte
+	//snapshots watch list IDs to snapshot IDs to content.
+	snapshots  map[string]map[string][]byte
+	changesets map[string][]byte
+	scanStates map[string][]byte
+}
+
+type Config struct {
+	//HistoryMaxSnapshots the most snapshots kept of a watch list, zero keeps them all.
+	HistoryMaxSnapshots int `json:"historyMaxSnapshots"`
+	//HistoryMaxAge seconds a snapshot is kept, zero keeps them forever. The latest snapshot is always kept.
+	HistoryMaxAge int64 `json:"historyMaxAgeSeconds"`
+}
+
+//SaveWatchlist skips saving a watch list that already exists, so the metadata is what the first user to create it gave it.
+func (s *Store) SaveWatchlist(ctx context.Context, list model.WatchlistDefinition) (string, error) {
+	list.Normalize()
+	if list.Metadata.Created.IsZero() {
+		list.Metadata.Created = time.Now()
+	}
+	b, err := json.Marshal(list)
+	if err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.watchlists[list.ID()]; !exists {
+		s.watchlists[list.ID()] = b
+	}
+	return list.ID(), nil
+}
+
+func (s *Store) LoadWatchlist(ctx context.Context, watchlistID string) (model.WatchlistDefinition, error) {
+	var list = model.WatchlistDefinition{Keywords: make([]string, 0)}
+
+	s.mu.RLock()
+	b, exists := s.watchlists[watchlistID]
+	s.mu.RUnlock()
+	if !exists {
+		return list, os.ErrNotExist
+	}
+	if err := json.Unmarshal(b, &list); err != nil {
+		return list, err
+	}
+	list.Normalize()
+	return list, nil
+}
+
+//DeleteWatchlist removes the watch list's definition and its content, os.ErrNotExist if there is no such watch list.
+func (s *Store) DeleteWatchlist(ctx context.Context, watchlistID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.watchlists[watchlistID]; !exists {
+		return os.ErrNotExist
+	}
+	for _, records := range []map[string][]byte{s.watchlists, s.schedules, s.content, s.changesets, s.scanStates} {
+		delete(records, watchlistID)
+	}
+	delete(s.snapshots, watchlistID)
+	return nil
+}
+
+func (s *Store) ListWatchlists(ctx context.Context) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return sortedKeys(s.watchlists), nil
+}
+
+//CopyWatchlistContent copies a watch list's content, changes, scan state and history to another watch list. What the other watch list already has is kept.
+func (s *Store) CopyWatchlistContent(ctx context.Context, fromWatchlistID string, toWatchlistID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, records := range []map[string][]byte{s.content, s.changesets, s.scanStates} {
+		if b, exists := records[fromWatchlistID]; exists {
+			if _, exists := records[toWatchlistID]; !exists {
+				records[toWatchlistID] = b
+			}
+		}
+	}
+	for snapshotID, b := range s.snapshots[fromWatchlistID] {
+		if s.snapshots[toWatchlistID] == nil {
+			s.snapshots[toWatchlistID] = make(map[string][]byte)
+		}
+		if _, exists := s.snapshots[toWatchlistID][snapshotID]; !exists {
+			s.snapshots[toWatchlistID][snapshotID] = b
+		}
+	}
+	return nil
+}
+
+//SaveWatchlistSchedules no schedules removes them, the watch list must exist.
+func (s *Store) SaveWatchlistSchedules(ctx context.Context, watchlistID string, schedules model.WatchlistSchedules) error {
+	b, err := json.Marshal(schedules)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.watchlists[watchlistID]; !exists {
+		return os.ErrNotExist
+	}
+	if len(schedules) == 0 {
+		delete(s.schedules, watchlistID)
+		return nil
+	}
+	s.schedules[watchlistID] = b
+	return nil
+}
+
+//LoadWatchlistSchedules none if the watch list has no schedules or does not exist.
+func (s *Store) LoadWatchlistSchedules(ctx context.Context, watchlistID string) (model.WatchlistSchedules, error) {
+	var schedules model.WatchlistSchedules
+
+	s.mu.RLock()
+	b, exists := s.schedules[watchlistID]
+	s.mu.RUnlock()
+	if !exists {
+		return nil, nil
+	}
+	if err := json.Unmarshal(b, &schedules); err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+//SaveWatchlistContent replaces the watch list's content and adds it to its history.
+func (s *Store) SaveWatchlistContent(ctx context.Context, watchlistContent *model.WatchlistContent) (string, error) {
+	b, err := json.Marshal(watchlistContent)
+	if err != nil {
+		return "", err
+	}
+	timestamp := watchlistContent.GetTimestamp()
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+	id := watchlistContent.GetWatchlistID()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.content[id] = b
+	if s.snapshots[id] == nil {
+		s.snapshots[id] = make(map[string][]byte)
+	}
+	//Snapshots are never overwritten.
+	if snapshotID := timestamp.UTC().Format(snapshotIDFormat); s.snapshots[id][snapshotID] == nil {
+		s.snapshots[id][snapshotID] = b
+	}
+	s.pruneHistory(id, time.Now())
+	return watchlistContent.ID(), nil
+}
+
+func (s *Store) LoadWatchlistContent(ctx context.Context, watchlistContentID string) (*model.WatchlistContent, error) {
+	s.mu.RLock()
+	b, exists := s.content[watchlistContentID]
+	s.mu.RUnlock()
+	if !exists {
+		return nil, os.ErrNotExist
+	}
+	return unmarshalContent(b)
+}
+
+func (s *Store) DeleteWatchlistContent(ctx context.Context, watchlistContentID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.content[watchlistContentID]; !exists {
+		return os.ErrNotExist
+	}
+	delete(s.content, watchlistContentID)
+	return nil
+}
+
+func (s *Store) SaveWatchlistScanState(ctx context.Context, state *model.WatchlistScanState) error {
+	return s.save(s.scanStates, state.WatchlistID, state)
+}
+
+func (s *Store) LoadWatchlistScanState(ctx context.Context, watchlistID string) (*model.WatchlistScanState, error) {
+	var state = model.WatchlistScanState{WatchlistID: watchlistID}
+
+	s.mu.RLock()
+	b, exists := s.scanStates[watchlistID]
+	s.mu.RUnlock()
+	if !exists {
+		return &state, nil
+	}
+	if err := json.Unmarshal(b, &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+//SaveWatchlistChangeset replaces the watch list's changes, only the latest changes are kept.
+func (s *Store) SaveWatchlistChangeset(ctx context.Context, changeset *model.WatchlistChangeset) error {
+	return s.save(s.changesets, changeset.WatchlistID, changeset)
+}
+
+func (s *Store) LoadWatchlistChangeset(ctx context.Context, watchlistID string) (*model.WatchlistChangeset, error) {
+	var changeset model.WatchlistChangeset
+
+	s.mu.RLock()
+	b, exists := s.changesets[watchlistID]
+	s.mu.RUnlock()
+	if !exists {
+		return nil, os.ErrNotExist
+	}
+	if err := json.Unmarshal(b, &changeset); err != nil {
+		return nil, err
+	}
+	return &changeset, nil
+}
+
+//ListWatchlistSnapshots the watch list's snapshots oldest first, none if it has no history.
+func (s *Store) ListWatchlistSnapshots(ctx context.Context, watchlistID string) ([]model.WatchlistSnapshot, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.listSnapshots(watchlistID), nil
+}
+
+func (s *Store) LoadWatchlistSnapshot(ctx context.Context, watchlistID string, snapshotID string) (*model.WatchlistContent, error) {
+	if _, err := time.Parse(snapshotIDFormat, snapshotID); err != nil {
+		return nil, fmt.Errorf("invalid snapshot id '%s'", snapshotID)
+	}
+	s.mu.RLock()
+	b, exists := s.snapshots[watchlistID][snapshotID]
+	s.mu.RUnlock()
+	if !exists {
+		return nil, os.ErrNotExist
+	}
+	return unmarshalContent(b)
+}
+
+//save the record's JSON under id in records.
+func (s *Store) save(records map[string][]byte, id string, record interface{}) error {
+	b, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	records[id] = b
+	return nil
+}
+
+//listSnapshots the caller holds the lock.
+func (s *Store) listSnapshots(watchlistID string) []model.WatchlistSnapshot {
+	var snapshots = []model.WatchlistSnapshot{}
+	for _, id := range sortedKeys(s.snapshots[watchlistID]) {
+		timestamp, _ := time.Parse(snapshotIDFormat, id)
+		snapshots = append(snapshots, model.WatchlistSnapshot{ID: id, Timestamp: timestamp})
+	}
+	return snapshots
+}
+
+//pruneHistory removes the snapshots beyond HistoryMaxSnapshots and those older than HistoryMaxAge, the latest snapshot is always kept. The caller holds the lock.
+func (s *Store) pruneHistory(watchlistID string, now time.Time) {
+	snapshots := s.listSnapshots(watchlistID)
+	if len(snapshots) < 2 {
+		return
+	}
+	maxAge := time.Duration(s.Config.HistoryMaxAge) * time.Second
+
+	for i, snapshot := range snapshots[:len(snapshots)-1] {
+		tooMany := s.Config.HistoryMaxSnapshots > 0 && len(snapshots)-i > s.Config.HistoryMaxSnapshots
+		tooOld := maxAge > 0 && now.Sub(snapshot.Timestamp) > maxAge
+		if tooMany || tooOld {
+			delete(s.snapshots[watchlistID], snapshot.ID)
+		}
+	}
+}
+
+func unmarshalContent(b []byte) (*model.WatchlistContent, error) {
+	var content model.WatchlistContent
+	if err := json.Unmarshal(b, &content); err != nil {
+		return nil, err
+	}
+	return &content, nil
+}
+
+func sortedKeys(records map[string][]byte) []string {
+	var keys = make([]string, 0, len(records))
+	for key := range records {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,13 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/store"
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/store/storetest"
+)
+
+func TestStoreConformance(t *testing.T) {
+	storetest.WatchlistStorer(t, func(t *testing.T) store.WatchlistStorer { return New(Config{}) })
+	storetest.WatchlistContentStorer(t, func(t *testing.T) store.Storer { return New(Config{}) })
+}
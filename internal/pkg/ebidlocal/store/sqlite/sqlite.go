@@ -0,0 +1,467 @@
+//Package sqlite a store of watch lists and their content in a SQLite database, see package sqldb. Documents are kept as JSON in the same format the fs store writes them, the columns beside them are what they are looked up by.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/model"
+	fileutils "github.com/scirelli/auction-ebidlocal-search/internal/pkg/fileUtils"
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/log"
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/sqldb"
+)
+
+//Schema the name the store's migrations are recorded under.
+const Schema = "ebidlocal"
+
+//snapshotIDFormat the same snapshot IDs as the fs store, they sort oldest first.
+const snapshotIDFormat = "20060102T150405.000000000Z"
+
+//migrations the store's schema, only ever append to it, see sqldb.Migrate.
+var migrations = []string{
+	`CREATE TABLE watchlists (
+		id TEXT PRIMARY KEY,
+		definition TEXT NOT NULL,
+		schedules TEXT,
+		created_at TEXT NOT NULL
+	);
+	CREATE TABLE watchlist_content (
+		watchlist_id TEXT PRIMARY KEY,
+		content_id TEXT NOT NULL,
+		content TEXT NOT NULL,
+		saved_at TEXT NOT NULL
+	);
+	CREATE TABLE watchlist_snapshots (
+		watchlist_id TEXT NOT NULL,
+		snapshot_id TEXT NOT NULL,
+		content TEXT NOT NULL,
+		PRIMARY KEY (watchlist_id, snapshot_id)
+	);
+	CREATE TABLE watchlist_changesets (
+		watchlist_id TEXT PRIMARY KEY,
+		changeset TEXT NOT NULL
+	);
+	CREATE TABLE watchlist_scan_states (
+		watchlist_id TEXT PRIMARY KEY,
+		content_id TEXT NOT NULL DEFAULT '',
+		state TEXT NOT NULL
+	);`,
+	//The updater finds changes by diffing the saved content, the content ID of the scan state was never read.
+	`ALTER TABLE watchlist_scan_states DROP COLUMN content_id;`,
+}
+
+//contentTables the tables of a watch list's content, its definition is in watchlists.
+var contentTables = []string{"watchlist_content", "watchlist_snapshots", "watchlist_changesets", "watchlist_scan_states"}
+
+func New(db *sql.DB, config Config, logger log.Logger) *Store {
+	if logger == nil {
+		logger = log.New("SQLiteStore", log.DEFAULT_LOG_LEVEL)
+	}
+	if config.DataFileName == "" {
+		config.DataFileName = "data.json"
+	}
+	if config.ContentFileName == "" {
+		config.ContentFileName = "models.json"
+	}
+	if config.ScheduleFileName == "" {
+		config.ScheduleFileName = "schedule.json"
+	}
+
+	return &Store{
+		db:     db,
+		Config: config,
+		Logger: logger,
+	}
+}
+
+//Store both the watch list and content store, with schedules, changes and history, in one database.
+type Store struct {
+	db     *sql.DB
+	Config Config
+	Logger log.Logger
+}
+
+type Config struct {
+	//HistoryMaxSnapshots the most snapshots kept of a watch list, zero keeps them all.
+	HistoryMaxSnapshots int `json:"historyMaxSnapshots"`
+	//HistoryMaxAge seconds a snapshot is kept, zero keeps them forever. The latest snapshot is always kept.
+	HistoryMaxAge int64 `json:"historyMaxAgeSeconds"`
+	//DataFileName, ContentFileName and ScheduleFileName the files an archived watch list's definition, content and schedules are written to, the same as the fs store's.
+	DataFileName     string `json:"dataFileName"`
+	ContentFileName  string `json:"contentFileName"`
+	ScheduleFileName string `json:"scheduleFileName"`
+}
+
+//Migrate creates or updates the store's tables, safe to run every time the app starts. Returns the number of migrations applied.
+func (s *Store) Migrate(ctx context.Context) (int, error) {
+	return sqldb.Migrate(ctx, s.db, Schema, migrations)
+}
+
+//SaveWatchlist skips saving a watch list that already exists, so the metadata is what the first user to create it gave it.
+func (s *Store) SaveWatchlist(ctx context.Context, list model.WatchlistDefinition) (string, error) {
+	list.Normalize()
+	if list.Metadata.Created.IsZero() {
+		list.Metadata.Created = time.Now()
+	}
+	b, err := json.Marshal(list)
+	if err != nil {
+		return "", err
+	}
+	if _, err = s.db.ExecContext(ctx,
+		"INSERT INTO watchlists (id, definition, created_at) VALUES (?, ?, ?) ON CONFLICT (id) DO NOTHING",
+		list.ID(), string(b), list.Metadata.Created.UTC().Format(time.RFC3339Nano),
+	); err != nil {
+		return "", err
+	}
+	return list.ID(), nil
+}
+
+func (s *Store) LoadWatchlist(ctx context.Context, watchlistID string) (model.WatchlistDefinition, error) {
+	var list = model.WatchlistDefinition{Keywords: make([]string, 0)}
+	var definition string
+
+	if err := s.db.QueryRowContext(ctx, "SELECT definition FROM watchlists WHERE id = ?", watchlistID).Scan(&definition); err != nil {
+		return list, notExist(err)
+	}
+	if err := json.Unmarshal([]byte(definition), &list); err != nil {
+		return list, err
+	}
+	list.Normalize()
+	return list, nil
+}
+
+//DeleteWatchlist removes the watch list's definition and its content.
+func (s *Store) DeleteWatchlist(ctx context.Context, watchlistID string) error {
+	return sqldb.InTx(ctx, s.db, func(tx *sql.Tx) error {
+		return deleteWatchlist(ctx, tx, watchlistID)
+	})
+}
+
+func (s *Store) ListWatchlists(ctx context.Context) ([]string, error) {
+	return queryStrings(ctx, s.db, "SELECT id FROM watchlists ORDER BY id")
+}
+
+//ArchiveWatchlist writes the watch list's definition, content and schedules to a directory named for it in archiveDir, in the fs store's layout, then deletes it from the database.
+func (s *Store) ArchiveWatchlist(ctx context.Context, watchlistID string, archiveDir string) error {
+	if watchlistID == "" || watchlistID != filepath.Base(watchlistID) || watchlistID == "." || watchlistID == ".." {
+		return fmt.Errorf("invalid watch list id '%s'", watchlistID)
+	}
+	return sqldb.InTx(ctx, s.db, func(tx *sql.Tx) error {
+		var definition string
+		var schedules, content sql.NullString
+		if err := tx.QueryRowContext(ctx, "SELECT definition, schedules FROM watchlists WHERE id = ?", watchlistID).Scan(&definition, &schedules); err != nil {
+			return notExist(err)
+		}
+		if err := tx.QueryRowContext(ctx, "SELECT content FROM watchlist_content WHERE watchlist_id = ?", watchlistID).Scan(&content); err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		var dir = filepath.Join(archiveDir, watchlistID)
+		if err := os.MkdirAll(dir, 0775); err != nil {
+			return err
+		}
+		for fileName, document := range map[string]sql.NullString{
+			s.Config.DataFileName:     {String: definition, Valid: true},
+			s.Config.ContentFileName:  content,
+			s.Config.ScheduleFileName: schedules,
+		} {
+			if !document.Valid {
+				continue
+			}
+			if err := fileutils.WriteFile(filepath.Join(dir, fileName), []byte(document.String), 0644); err != nil {
+				return err
+			}
+		}
+		s.Logger.Infof("Store.ArchiveWatchlist: Archived '%s' to '%s'", watchlistID, dir)
+		return deleteWatchlist(ctx, tx, watchlistID)
+	})
+}
+
+//CopyWatchlistContent copies a watch list's content, changes, scan state and history to another watch list. What the other watch list already has is kept.
+func (s *Store) CopyWatchlistContent(ctx context.Context, fromWatchlistID string, toWatchlistID string) error {
+	return sqldb.InTx(ctx, s.db, func(tx *sql.Tx) error {
+		for _, statement := range []string{
+			`INSERT INTO watchlist_content (watchlist_id, content_id, content, saved_at)
+				SELECT ?2, content_id, content, saved_at FROM watchlist_content WHERE watchlist_id = ?1
+				ON CONFLICT (watchlist_id) DO NOTHING`,
+			`INSERT INTO watchlist_snapshots (watchlist_id, snapshot_id, content)
+				SELECT ?2, snapshot_id, content FROM watchlist_snapshots WHERE watchlist_id = ?1
+				ON CONFLICT (watchlist_id, snapshot_id) DO NOTHING`,
+			`INSERT INTO watchlist_changesets (watchlist_id, changeset)
+				SELECT ?2, changeset FROM watchlist_changesets WHERE watchlist_id = ?1
+				ON CONFLICT (watchlist_id) DO NOTHING`,
+			`INSERT INTO watchlist_scan_states (watchlist_id, state)
+				SELECT ?2, state FROM watchlist_scan_states WHERE watchlist_id = ?1
+				ON CONFLICT (watchlist_id) DO NOTHING`,
+		} {
+			if _, err := tx.ExecContext(ctx, statement, fromWatchlistID, toWatchlistID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+//SaveWatchlistSchedules no schedules removes them, the watch list must exist.
+func (s *Store) SaveWatchlistSchedules(ctx context.Context, watchlistID string, schedules model.WatchlistSchedules) error {
+	var value sql.NullString
+	if len(schedules) > 0 {
+		b, err := json.Marshal(schedules)
+		if err != nil {
+			return err
+		}
+		value = sql.NullString{String: string(b), Valid: true}
+	}
+	result, err := s.db.ExecContext(ctx, "UPDATE watchlists SET schedules = ? WHERE id = ?", value, watchlistID)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return os.ErrNotExist
+	}
+	return err
+}
+
+//LoadWatchlistSchedules none if the watch list has no schedules or does not exist.
+func (s *Store) LoadWatchlistSchedules(ctx context.Context, watchlistID string) (model.WatchlistSchedules, error) {
+	var schedules model.WatchlistSchedules
+	var value sql.NullString
+
+	err := s.db.QueryRowContext(ctx, "SELECT schedules FROM watchlists WHERE id = ?", watchlistID).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !value.Valid) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal([]byte(value.String), &schedules); err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+//SaveWatchlistContent replaces the watch list's content, and adds it to its history, in one transaction.
+func (s *Store) SaveWatchlistContent(ctx context.Context, watchlistContent *model.WatchlistContent) (string, error) {
+	b, err := json.Marshal(watchlistContent)
+	if err != nil {
+		return "", err
+	}
+	timestamp := watchlistContent.GetTimestamp()
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+
+	err = sqldb.InTx(ctx, s.db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO watchlist_content (watchlist_id, content_id, content, saved_at) VALUES (?, ?, ?, ?)
+			ON CONFLICT (watchlist_id) DO UPDATE SET content_id = excluded.content_id, content = excluded.content, saved_at = excluded.saved_at`,
+			watchlistContent.GetWatchlistID(), watchlistContent.ID(), string(b), timestamp.UTC().Format(time.RFC3339Nano),
+		); err != nil {
+			return err
+		}
+		//Snapshots are never overwritten.
+		if _, err := tx.ExecContext(ctx,
+			"INSERT INTO watchlist_snapshots (watchlist_id, snapshot_id, content) VALUES (?, ?, ?) ON CONFLICT (watchlist_id, snapshot_id) DO NOTHING",
+			watchlistContent.GetWatchlistID(), timestamp.UTC().Format(snapshotIDFormat), string(b),
+		); err != nil {
+			return err
+		}
+		return s.pruneHistory(ctx, tx, watchlistContent.GetWatchlistID(), time.Now())
+	})
+	if err != nil {
+		return "", err
+	}
+	return watchlistContent.ID(), nil
+}
+
+func (s *Store) LoadWatchlistContent(ctx context.Context, watchlistContentID string) (*model.WatchlistContent, error) {
+	var content string
+	if err := s.db.QueryRowContext(ctx, "SELECT content FROM watchlist_content WHERE watchlist_id = ?", watchlistContentID).Scan(&content); err != nil {
+		return nil, notExist(err)
+	}
+	return unmarshalContent(content)
+}
+
+func (s *Store) DeleteWatchlistContent(ctx context.Context, watchlistContentID string) error {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM watchlist_content WHERE watchlist_id = ?", watchlistContentID)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return os.ErrNotExist
+	}
+	return err
+}
+
+func (s *Store) SaveWatchlistScanState(ctx context.Context, state *model.WatchlistScanState) error {
+	b, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx,
+		`INSERT INTO watchlist_scan_states (watchlist_id, state) VALUES (?, ?)
+		ON CONFLICT (watchlist_id) DO UPDATE SET state = excluded.state`,
+		state.WatchlistID, string(b),
+	)
+	return err
+}
+
+func (s *Store) LoadWatchlistScanState(ctx context.Context, watchlistID string) (*model.WatchlistScanState, error) {
+	var state = model.WatchlistScanState{WatchlistID: watchlistID}
+	var value string
+
+	err := s.db.QueryRowContext(ctx, "SELECT state FROM watchlist_scan_states WHERE watchlist_id = ?", watchlistID).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &state, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal([]byte(value), &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+//SaveWatchlistChangeset replaces the watch list's changes, only the latest changes are kept.
+func (s *Store) SaveWatchlistChangeset(ctx context.Context, changeset *model.WatchlistChangeset) error {
+	b, err := json.Marshal(changeset)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx,
+		"INSERT INTO watchlist_changesets (watchlist_id, changeset) VALUES (?, ?) ON CONFLICT (watchlist_id) DO UPDATE SET changeset = excluded.changeset",
+		changeset.WatchlistID, string(b),
+	)
+	return err
+}
+
+func (s *Store) LoadWatchlistChangeset(ctx context.Context, watchlistID string) (*model.WatchlistChangeset, error) {
+	var changeset model.WatchlistChangeset
+	var value string
+
+	if err := s.db.QueryRowContext(ctx, "SELECT changeset FROM watchlist_changesets WHERE watchlist_id = ?", watchlistID).Scan(&value); err != nil {
+		return nil, notExist(err)
+	}
+	if err := json.Unmarshal([]byte(value), &changeset); err != nil {
+		return nil, err
+	}
+	return &changeset, nil
+}
+
+//ListWatchlistSnapshots the watch list's snapshots oldest first, none if it has no history.
+func (s *Store) ListWatchlistSnapshots(ctx context.Context, watchlistID string) ([]model.WatchlistSnapshot, error) {
+	return listSnapshots(ctx, s.db, watchlistID)
+}
+
+func (s *Store) LoadWatchlistSnapshot(ctx context.Context, watchlistID string, snapshotID string) (*model.WatchlistContent, error) {
+	var content string
+
+	if _, err := time.Parse(snapshotIDFormat, snapshotID); err != nil {
+		return nil, fmt.Errorf("invalid snapshot id '%s'", snapshotID)
+	}
+	if err := s.db.QueryRowContext(ctx, "SELECT content FROM watchlist_snapshots WHERE watchlist_id = ? AND snapshot_id = ?", watchlistID, snapshotID).Scan(&content); err != nil {
+		return nil, notExist(err)
+	}
+	return unmarshalContent(content)
+}
+
+//pruneHistory removes the snapshots beyond HistoryMaxSnapshots and those older than HistoryMaxAge, the latest snapshot is always kept.
+func (s *Store) pruneHistory(ctx context.Context, tx *sql.Tx, watchlistID string, now time.Time) error {
+	snapshots, err := listSnapshots(ctx, tx, watchlistID)
+	if err != nil || len(snapshots) < 2 {
+		return err
+	}
+	maxAge := time.Duration(s.Config.HistoryMaxAge) * time.Second
+
+	for i, snapshot := range snapshots[:len(snapshots)-1] {
+		tooMany := s.Config.HistoryMaxSnapshots > 0 && len(snapshots)-i > s.Config.HistoryMaxSnapshots
+		tooOld := maxAge > 0 && now.Sub(snapshot.Timestamp) > maxAge
+		if !tooMany && !tooOld {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, "DELETE FROM watchlist_snapshots WHERE watchlist_id = ? AND snapshot_id = ?", watchlistID, snapshot.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//querier a database or a transaction.
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+func listSnapshots(ctx context.Context, db querier, watchlistID string) ([]model.WatchlistSnapshot, error) {
+	var snapshots = []model.WatchlistSnapshot{}
+
+	ids, err := queryStrings(ctx, db, "SELECT snapshot_id FROM watchlist_snapshots WHERE watchlist_id = ? ORDER BY snapshot_id", watchlistID)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		timestamp, err := time.Parse(snapshotIDFormat, id)
+		if err != nil {
+			continue
+		}
+		snapshots = append(snapshots, model.WatchlistSnapshot{ID: id, Timestamp: timestamp})
+	}
+	return snapshots, nil
+}
+
+//queryStrings the first column of every row.
+func queryStrings(ctx context.Context, db querier, query string, args ...interface{}) ([]string, error) {
+	var values = []string{}
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, rows.Err()
+}
+
+func deleteWatchlist(ctx context.Context, tx *sql.Tx, watchlistID string) error {
+	result, err := tx.ExecContext(ctx, "DELETE FROM watchlists WHERE id = ?", watchlistID)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return os.ErrNotExist
+	}
+	for _, table := range contentTables {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM "+table+" WHERE watchlist_id = ?", watchlistID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func unmarshalContent(value string) (*model.WatchlistContent, error) {
+	var content model.WatchlistContent
+	if err := json.Unmarshal([]byte(value), &content); err != nil {
+		return nil, err
+	}
+	return &content, nil
+}
+
+//notExist os.ErrNotExist for a row that is not found, the error the fs store returns for a missing file, so callers checking os.IsNotExist work with either store.
+func notExist(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return os.ErrNotExist
+	}
+	return err
+}
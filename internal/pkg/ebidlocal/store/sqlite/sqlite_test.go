@@ -0,0 +1,166 @@
+package sqlite
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/model"
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/store"
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/store/storetest"
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/sqldb"
+)
+
+func newTestStore(t *testing.T, config Config) *Store {
+	db, err := sqldb.Open(*sqldb.Defaults(&sqldb.Config{}, t.TempDir()))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	t.Cleanup(func() { db.Close() })
+
+	store := New(db, config, nil)
+	applied, err := store.Migrate(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, len(migrations), applied)
+	applied, err = store.Migrate(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, applied, "Migrations are applied once")
+	return store
+}
+
+func TestStoreWatchlists(t *testing.T) {
+	var ctx = context.Background()
+	var store = newTestStore(t, Config{})
+	var list = model.WatchlistDefinition{Keywords: []string{"drill", "saw"}, Metadata: model.WatchlistMetadata{Name: "tools"}}
+
+	_, err := store.LoadWatchlist(ctx, list.ID())
+	assert.True(t, os.IsNotExist(err))
+
+	id, err := store.SaveWatchlist(ctx, list)
+	assert.NoError(t, err)
+	assert.Equal(t, list.ID(), id)
+	_, err = store.SaveWatchlist(ctx, model.WatchlistDefinition{Keywords: []string{"drill", "saw"}, Metadata: model.WatchlistMetadata{Name: "other"}})
+	assert.NoError(t, err)
+
+	loaded, err := store.LoadWatchlist(ctx, id)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"drill", "saw"}, []string(loaded.Keywords))
+	assert.Equal(t, "tools", loaded.Metadata.Name, "The first save wins")
+	assert.False(t, loaded.Metadata.Created.IsZero())
+
+	ids, err := store.ListWatchlists(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{id}, ids)
+
+	schedules, err := store.LoadWatchlistSchedules(ctx, id)
+	assert.NoError(t, err)
+	assert.Nil(t, schedules)
+	assert.NoError(t, store.SaveWatchlistSchedules(ctx, id, model.WatchlistSchedules{{Paused: true}}))
+	schedules, _ = store.LoadWatchlistSchedules(ctx, id)
+	assert.Equal(t, model.WatchlistSchedules{{Paused: true}}, schedules)
+	assert.True(t, os.IsNotExist(store.SaveWatchlistSchedules(ctx, "missing", model.WatchlistSchedules{{Paused: true}})))
+
+	_, err = store.SaveWatchlistContent(ctx, &model.WatchlistContent{WatchlistID: id, Timestamp: time.Now()})
+	assert.NoError(t, err)
+	assert.NoError(t, store.DeleteWatchlist(ctx, id))
+	ids, _ = store.ListWatchlists(ctx)
+	assert.Empty(t, ids)
+	_, err = store.LoadWatchlistContent(ctx, id)
+	assert.True(t, os.IsNotExist(err), "The content is deleted with the watch list")
+}
+
+func TestStoreContent(t *testing.T) {
+	var ctx = context.Background()
+	var store = newTestStore(t, Config{HistoryMaxSnapshots: 3, HistoryMaxAge: 24 * 60 * 60})
+	var now = time.Now().UTC()
+
+	_, err := store.LoadWatchlistContent(ctx, "abc")
+	assert.True(t, os.IsNotExist(err))
+	_, err = store.LoadWatchlistChangeset(ctx, "abc")
+	assert.True(t, os.IsNotExist(err))
+
+	//Two days old, removed by age once there is a newer snapshot.
+	_, err = store.SaveWatchlistContent(ctx, &model.WatchlistContent{WatchlistID: "abc", Timestamp: now.Add(-48 * time.Hour)})
+	assert.NoError(t, err)
+	for i := 4; i > 0; i-- {
+		_, err = store.SaveWatchlistContent(ctx, &model.WatchlistContent{
+			WatchlistID:  "abc",
+			Timestamp:    now.Add(-time.Duration(i) * time.Minute),
+			AuctionItems: []model.AuctionItem{{ItemName: "drill", TotalBids: i}},
+		})
+		assert.NoError(t, err)
+	}
+
+	content, err := store.LoadWatchlistContent(ctx, "abc")
+	assert.NoError(t, err)
+	assert.True(t, content.Timestamp.Equal(now.Add(-time.Minute)))
+	assert.Equal(t, 1, content.AuctionItems[0].TotalBids)
+
+	snapshots, err := store.ListWatchlistSnapshots(ctx, "abc")
+	assert.NoError(t, err)
+	if assert.Len(t, snapshots, 3) {
+		assert.True(t, snapshots[0].Timestamp.Equal(now.Add(-3*time.Minute)))
+		assert.True(t, snapshots[2].Timestamp.Equal(now.Add(-1*time.Minute)))
+		snapshot, err := store.LoadWatchlistSnapshot(ctx, "abc", snapshots[0].ID)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, snapshot.AuctionItems[0].TotalBids)
+	}
+	_, err = store.LoadWatchlistSnapshot(ctx, "abc", "../models")
+	assert.Error(t, err)
+	_, err = store.LoadWatchlistSnapshot(ctx, "abc", now.Format(snapshotIDFormat))
+	assert.True(t, os.IsNotExist(err))
+
+	assert.NoError(t, store.SaveWatchlistChangeset(ctx, &model.WatchlistChangeset{WatchlistID: "abc", ContentID: content.ID()}))
+	changeset, err := store.LoadWatchlistChangeset(ctx, "abc")
+	assert.NoError(t, err)
+	assert.Equal(t, content.ID(), changeset.ContentID)
+
+	state, err := store.LoadWatchlistScanState(ctx, "abc")
+	assert.NoError(t, err)
+	assert.Equal(t, &model.WatchlistScanState{WatchlistID: "abc"}, state, "Never searched")
+	state.Succeeded(now)
+	assert.NoError(t, store.SaveWatchlistScanState(ctx, state))
+	state, _ = store.LoadWatchlistScanState(ctx, "abc")
+	assert.True(t, state.LastSuccess.Equal(now))
+
+	assert.NoError(t, store.CopyWatchlistContent(ctx, "abc", "def"))
+	copied, err := store.LoadWatchlistContent(ctx, "def")
+	assert.NoError(t, err)
+	assert.Equal(t, content.AuctionItems, copied.AuctionItems)
+	snapshots, _ = store.ListWatchlistSnapshots(ctx, "def")
+	assert.Len(t, snapshots, 3)
+	state, _ = store.LoadWatchlistScanState(ctx, "def")
+	assert.True(t, state.LastSuccess.Equal(now))
+
+	assert.NoError(t, store.DeleteWatchlistContent(ctx, "abc"))
+	assert.True(t, os.IsNotExist(store.DeleteWatchlistContent(ctx, "abc")))
+}
+
+func TestStoreArchiveWatchlist(t *testing.T) {
+	var ctx = context.Background()
+	var store = newTestStore(t, Config{})
+	var archiveDir = t.TempDir()
+
+	id, err := store.SaveWatchlist(ctx, model.WatchlistDefinition{Keywords: []string{"drill"}})
+	assert.NoError(t, err)
+	_, err = store.SaveWatchlistContent(ctx, &model.WatchlistContent{WatchlistID: id, Timestamp: time.Now()})
+	assert.NoError(t, err)
+
+	assert.NoError(t, store.ArchiveWatchlist(ctx, id, archiveDir))
+	for _, fileName := range []string{"data.json", "models.json"} {
+		assert.FileExists(t, filepath.Join(archiveDir, id, fileName))
+	}
+	assert.NoFileExists(t, filepath.Join(archiveDir, id, "schedule.json"), "The watch list had no schedules")
+	_, err = store.LoadWatchlist(ctx, id)
+	assert.True(t, os.IsNotExist(err))
+	assert.True(t, os.IsNotExist(store.ArchiveWatchlist(ctx, id, archiveDir)))
+}
+
+func TestStoreConformance(t *testing.T) {
+	storetest.WatchlistStorer(t, func(t *testing.T) store.WatchlistStorer { return newTestStore(t, Config{}) })
+	storetest.WatchlistContentStorer(t, func(t *testing.T) store.Storer { return newTestStore(t, Config{}) })
+}
@@ -14,14 +14,58 @@ type Storer interface {
 
 //WatchlistStorer storeable to perform watchlist store operations.
 type WatchlistStorer interface {
-	SaveWatchlist(ctx context.Context, watchlist model.Watchlist) (string, error)
-	LoadWatchlist(ctx context.Context, watchlistID string) (model.Watchlist, error)
+	SaveWatchlist(ctx context.Context, watchlist model.WatchlistDefinition) (string, error)
+	LoadWatchlist(ctx context.Context, watchlistID string) (model.WatchlistDefinition, error)
+	//DeleteWatchlist os.ErrNotExist if there is no such watch list.
 	DeleteWatchlist(ctx context.Context, watchlistID string) error
+	//ListWatchlists the IDs of every stored watch list.
+	ListWatchlists(ctx context.Context) ([]string, error)
 }
 
-//WatchlistContentStorer storeable to perform watchlist content store operations.
+//WatchlistContentCopier store able to carry a watch list's content over to another watch list. Editing a watch list changes its ID, the edited list keeps the content of the original.
+type WatchlistContentCopier interface {
+	CopyWatchlistContent(ctx context.Context, fromWatchlistID string, toWatchlistID string) error
+}
+
+//WatchlistScheduler store able to save when a watch list is searched, the schedules of every user of the watch list. A watch list without saved schedules is always searched.
+type WatchlistScheduler interface {
+	SaveWatchlistSchedules(ctx context.Context, watchlistID string, schedules model.WatchlistSchedules) error
+	LoadWatchlistSchedules(ctx context.Context, watchlistID string) (model.WatchlistSchedules, error)
+}
+
+//WatchlistArchiver store able to move a watch list out of the way, instead of deleting it, so it can be restored.
+type WatchlistArchiver interface {
+	ArchiveWatchlist(ctx context.Context, watchlistID string, archiveDir string) error
+}
+
+//WatchlistContentStorer storeable to perform watchlist content store operations. It also keeps the updater's state of each watch list, so change detection works with any store.
 type WatchlistContentStorer interface {
 	SaveWatchlistContent(ctx context.Context, watchlistContent *model.WatchlistContent) (string, error)
 	LoadWatchlistContent(ctx context.Context, watchlistContentID string) (*model.WatchlistContent, error)
 	DeleteWatchlistContent(ctx context.Context, watchlistContentID string) error
+	SaveWatchlistScanState(ctx context.Context, state *model.WatchlistScanState) error
+	//LoadWatchlistScanState the watch list's state, an empty state if it was never searched.
+	LoadWatchlistScanState(ctx context.Context, watchlistID string) (*model.WatchlistScanState, error)
+}
+
+//WatchlistChangesetStorer store able to keep what changed the last time a watch list's content was saved, see model.DiffWatchlistContent.
+type WatchlistChangesetStorer interface {
+	SaveWatchlistChangeset(ctx context.Context, changeset *model.WatchlistChangeset) error
+	LoadWatchlistChangeset(ctx context.Context, watchlistID string) (*model.WatchlistChangeset, error)
+}
+
+//WatchlistHistoryStorer store able to keep every version of a watch list's content. Snapshots are never changed, old ones are removed by the store's retention policy.
+type WatchlistHistoryStorer interface {
+	//ListWatchlistSnapshots the watch list's snapshots oldest first.
+	ListWatchlistSnapshots(ctx context.Context, watchlistID string) ([]model.WatchlistSnapshot, error)
+	LoadWatchlistSnapshot(ctx context.Context, watchlistID string, snapshotID string) (*model.WatchlistContent, error)
+}
+
+//ItemArchiver store able to keep the items whose auction closed, keyed by item ID, long after they dropped out of every watch list.
+type ItemArchiver interface {
+	//ArchiveItems saves the items, an item already archived is merged, see model.ArchivedItem.Merge.
+	ArchiveItems(ctx context.Context, items []model.ArchivedItem) error
+	LoadArchivedItem(ctx context.Context, itemID string) (*model.ArchivedItem, error)
+	//QueryArchivedItems the archived items the query selects, most recently closed first.
+	QueryArchivedItems(ctx context.Context, query model.ArchiveQuery) ([]model.ArchivedItem, error)
 }
@@ -0,0 +1,233 @@
+//Package storetest the behavior every watch list and content store must have, run from the tests of each store so they stay interchangeable. A store that fails is not a drop in replacement for the others.
+package storetest
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/model"
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/store"
+)
+
+//concurrency the goroutines the concurrent tests run at once.
+const concurrency = 8
+
+//WatchlistStorer runs the watch list store tests, newStore returns an empty store for each test.
+func WatchlistStorer(t *testing.T, newStore func(t *testing.T) store.WatchlistStorer) {
+	var ctx = context.Background()
+	var list = model.WatchlistDefinition{Keywords: []string{"drill", "saw"}, Metadata: model.WatchlistMetadata{Name: "tools"}}
+
+	t.Run("not found", func(t *testing.T) {
+		s := newStore(t)
+		_, err := s.LoadWatchlist(ctx, list.ID())
+		assert.True(t, os.IsNotExist(err), "Loading a missing watch list is a not exist error, got %v", err)
+		ids, err := s.ListWatchlists(ctx)
+		assert.NoError(t, err)
+		assert.Empty(t, ids)
+	})
+
+	t.Run("save and load", func(t *testing.T) {
+		s := newStore(t)
+		id, err := s.SaveWatchlist(ctx, list)
+		assert.NoError(t, err)
+		assert.Equal(t, list.ID(), id, "The ID is the definition's")
+
+		loaded, err := s.LoadWatchlist(ctx, id)
+		assert.NoError(t, err)
+		assert.Equal(t, []string(list.Keywords), []string(loaded.Keywords))
+		assert.Equal(t, "tools", loaded.Metadata.Name)
+		assert.False(t, loaded.Metadata.Created.IsZero(), "The created time is set")
+		ids, err := s.ListWatchlists(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{id}, ids)
+	})
+
+	t.Run("overwrite", func(t *testing.T) {
+		s := newStore(t)
+		id, err := s.SaveWatchlist(ctx, list)
+		assert.NoError(t, err)
+		again := list
+		again.Metadata.Name = "other"
+		againID, err := s.SaveWatchlist(ctx, again)
+		assert.NoError(t, err)
+		assert.Equal(t, id, againID)
+
+		loaded, err := s.LoadWatchlist(ctx, id)
+		assert.NoError(t, err)
+		assert.Equal(t, "tools", loaded.Metadata.Name, "Saving an existing watch list keeps the first one")
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		s := newStore(t)
+		id, err := s.SaveWatchlist(ctx, list)
+		assert.NoError(t, err)
+		assert.NoError(t, s.DeleteWatchlist(ctx, id))
+		_, err = s.LoadWatchlist(ctx, id)
+		assert.True(t, os.IsNotExist(err), "A deleted watch list is not found, got %v", err)
+		ids, _ := s.ListWatchlists(ctx)
+		assert.Empty(t, ids)
+		err = s.DeleteWatchlist(ctx, id)
+		assert.True(t, os.IsNotExist(err), "Deleting a missing watch list is a not exist error, got %v", err)
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		s := newStore(t)
+		var wg sync.WaitGroup
+		for i := 0; i < concurrency; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				//Everyone saves the shared watch list and one of their own.
+				_, err := s.SaveWatchlist(ctx, list)
+				assert.NoError(t, err)
+				id, err := s.SaveWatchlist(ctx, model.WatchlistDefinition{Keywords: []string{fmt.Sprintf("item %d", i)}})
+				assert.NoError(t, err)
+				_, err = s.LoadWatchlist(ctx, id)
+				assert.NoError(t, err)
+			}(i)
+		}
+		wg.Wait()
+
+		ids, err := s.ListWatchlists(ctx)
+		assert.NoError(t, err)
+		assert.Len(t, ids, concurrency+1)
+	})
+}
+
+//WatchlistContentStorer runs the content store tests, newStore returns an empty store for each test. Content belongs to a watch list, the tests save the watch list first.
+func WatchlistContentStorer(t *testing.T, newStore func(t *testing.T) store.Storer) {
+	var ctx = context.Background()
+	var now = time.Now().UTC().Truncate(time.Millisecond)
+
+	newWatchlist := func(t *testing.T, s store.Storer, keyword string) string {
+		id, err := s.SaveWatchlist(ctx, model.WatchlistDefinition{Keywords: []string{keyword}})
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		return id
+	}
+	newContent := func(id string, bid float64, at time.Time) *model.WatchlistContent {
+		return &model.WatchlistContent{
+			WatchlistID:  id,
+			Timestamp:    at,
+			AuctionItems: []model.AuctionItem{{Id: "1", ItemName: "drill", ParentAuctionID: "a", CurrentBidAmount: bid}},
+		}
+	}
+
+	t.Run("not found", func(t *testing.T) {
+		s := newStore(t)
+		id := newWatchlist(t, s, "drill")
+		_, err := s.LoadWatchlistContent(ctx, id)
+		assert.True(t, os.IsNotExist(err), "Loading missing content is a not exist error, got %v", err)
+		assert.True(t, os.IsNotExist(s.DeleteWatchlistContent(ctx, id)), "Deleting missing content is a not exist error")
+
+		state, err := s.LoadWatchlistScanState(ctx, id)
+		assert.NoError(t, err)
+		assert.Equal(t, &model.WatchlistScanState{WatchlistID: id}, state, "A watch list never searched has an empty state")
+	})
+
+	t.Run("save and load", func(t *testing.T) {
+		s := newStore(t)
+		id := newWatchlist(t, s, "drill")
+		content := newContent(id, 10, now)
+		contentID, err := s.SaveWatchlistContent(ctx, content)
+		assert.NoError(t, err)
+		assert.Equal(t, content.ID(), contentID, "The ID is the content's")
+
+		loaded, err := s.LoadWatchlistContent(ctx, id)
+		assert.NoError(t, err)
+		assert.Equal(t, content.AuctionItems, loaded.AuctionItems)
+		assert.True(t, content.Timestamp.Equal(loaded.Timestamp))
+		assert.Equal(t, content.ID(), loaded.ID())
+
+		//What is done to loaded content does not change the stored content.
+		loaded.AuctionItems[0].CurrentBidAmount = 99
+		loaded, _ = s.LoadWatchlistContent(ctx, id)
+		assert.Equal(t, 10.0, loaded.AuctionItems[0].CurrentBidAmount)
+	})
+
+	t.Run("overwrite", func(t *testing.T) {
+		s := newStore(t)
+		id := newWatchlist(t, s, "drill")
+		_, err := s.SaveWatchlistContent(ctx, newContent(id, 10, now.Add(-time.Minute)))
+		assert.NoError(t, err)
+		_, err = s.SaveWatchlistContent(ctx, newContent(id, 20, now))
+		assert.NoError(t, err)
+
+		loaded, err := s.LoadWatchlistContent(ctx, id)
+		assert.NoError(t, err)
+		assert.Equal(t, 20.0, loaded.AuctionItems[0].CurrentBidAmount, "Saving replaces the content")
+
+		state := model.WatchlistScanState{WatchlistID: id}
+		state.Failed(now, fmt.Errorf("timeout"))
+		assert.NoError(t, s.SaveWatchlistScanState(ctx, &state))
+		state.Succeeded(now.Add(time.Minute))
+		assert.NoError(t, s.SaveWatchlistScanState(ctx, &state))
+		loadedState, err := s.LoadWatchlistScanState(ctx, id)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, loadedState.Failures, "Saving replaces the state")
+		assert.True(t, loadedState.LastSuccess.Equal(now.Add(time.Minute)))
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		s := newStore(t)
+		id := newWatchlist(t, s, "drill")
+		other := newWatchlist(t, s, "saw")
+		for _, listID := range []string{id, other} {
+			_, err := s.SaveWatchlistContent(ctx, newContent(listID, 10, now))
+			assert.NoError(t, err)
+		}
+
+		assert.NoError(t, s.DeleteWatchlistContent(ctx, id))
+		_, err := s.LoadWatchlistContent(ctx, id)
+		assert.True(t, os.IsNotExist(err), "Deleted content is not found, got %v", err)
+		_, err = s.LoadWatchlist(ctx, id)
+		assert.NoError(t, err, "Deleting the content keeps the watch list")
+
+		assert.NoError(t, s.DeleteWatchlist(ctx, other))
+		_, err = s.LoadWatchlistContent(ctx, other)
+		assert.True(t, os.IsNotExist(err), "Deleting the watch list deletes its content, got %v", err)
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		s := newStore(t)
+		shared := newWatchlist(t, s, "drill")
+		var ids = make([]string, concurrency)
+		for i := range ids {
+			ids[i] = newWatchlist(t, s, fmt.Sprintf("item %d", i))
+		}
+
+		var wg sync.WaitGroup
+		for i := 0; i < concurrency; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				for j := 0; j < 5; j++ {
+					at := now.Add(time.Duration(i*5+j) * time.Millisecond)
+					for _, id := range []string{shared, ids[i]} {
+						_, err := s.SaveWatchlistContent(ctx, newContent(id, float64(j), at))
+						assert.NoError(t, err)
+						//A reader sees one whole save or another, never part of one.
+						loaded, err := s.LoadWatchlistContent(ctx, id)
+						if assert.NoError(t, err) {
+							assert.Len(t, loaded.AuctionItems, 1)
+						}
+					}
+				}
+			}(i)
+		}
+		wg.Wait()
+
+		for _, id := range ids {
+			loaded, err := s.LoadWatchlistContent(ctx, id)
+			assert.NoError(t, err)
+			assert.Equal(t, 4.0, loaded.AuctionItems[0].CurrentBidAmount, "The last save wins")
+		}
+	})
+}
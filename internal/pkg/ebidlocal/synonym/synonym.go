@@ -0,0 +1,162 @@
+//Package synonym expands watch list queries with synonyms so a watch list for "couch" also finds "sectional".
+//
+//A dictionary is a list of groups of interchangeable terms, a term is a word or a phrase.
+//
+//	[["couch", "sofa", "loveseat", "settee", "sectional"], ["tv", "television"]]
+package synonym
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/query"
+)
+
+//Dictionary groups of interchangeable terms. A term may be in more than one group, its synonyms are the other terms of all of its groups. The nil Dictionary has no synonyms.
+type Dictionary struct {
+	groups [][]string
+	index  map[string][]int
+}
+
+//New a dictionary of the groups, terms are lower cased and trimmed.
+func New(groups [][]string) *Dictionary {
+	var d = Dictionary{index: make(map[string][]int)}
+	return d.add(groups)
+}
+
+//Load a dictionary from a JSON file of groups.
+func Load(fileName string) (*Dictionary, error) {
+	var groups [][]string
+
+	b, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &groups); err != nil {
+		return nil, fmt.Errorf("synonyms '%s': %w", fileName, err)
+	}
+	if err := Validate(groups); err != nil {
+		return nil, fmt.Errorf("synonyms '%s': %w", fileName, err)
+	}
+	return New(groups), nil
+}
+
+//Validate each term must be a word or a phrase, something a query can search for that is not an operator.
+func Validate(groups [][]string) error {
+	for _, group := range groups {
+		for _, term := range group {
+			if err := validateTerm(Normalize(term)); err != nil {
+				return fmt.Errorf("synonym '%s': %w", term, err)
+			}
+		}
+	}
+	return nil
+}
+
+//Normalize a term the way a dictionary stores it.
+func Normalize(term string) string {
+	return strings.Join(strings.Fields(strings.ToLower(term)), " ")
+}
+
+//Merge a new dictionary with the groups of both.
+func (d *Dictionary) Merge(groups [][]string) *Dictionary {
+	var merged = New(nil)
+	if d != nil {
+		merged.add(d.groups)
+	}
+	return merged.add(groups)
+}
+
+//Synonyms the other terms of the term's groups, in the order they were added.
+func (d *Dictionary) Synonyms(term string) []string {
+	if d == nil {
+		return nil
+	}
+
+	var synonyms []string
+	var seen = map[string]struct{}{Normalize(term): {}}
+	for _, g := range d.index[Normalize(term)] {
+		for _, synonym := range d.groups[g] {
+			if _, exists := seen[synonym]; !exists {
+				seen[synonym] = struct{}{}
+				synonyms = append(synonyms, synonym)
+			}
+		}
+	}
+	return synonyms
+}
+
+//Groups the groups that have one of the terms in them.
+func (d *Dictionary) Groups(terms []string) [][]string {
+	if d == nil {
+		return nil
+	}
+
+	var found = make(map[int]struct{})
+	for _, term := range terms {
+		for _, g := range d.index[Normalize(term)] {
+			found[g] = struct{}{}
+		}
+	}
+	var indexes = make([]int, 0, len(found))
+	for g := range found {
+		indexes = append(indexes, g)
+	}
+	sort.Ints(indexes)
+
+	var groups = make([][]string, 0, len(indexes))
+	for _, g := range indexes {
+		groups = append(groups, d.groups[g])
+	}
+	return groups
+}
+
+//Expand the query with the synonyms of its terms, see query.Query.Expand.
+func (d *Dictionary) Expand(q *query.Query) *query.Query {
+	return q.Expand(d.Synonyms)
+}
+
+func (d *Dictionary) add(groups [][]string) *Dictionary {
+	for _, group := range groups {
+		var terms []string
+		for _, term := range group {
+			if term = Normalize(term); term != "" {
+				terms = append(terms, term)
+			}
+		}
+		if len(terms) < 2 {
+			continue
+		}
+
+		d.groups = append(d.groups, terms)
+		for _, term := range terms {
+			d.index[term] = append(d.index[term], len(d.groups)-1)
+		}
+	}
+	return d
+}
+
+func validateTerm(term string) error {
+	if term == "" {
+		return fmt.Errorf("empty term")
+	}
+	if strings.ContainsAny(term, `"()`) || query.IsPattern(term) {
+		return fmt.Errorf("a term is a word or phrase")
+	}
+	if strings.Contains(term, " ") {
+		_, err := query.Parse(`"` + term + `"`)
+		return err
+	}
+
+	q, err := query.Parse(term)
+	if err != nil {
+		return err
+	}
+	if w, ok := q.Root.(query.Word); !ok || w.Fuzzy >= 0 || w.Text != term {
+		return fmt.Errorf("a term is a word or phrase")
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package synonym
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/query"
+)
+
+func TestSynonyms(t *testing.T) {
+	var d = New([][]string{{"Couch", "sofa", "  love   seat "}, {"sofa", "divan"}, {"lonely"}})
+
+	assert.Equal(t, []string{"sofa", "love seat"}, d.Synonyms("couch"))
+	assert.Equal(t, []string{"couch", "love seat", "divan"}, d.Synonyms("SOFA"))
+	assert.Nil(t, d.Synonyms("lonely"), "A group needs more than one term")
+	assert.Nil(t, (*Dictionary)(nil).Synonyms("couch"))
+
+	assert.Equal(t, [][]string{{"sofa", "divan"}}, d.Groups([]string{"divan", "chair"}))
+
+	merged := d.Merge([][]string{{"couch", "sectional"}})
+	assert.Equal(t, []string{"sofa", "love seat", "sectional"}, merged.Synonyms("couch"))
+	assert.Equal(t, []string{"sofa", "love seat"}, d.Synonyms("couch"), "Merging does not change the dictionary")
+}
+
+func TestExpand(t *testing.T) {
+	var d = New([][]string{{"couch", "sofa", "love seat"}, {"leather", "pleather"}})
+
+	var tests = []struct {
+		Query    string
+		Expected string
+	}{
+		{Query: "couch", Expected: `couch OR sofa OR "love seat"`},
+		{Query: "red couch", Expected: `red AND (couch OR sofa OR "love seat")`},
+		{Query: "couch -leather", Expected: `(couch OR sofa OR "love seat") AND NOT leather`},
+		{Query: "chair", Expected: "chair"},
+		{Query: `re:couch\d`, Expected: `re:couch\d`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Query, func(t *testing.T) {
+			q, err := query.Parse(test.Query)
+			assert.NoError(t, err)
+
+			expanded := d.Expand(q)
+			assert.Equal(t, test.Expected, expanded.String())
+			assert.Equal(t, test.Query, expanded.Raw)
+			assert.NoError(t, query.Validate(expanded.String()), "Expanded queries parse")
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Validate([][]string{{"couch", "Love Seat", "x-ray"}}))
+	for _, term := range []string{"", "or", "-couch", "couch~", `"couch"`, "(couch)", "re:couch"} {
+		assert.Error(t, Validate([][]string{{"sofa", term}}), term)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	d, err := Load(filepath.Join("..", "..", "..", "..", "assets", "synonyms.json"))
+	assert.NoError(t, err, "The shared dictionary is valid")
+	assert.Contains(t, d.Synonyms("couch"), "sectional")
+
+	var fileName = filepath.Join(t.TempDir(), "synonyms.json")
+	assert.NoError(t, os.WriteFile(fileName, []byte(`[["sofa", "or"]]`), 0644))
+	_, err = Load(fileName)
+	assert.Error(t, err)
+
+	_, err = Load(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,81 @@
+//Package fileutils writes files so a crash, or a reader such as the file server, never sees part of a write, and locks directories so the server and scanner processes never interleave their writes.
+package fileutils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+//tempMarker marks the temp files writes are made to before they are renamed over the real file, see IsTemp.
+const tempMarker = ".tmp-"
+
+//WriteFile writes data to a temp file next to name, syncs it and renames it over name. Readers see the old file or the new one, never part of one.
+func WriteFile(name string, data []byte, perm os.FileMode) error {
+	temp, err := writeTemp(name, data, perm)
+	if err != nil {
+		return err
+	}
+	if err = os.Rename(temp, name); err != nil {
+		os.Remove(temp)
+		return err
+	}
+	return syncDir(filepath.Dir(name))
+}
+
+//WriteNewFile like WriteFile but the file must not exist, os.ErrExist if it does. Used for files that are never changed once written.
+func WriteNewFile(name string, data []byte, perm os.FileMode) error {
+	temp, err := writeTemp(name, data, perm)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp)
+	//A hard link, unlike a rename, fails when name exists.
+	if err = os.Link(temp, name); err != nil {
+		if os.IsExist(err) {
+			return &os.PathError{Op: "write", Path: name, Err: os.ErrExist}
+		}
+		return err
+	}
+	return syncDir(filepath.Dir(name))
+}
+
+//IsTemp true if the file is a temp file of WriteFile or WriteNewFile. One left behind is from a write that never finished.
+func IsTemp(name string) bool {
+	base := filepath.Base(name)
+	return strings.HasPrefix(base, ".") && strings.Contains(base, tempMarker)
+}
+
+func writeTemp(name string, data []byte, perm os.FileMode) (string, error) {
+	file, err := os.CreateTemp(filepath.Dir(name), fmt.Sprintf(".%s%s*", filepath.Base(name), tempMarker))
+	if err != nil {
+		return "", err
+	}
+	temp := file.Name()
+	if _, err = file.Write(data); err == nil {
+		err = file.Sync()
+	}
+	if err == nil {
+		err = file.Chmod(perm)
+	}
+	if err2 := file.Close(); err == nil {
+		err = err2
+	}
+	if err != nil {
+		os.Remove(temp)
+		return "", err
+	}
+	return temp, nil
+}
+
+//syncDir makes the rename durable, not every system can sync a directory so failing to is not an error.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return nil
+	}
+	defer d.Close()
+	d.Sync()
+	return nil
+}
@@ -0,0 +1,127 @@
+package fileutils
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteFile(t *testing.T) {
+	var name = filepath.Join(t.TempDir(), "data.json")
+
+	assert.NoError(t, WriteFile(name, []byte(`{"a":1}`), 0644))
+	assert.NoError(t, WriteFile(name, []byte(`{}`), 0600))
+	b, err := os.ReadFile(name)
+	assert.NoError(t, err)
+	assert.Equal(t, `{}`, string(b), "The file is replaced, not written over")
+	info, _ := os.Stat(name)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	entries, _ := os.ReadDir(filepath.Dir(name))
+	assert.Len(t, entries, 1, "No temp files are left behind")
+}
+
+func TestWriteNewFile(t *testing.T) {
+	var name = filepath.Join(t.TempDir(), "snapshot.json")
+
+	assert.NoError(t, WriteNewFile(name, []byte(`1`), 0644))
+	err := WriteNewFile(name, []byte(`2`), 0644)
+	assert.True(t, os.IsExist(err))
+	b, _ := os.ReadFile(name)
+	assert.Equal(t, `1`, string(b))
+
+	entries, _ := os.ReadDir(filepath.Dir(name))
+	assert.Len(t, entries, 1, "No temp files are left behind")
+}
+
+func TestLock(t *testing.T) {
+	var dir = t.TempDir()
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var holders, most int
+
+	_, err := Lock(filepath.Join(dir, "missing"))
+	assert.Error(t, err)
+
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock, err := Lock(dir)
+			if !assert.NoError(t, err) {
+				return
+			}
+			mu.Lock()
+			holders++
+			if holders > most {
+				most = holders
+			}
+			mu.Unlock()
+			time.Sleep(5 * time.Millisecond)
+			mu.Lock()
+			holders--
+			mu.Unlock()
+			assert.NoError(t, unlock())
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 1, most, "Only one holder at a time")
+}
+
+func TestRecover(t *testing.T) {
+	var dir = t.TempDir()
+	var sub = filepath.Join(dir, "abc")
+
+	assert.NoError(t, os.MkdirAll(sub, 0775))
+	assert.NoError(t, os.WriteFile(filepath.Join(sub, "data.json"), []byte(`{"a":1}`), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(sub, "models.json"), []byte(`{"a":`), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(sub, ".models.json.tmp-123"), []byte(`{"a":1`), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(sub, "hash"), []byte(`abc`), 0644))
+
+	removed, corrupt, err := Recover(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(sub, ".models.json.tmp-123")}, removed)
+	assert.Equal(t, []string{filepath.Join(sub, "models.json")}, corrupt)
+	_, err = os.Stat(filepath.Join(sub, ".models.json.tmp-123"))
+	assert.True(t, os.IsNotExist(err))
+
+	removed, corrupt, err = Recover(filepath.Join(dir, "missing"))
+	assert.NoError(t, err)
+	assert.Empty(t, removed)
+	assert.Empty(t, corrupt)
+}
+
+func TestRecoverWaitsForTheWatchlistLock(t *testing.T) {
+	var dir = t.TempDir()
+	var sub = filepath.Join(dir, "abc")
+	var history = filepath.Join(sub, "history")
+	var temp = filepath.Join(history, ".1.json.tmp-123")
+
+	assert.NoError(t, os.MkdirAll(history, 0775))
+	assert.NoError(t, os.WriteFile(temp, []byte(`{"a":1`), 0644))
+
+	//A writer of the history holds the lock of the watch list's directory, not of the history directory.
+	unlock, err := Lock(sub)
+	assert.NoError(t, err)
+	var done = make(chan []string)
+	go func() {
+		removed, _, err := Recover(dir)
+		assert.NoError(t, err)
+		done <- removed
+	}()
+	select {
+	case <-done:
+		t.Fatal("Recover did not wait for the writer")
+	case <-time.After(50 * time.Millisecond):
+	}
+	assert.NoError(t, os.Rename(temp, filepath.Join(history, "1.json")))
+	assert.NoError(t, unlock())
+	assert.Empty(t, <-done, "The write finished while waiting for the lock")
+
+	assert.Equal(t, dir, lockDir(dir, filepath.Join(dir, ".index.json.tmp-1")))
+	assert.Equal(t, sub, lockDir(dir, filepath.Join(sub, ".data.json.tmp-1")))
+}
@@ -0,0 +1,22 @@
+package fileutils
+
+import (
+	"os"
+	"path/filepath"
+)
+
+//LockFileName the file in a directory Lock locks.
+const LockFileName = ".lock"
+
+//Lock takes an exclusive advisory lock on the directory, waiting until no other process or goroutine holds it. Everything that writes to the directory should hold its lock. The directory must exist.
+func Lock(dir string) (unlock func() error, err error) {
+	if _, err = os.Stat(dir); err != nil {
+		return nil, err
+	}
+	return lockFile(filepath.Join(dir, LockFileName))
+}
+
+//IsLock true if the file is a lock file of Lock.
+func IsLock(name string) bool {
+	return filepath.Base(name) == LockFileName
+}
@@ -0,0 +1,24 @@
+//go:build !unix
+
+package fileutils
+
+import (
+	"path/filepath"
+	"sync"
+)
+
+var locks sync.Map
+
+//lockFile without flock the directory is only locked against the goroutines of this process.
+func lockFile(name string) (func() error, error) {
+	abs, err := filepath.Abs(name)
+	if err != nil {
+		return nil, err
+	}
+	mu, _ := locks.LoadOrStore(abs, &sync.Mutex{})
+	mu.(*sync.Mutex).Lock()
+	return func() error {
+		mu.(*sync.Mutex).Unlock()
+		return nil
+	}, nil
+}
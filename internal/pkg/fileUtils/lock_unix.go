@@ -0,0 +1,29 @@
+//go:build unix
+
+package fileutils
+
+import (
+	"os"
+	"syscall"
+)
+
+//lockFile flock is held by the open file so it is released if the process dies.
+func lockFile(name string) (func() error, error) {
+	file, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if err = syscall.Flock(int(file.Fd()), syscall.LOCK_EX); err != syscall.EINTR {
+			break
+		}
+	}
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return func() error {
+		defer file.Close()
+		return syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+	}, nil
+}
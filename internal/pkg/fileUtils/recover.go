@@ -0,0 +1,67 @@
+package fileutils
+
+import (
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+//Recover checks dir and its sub directories after a crash. Temp files of writes that never finished are removed, a write another process is making while holding the lock of the directory below dir it writes in, such as a watch list's or user's directory, is waited for, and the JSON files that do not parse, written before writes were atomic, are returned so the caller can restore or report them. A missing dir has nothing to recover.
+func Recover(dir string) (removed []string, corrupt []string, err error) {
+	err = filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == dir {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if entry.IsDir() || !entry.Type().IsRegular() {
+			return nil
+		}
+		if IsTemp(path) {
+			if ok, err := removeTemp(lockDir(dir, path), path); err != nil {
+				return err
+			} else if ok {
+				removed = append(removed, path)
+			}
+			return nil
+		}
+		if strings.HasSuffix(path, ".json") {
+			b, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			if !json.Valid(b) {
+				corrupt = append(corrupt, path)
+			}
+		}
+		return nil
+	})
+	return removed, corrupt, err
+}
+
+//lockDir the directory writers lock to write the file: the directory below dir the file is in, such as a watch list's directory for the files of its history too, or dir itself for the files directly in it.
+func lockDir(dir string, path string) string {
+	rel, err := filepath.Rel(dir, filepath.Dir(path))
+	if err != nil || rel == "." {
+		return dir
+	}
+	return filepath.Join(dir, strings.Split(rel, string(filepath.Separator))[0])
+}
+
+//removeTemp removes the temp file while holding the lock of lockDir, false if the write finished while waiting for the lock.
+func removeTemp(lockDir string, path string) (bool, error) {
+	unlock, err := Lock(lockDir)
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+	if err := os.Remove(path); os.IsNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return true, nil
+}
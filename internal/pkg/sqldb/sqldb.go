@@ -0,0 +1,119 @@
+//Package sqldb opens SQLite databases, with the pure Go driver so no cgo is needed, and keeps their schemas up to date. More than one process may use the same database, writes wait for each other.
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	_ "modernc.org/sqlite"
+)
+
+//DriverName the database/sql driver the databases are opened with.
+const DriverName = "sqlite"
+
+//Config where the database is and how long a write waits for another one.
+type Config struct {
+	//Path the database file, created if it does not exist.
+	Path string `json:"path"`
+	//BusyTimeout milliseconds a write waits for another process's write to finish.
+	BusyTimeout int `json:"busyTimeoutMs"`
+}
+
+//Defaults the database is ebidlocal.db in contentPath.
+func Defaults(config *Config, contentPath string) *Config {
+	if config.Path == "" {
+		config.Path = filepath.Join(contentPath, "ebidlocal.db")
+	}
+	if config.BusyTimeout <= 0 {
+		config.BusyTimeout = 5000
+	}
+	return config
+}
+
+//Open the database in write-ahead log mode, so reads do not wait for writes, with foreign keys on. Transactions begin immediately, see InTx.
+func Open(config Config) (*sql.DB, error) {
+	if config.Path == "" {
+		return nil, fmt.Errorf("no database path")
+	}
+	if err := os.MkdirAll(filepath.Dir(config.Path), 0775); err != nil {
+		return nil, err
+	}
+	var pragmas = url.Values{}
+	pragmas.Set("_txlock", "immediate")
+	for _, pragma := range []string{
+		fmt.Sprintf("busy_timeout(%d)", config.BusyTimeout),
+		"journal_mode(WAL)",
+		"synchronous(NORMAL)",
+		"foreign_keys(1)",
+	} {
+		pragmas.Add("_pragma", pragma)
+	}
+
+	//The path is escaped, a # or ? in it would otherwise end it in the URI.
+	path := strings.NewReplacer("%", "%25", "?", "%3f", "#", "%23").Replace(config.Path)
+	db, err := sql.Open(DriverName, "file:"+path+"?"+pragmas.Encode())
+	if err != nil {
+		return nil, err
+	}
+	if err = db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+//Migrate applies the migrations of schema the database does not have yet, in order. A migration's version is its index plus one, so migrations are only ever appended. The pending migrations are applied in one transaction, which holds the write lock, so two processes starting at once do not both apply them. Returns the number of migrations applied.
+func Migrate(ctx context.Context, db *sql.DB, schema string, migrations []string) (applied int, err error) {
+	if _, err = db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		schema TEXT NOT NULL,
+		version INTEGER NOT NULL,
+		applied_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+		PRIMARY KEY (schema, version)
+	)`); err != nil {
+		return 0, err
+	}
+
+	err = InTx(ctx, db, func(tx *sql.Tx) error {
+		var version int
+		if err := tx.QueryRowContext(ctx, "SELECT COALESCE(MAX(version), 0) FROM schema_migrations WHERE schema = ?", schema).Scan(&version); err != nil {
+			return err
+		}
+		if version > len(migrations) {
+			return fmt.Errorf("database schema '%s' is version %d, newer than this version of the app knows, %d", schema, version, len(migrations))
+		}
+		for i := version; i < len(migrations); i++ {
+			if _, err := tx.ExecContext(ctx, migrations[i]); err != nil {
+				return fmt.Errorf("schema '%s' migration %d; %w", schema, i+1, err)
+			}
+			if _, err := tx.ExecContext(ctx, "INSERT INTO schema_migrations (schema, version) VALUES (?, ?)", schema, i+1); err != nil {
+				return err
+			}
+		}
+		applied = len(migrations) - version
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return applied, nil
+}
+
+//InTx runs fn in a transaction, it takes the write lock when it begins so transactions that read before they write do not fail on another process's write. Committed if fn succeeds and rolled back otherwise.
+func InTx(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err = fn(tx); err != nil {
+		if err2 := tx.Rollback(); err2 != nil {
+			err = fmt.Errorf("%v: %w", err, err2)
+		}
+		return err
+	}
+	return tx.Commit()
+}
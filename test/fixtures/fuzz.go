@@ -0,0 +1,146 @@
+package fixtures
+
+import (
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+
+	"github.com/scirelli/auction-ebidlocal-search/internal/pkg/ebidlocal/model"
+	ebidhttp "github.com/scirelli/auction-ebidlocal-search/internal/pkg/net/http"
+)
+
+//FuzzTimeout how long a fuzzed search has to finish and close its channel.
+const FuzzTimeout = 5 * time.Second
+
+//Corpus returns the contents of every file matching the glob patterns, used to seed fuzz targets with captured responses.
+func Corpus(tb testing.TB, patterns ...string) (corpus []string) {
+	tb.Helper()
+
+	for _, pattern := range patterns {
+		files, err := filepath.Glob(pattern)
+		if err != nil {
+			tb.Fatal(err)
+		}
+		sort.Strings(files)
+		for _, file := range files {
+			b, err := ioutil.ReadFile(file)
+			if err != nil {
+				tb.Fatal(err)
+			}
+			corpus = append(corpus, string(b))
+		}
+	}
+
+	return corpus
+}
+
+//CheckGoroutineLeak records the number of running goroutines, the returned function fails the test if more are still running after waiting up to timeout for them to wind down.
+func CheckGoroutineLeak(tb testing.TB, timeout time.Duration) func() {
+	tb.Helper()
+	var before = runtime.NumGoroutine()
+
+	return func() {
+		tb.Helper()
+		var deadline = time.Now().Add(timeout)
+
+		for runtime.NumGoroutine() > before {
+			if time.Now().After(deadline) {
+				tb.Errorf("Goroutine leak: %d goroutines running, expected at most %d", runtime.NumGoroutine(), before)
+				return
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+}
+
+//ReceiveAll drains the channel, failing the test if it is not closed within timeout.
+func ReceiveAll[T any](tb testing.TB, in <-chan T, timeout time.Duration) (out []T) {
+	tb.Helper()
+	var timer = time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		select {
+		case v, ok := <-in:
+			if !ok {
+				return out
+			}
+			out = append(out, v)
+		case <-timer.C:
+			tb.Fatalf("Channel was not closed within '%s'", timeout)
+			return out
+		}
+	}
+}
+
+//FuzzSearchAuction fuzzes a search version splitting a response into rows, seeded with the corpus. setClient replaces the version's HTTP client and returns the one it replaced.
+func FuzzSearchAuction(f *testing.F, corpus []string, setClient func(ebidhttp.HTTPClient) ebidhttp.HTTPClient, search func(out chan<- model.SearchResult, auction string, keyword string) error) {
+	for _, seed := range corpus {
+		f.Add(seed)
+	}
+
+	defer setClient(setClient(nil))
+	f.Fuzz(func(t *testing.T, body string) {
+		defer CheckGoroutineLeak(t, FuzzTimeout)()
+		var out = make(chan model.SearchResult)
+		var err error
+
+		setClient(&MockClient{
+			DoFunc: func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					Body:       ioutil.NopCloser(strings.NewReader(body)),
+					StatusCode: 200,
+					Request:    req,
+				}, nil
+			},
+		})
+
+		go func() {
+			defer close(out)
+			err = search(out, "fuzz", "fuzz")
+		}()
+
+		for _, result := range ReceiveAll(t, out, FuzzTimeout) {
+			if result.AuctionID != "fuzz" || result.Keyword != "fuzz" {
+				t.Errorf("Expected auction and keyword 'fuzz' got '%s' '%s'", result.AuctionID, result.Keyword)
+			}
+			if !strings.HasPrefix(result.Content, "<div") {
+				t.Errorf("Expected a row got '%s'", result.Content)
+			}
+		}
+		if err != nil {
+			t.Errorf("Unexpected error '%s'", err)
+		}
+	})
+}
+
+//FuzzFullyQualifyLinks fuzzes a search version qualifying the links of a response, seeded with the corpus. Every link must start with site afterwards.
+func FuzzFullyQualifyLinks(f *testing.F, corpus []string, site string, qualify func(doc *goquery.Document) *goquery.Document) {
+	for _, seed := range corpus {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, body string) {
+		doc, err := goquery.NewDocumentFromReader(strings.NewReader(body))
+		if err != nil {
+			t.Skip(err)
+		}
+		before := doc.Find("a[href]").Length()
+
+		qualify(doc).Find("a[href]").Each(func(i int, s *goquery.Selection) {
+			if href := s.AttrOr("href", ""); !strings.HasPrefix(href, site) {
+				t.Errorf("Link was not qualified '%s'", href)
+			}
+		})
+		if after := doc.Find("a[href]").Length(); before != after {
+			t.Errorf("Expected '%d' links got '%d'", before, after)
+		}
+	})
+}
@@ -0,0 +1,59 @@
+package fixtures
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+//GoldenExt extension of golden files. A golden file sits next to the capture it was generated from.
+const GoldenExt = ".golden.json"
+
+//Captures returns the sorted paths of all files in dir with the extension ext. The test fails if none are found so an empty capture directory is never silently green.
+func Captures(t *testing.T, dir string, ext string) []string {
+	t.Helper()
+
+	captures, err := filepath.Glob(filepath.Join(dir, "*"+ext))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(captures) == 0 {
+		t.Fatalf("No '%s' captures found in '%s'", ext, dir)
+	}
+	sort.Strings(captures)
+
+	return captures
+}
+
+//GoldenFile path of the golden file for a capture.
+func GoldenFile(capture string) string {
+	return strings.TrimSuffix(capture, filepath.Ext(capture)) + GoldenExt
+}
+
+//Golden compares actual against the golden file. With update the golden file is written with actual instead, tests pass it from an -update flag of their own.
+func Golden(t *testing.T, goldenFile string, actual []byte, update bool) {
+	t.Helper()
+
+	if update {
+		if err := ioutil.WriteFile(goldenFile, actual, 0644); err != nil {
+			t.Fatal(err)
+		}
+		t.Logf("Updated golden file '%s'", goldenFile)
+		return
+	}
+
+	expected, err := ioutil.ReadFile(goldenFile)
+	if os.IsNotExist(err) {
+		t.Fatalf("Golden file '%s' does not exist, run the test with -update to create it", goldenFile)
+	} else if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(expected, actual) {
+		t.Errorf("Result does not match golden file '%s', run the test with -update if the change is expected.\nExpected:\n%s\nActual:\n%s", goldenFile, expected, actual)
+	}
+}